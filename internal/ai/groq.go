@@ -96,7 +96,7 @@ func (c *GroqClient) Analyze(systemPrompt, userPrompt string) (string, int, erro
 	return groqResp.Choices[0].Message.Content, groqResp.Usage.TotalTokens, nil
 }
 
-func (c *GroqClient) AnalyzeProject(projectName, description, technologies, readme string) (string, int, error) {
+func (c *GroqClient) AnalyzeProject(projectName, description, technologies, readme, todos string) (string, int, error) {
 	systemPrompt := `You are a senior software engineer analyzing development projects. Provide concise, actionable insights about project status, progress, and next steps. Focus on technical accuracy.`
 
 	userPrompt := fmt.Sprintf(`Analyze this project:
@@ -108,13 +108,16 @@ Technologies: %s
 README excerpt:
 %s
 
+Open TODOs in the code:
+%s
+
 Provide:
 1. Current state assessment (2-3 sentences)
 2. Estimated completion percentage (0-100)
 3. Key next steps (3-5 items)
 4. Technical concerns or blockers
 
-Format your response as clear, structured text.`, projectName, description, technologies, readme)
+Format your response as clear, structured text.`, projectName, description, technologies, readme, todos)
 
 	return c.Analyze(systemPrompt, userPrompt)
 }
@@ -8,7 +8,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
-	"github.com/snowarch/project-memory/internal/utils"
 )
 
 var agentCmd = &cobra.Command{
@@ -79,13 +78,7 @@ func init() {
 }
 
 func generateAgentContext(project *models.Project, format string) error {
-	generator := utils.NewContextGenerator(project.Path)
-	context, err := generator.GenerateAgentContext(
-		project.Name,
-		string(project.Status),
-		project.Progress,
-		project.Notes,
-	)
+	context, err := newAgentContext(project)
 	if err != nil {
 		return fmt.Errorf("failed to generate context: %w", err)
 	}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/filter"
 	"github.com/snowarch/project-memory/internal/repository"
 	"github.com/snowarch/project-memory/internal/scanner"
 	"github.com/snowarch/project-memory/internal/utils"
@@ -21,6 +23,7 @@ var agentOptimizedCmd = &cobra.Command{
 		format, _ := cmd.Flags().GetString("format")
 		output, _ := cmd.Flags().GetString("output")
 		batch, _ := cmd.Flags().GetString("batch")
+		filterExpr, _ := cmd.Flags().GetString("filter")
 
 		// Check environment variables for agent integration
 		if action == "" {
@@ -35,6 +38,9 @@ var agentOptimizedCmd = &cobra.Command{
 		if output == "" {
 			output = os.Getenv("PMEM_OUTPUT")
 		}
+		if filterExpr == "" {
+			filterExpr = os.Getenv("PMEM_FILTER")
+		}
 
 		// Default values
 		if format == "" {
@@ -43,7 +49,7 @@ var agentOptimizedCmd = &cobra.Command{
 
 		switch action {
 		case "list":
-			return listProjectsOptimized(format, output)
+			return listProjectsOptimized(filterExpr, format, output)
 		case "discover":
 			return discoverProjectsOptimized(output)
 		case "context":
@@ -81,10 +87,15 @@ var agentOptimizedCmd = &cobra.Command{
 	},
 }
 
-func listProjectsOptimized(format, output string) error {
+func listProjectsOptimized(filterExpr, format, output string) error {
 	projectRepo := repository.NewProjectRepository(db.Conn())
 	
-	projects, err := projectRepo.List("", 100, 0)
+	f, err := filter.Parse(filterExpr, time.Now())
+	if err != nil {
+		return err
+	}
+
+	projects, err := projectRepo.Find(f, 0, 0)
 	if err != nil {
 		return fmt.Errorf("failed to list projects: %w", err)
 	}
@@ -151,14 +162,7 @@ func getProjectContextOptimized(projectName, format, output string) error {
 	}
 
 	project := projects[0]
-	generator := utils.NewContextGenerator(project.Path)
-	
-	context, err := generator.GenerateAgentContext(
-		project.Name,
-		string(project.Status),
-		project.Progress,
-		project.Notes,
-	)
+	context, err := newAgentContext(&project)
 	if err != nil {
 		return fmt.Errorf("failed to generate context: %w", err)
 	}
@@ -222,14 +226,7 @@ func getBatchContextOptimized(batchFile, format, output string) error {
 		}
 
 		project := projects[0]
-		generator := utils.NewContextGenerator(project.Path)
-		
-		context, err := generator.GenerateAgentContext(
-			project.Name,
-			string(project.Status),
-			project.Progress,
-			project.Notes,
-		)
+		context, err := newAgentContext(&project)
 		if err != nil {
 			continue
 		}
@@ -357,7 +354,7 @@ func listTechnologiesOptimized(format, output string) error {
 	projectRepo := repository.NewProjectRepository(db.Conn())
 	techRepo := repository.NewTechnologyRepository(db.Conn())
 	
-	projects, err := projectRepo.List("", 100, 0)
+	projects, err := projectRepo.Find(nil, 0, 0)
 	if err != nil {
 		return fmt.Errorf("failed to list projects: %w", err)
 	}
@@ -397,13 +394,13 @@ func listTechnologiesOptimized(format, output string) error {
 func healthCheckOptimized() error {
 	projectRepo := repository.NewProjectRepository(db.Conn())
 	
-	projects, err := projectRepo.List("", 1, 0)
+	count, err := projectRepo.Count("")
 	if err != nil {
 		fmt.Printf("ERROR: %v\n", err)
 		return err
 	}
 
-	fmt.Printf("OK|projects:%d|timestamp:%d\n", len(projects), utils.GetCurrentTimestamp())
+	fmt.Printf("OK|projects:%d|timestamp:%d\n", count, utils.GetCurrentTimestamp())
 	return nil
 }
 
@@ -411,7 +408,7 @@ func showAgentHelp() error {
 	helpText := `Project Memory Agent Interface
 
 Actions:
-  list                 - List all projects
+  list                 - List projects, all or those matching --filter
   discover             - Discover projects in current directory
   context              - Get project context
   batch-context        - Get context for multiple projects
@@ -437,9 +434,11 @@ Environment Variables:
   PMEM_PROJECT         - Project name
   PMEM_FORMAT          - Output format
   PMEM_OUTPUT          - Output file
+  PMEM_FILTER          - Filter for list
 
 Examples:
   pmem agent-optimized --action list --format json
+  pmem agent-optimized --action list --filter "status:active tech:go" --format simple
   pmem agent-optimized --action context --project myproject --format summary
   PMEM_ACTION=context PMEM_PROJECT=myproject pmem agent-optimized
   pmem agent-optimized --action batch-context --batch projects.txt --format json
@@ -450,6 +449,7 @@ Flags:
   --format string       - Output format (default: json)
   --output string       - Output file
   --batch string        - Batch file for multiple projects
+  --filter string       - Filter for list, such as "status:active progress<50"
 `
 	fmt.Print(helpText)
 	return nil
@@ -469,5 +469,6 @@ func init() {
 	agentOptimizedCmd.Flags().String("format", "json", "Output format")
 	agentOptimizedCmd.Flags().String("output", "", "Output file")
 	agentOptimizedCmd.Flags().String("batch", "", "Batch file for multiple projects")
+	agentOptimizedCmd.Flags().String("filter", "", "Filter for the list action, such as \"status:active progress<50\"")
 	rootCmd.AddCommand(agentOptimizedCmd)
 }
@@ -15,6 +15,10 @@ import (
 
 var apiKey string
 
+// maxPromptTodos bounds the open todos listed in analysis prompts, the
+// highest priority first.
+const maxPromptTodos = 30
+
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze <project-name>",
 	Short: "Analyze project with AI",
@@ -60,6 +64,12 @@ var analyzeCmd = &cobra.Command{
 		}
 
 		readme := readREADME(project.Path)
+
+		todoRepo := repository.NewTodoRepository(db.Conn())
+		todos, err := todoRepo.List(project.ID, repository.TodoFilter{Source: models.TodoSourceCode})
+		if err != nil {
+			return fmt.Errorf("failed to get todos: %w", err)
+		}
 		
 		groqClient := ai.NewGroqClient(apiKey)
 		
@@ -68,6 +78,7 @@ var analyzeCmd = &cobra.Command{
 			project.Description,
 			strings.Join(techList, ", "),
 			readme,
+			formatTodos(todos, maxPromptTodos),
 		)
 		if err != nil {
 			return fmt.Errorf("AI analysis failed: %w", err)
@@ -85,6 +96,18 @@ var analyzeCmd = &cobra.Command{
 
 		if err := analysisRepo.Create(analysis); err != nil {
 			fmt.Printf("Warning: Failed to save analysis: %v\n", err)
+		} else if err := repository.NewSearchRepository(db.Conn()).Reindex(project.ID); err != nil {
+			fmt.Printf("Warning: Failed to update search index: %v\n", err)
+		}
+
+		activityRepo := repository.NewActivityRepository(db.Conn())
+		err = activityRepo.Record(project.ID, models.ActionAnalyzed, map[string]interface{}{
+			"analysis_type": analysis.AnalysisType,
+			"model":         analysis.Model,
+			"tokens_used":   analysis.TokensUsed,
+		})
+		if err != nil {
+			fmt.Printf("Warning: Failed to log activity: %v\n", err)
 		}
 
 		fmt.Printf("\nAnalysis Result:\n%s\n", result)
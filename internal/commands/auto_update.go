@@ -28,9 +28,10 @@ var autoUpdateCmd = &cobra.Command{
 		logger.Info("Scanning directory: %s", directory)
 
 		projectRepo := repository.NewProjectRepository(db.Conn())
+		activityRepo := repository.NewActivityRepository(db.Conn())
 
 		// Get existing projects
-		projects, err := projectRepo.List("", 1000, 0)
+		projects, err := projectRepo.Find(nil, 0, 0)
 		if err != nil {
 			return fmt.Errorf("failed to get projects: %w", err)
 		}
@@ -69,6 +70,7 @@ var autoUpdateCmd = &cobra.Command{
 					fmt.Println()
 				} else {
 					// Update project with new insights
+					before := project
 					oldProgress := project.Progress
 					project.Progress = suggestedProgress
 					project.UpdatedAt = time.Now()
@@ -89,6 +91,9 @@ var autoUpdateCmd = &cobra.Command{
 						logger.Warn("Failed to update project %s: %v", project.Name, err)
 						continue
 					}
+					if err := activityRepo.RecordChanges(&before, &project, "auto-update"); err != nil {
+						logger.Warn("Failed to log activity for %s: %v", project.Name, err)
+					}
 
 					updatedCount++
 					insightsCount += len(insights)
@@ -7,7 +7,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/snowarch/project-memory/internal/repository"
-	"github.com/snowarch/project-memory/internal/utils"
 )
 
 var contextCmd = &cobra.Command{
@@ -34,13 +33,7 @@ var contextCmd = &cobra.Command{
 		project := projects[0]
 
 		// Generate context
-		generator := utils.NewContextGenerator(project.Path)
-		context, err := generator.GenerateAgentContext(
-			project.Name,
-			string(project.Status),
-			project.Progress,
-			project.Notes,
-		)
+		context, err := newAgentContext(&project)
 		if err != nil {
 			return fmt.Errorf("failed to generate context: %w", err)
 		}
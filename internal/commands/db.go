@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/database"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and migrate the database schema",
+	Long: `Inspect and migrate the database schema.
+
+Every command applies pending migrations when it opens the database; these
+commands open it without migrating, to check or upgrade it explicitly.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migrations were applied",
+	Long: `Show which migrations were applied.
+
+The database is opened without checking its schema version, so the version
+of a database migrated by a newer pmem is reported too.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := database.Inspect(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer schema.Close()
+
+		status, err := schema.MigrationStatus()
+		if err != nil {
+			return fmt.Errorf("failed to read migrations: %w", err)
+		}
+		version, err := schema.SchemaVersion()
+		if err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+
+		fmt.Printf("Database: %s\n", dbPath)
+		fmt.Printf("Schema version: %d of %d\n", version, schema.LatestVersion())
+		if version > schema.LatestVersion() {
+			fmt.Println("The database was migrated by a newer pmem; upgrade pmem to use it.")
+		}
+		fmt.Println()
+		fmt.Printf("%-8s %-30s %s\n", "Version", "Name", "Applied")
+		for _, m := range status {
+			applied := "pending"
+			if m.AppliedAt != nil {
+				applied = m.AppliedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%-8d %-30s %s\n", m.Version, m.Name, applied)
+		}
+		return nil
+	},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := database.Open(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer schema.Close()
+
+		applied, err := schema.Migrate()
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Printf("Database is up to date (schema version %d)\n", schema.LatestVersion())
+		}
+		return nil
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbStatusCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+	rootCmd.AddCommand(dbCmd)
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -21,6 +22,9 @@ var exportCmd = &cobra.Command{
 		format := args[0]
 		outputFile := args[1]
 		status, _ := cmd.Flags().GetString("status")
+		filterExpr, _ := cmd.Flags().GetString("filter")
+		sort, _ := cmd.Flags().GetString("sort")
+		allDeps, _ := cmd.Flags().GetBool("all-deps")
 
 		if format != "json" && format != "csv" {
 			return fmt.Errorf("format must be 'json' or 'csv'")
@@ -28,139 +32,216 @@ var exportCmd = &cobra.Command{
 
 		projectRepo := repository.NewProjectRepository(db.Conn())
 		techRepo := repository.NewTechnologyRepository(db.Conn())
+		statRepo := repository.NewLanguageStatRepository(db.Conn())
 
-		var projects []models.Project
-		var err error
-
-		if status != "" {
-			projects, err = projectRepo.List(status, 1000, 0)
-		} else {
-			projects, err = projectRepo.List("", 1000, 0) // Large limit
-		}
-
+		f, err := parseProjectFilter(filterExpr, status)
 		if err != nil {
-			return fmt.Errorf("failed to get projects: %w", err)
+			return err
 		}
 
-		if len(projects) == 0 {
-			fmt.Printf("No projects found to export\n")
-			return nil
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
 		}
 
+		var writer exportWriter
 		switch format {
 		case "json":
-			err = exportToJSON(projects, techRepo, outputFile)
+			writer = newJSONExportWriter(file, techRepo, statRepo, allDeps)
 		case "csv":
-			err = exportToCSV(projects, techRepo, outputFile)
+			writer, err = newCSVExportWriter(file, techRepo, statRepo, allDeps)
 		}
 
+		// Projects are read and written a page at a time, so exports of
+		// any size do not hold every project in memory.
+		count := 0
+		if err == nil {
+			err = projectRepo.Each(repository.ProjectQuery{Filter: f, Sort: sort}, func(project *models.Project) error {
+				count++
+				return writer.Write(project)
+			})
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil || count == 0 {
+			os.Remove(outputFile)
+		}
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to export projects: %w", err)
+		}
+
+		if count == 0 {
+			fmt.Printf("No projects found to export\n")
+			return nil
 		}
 
-		fmt.Printf("Exported %d projects to %s\n", len(projects), outputFile)
+		fmt.Printf("Exported %d projects to %s\n", count, outputFile)
 		return nil
 	},
 }
 
-func exportToJSON(projects []models.Project, techRepo *repository.TechnologyRepository, outputFile string) error {
-	type ExportProject struct {
-		Name         string            `json:"name"`
-		Path         string            `json:"path"`
-		Description  string            `json:"description"`
-		Status       string            `json:"status"`
-		Progress     int               `json:"progress"`
-		CreatedAt    int64             `json:"created_at"`
-		UpdatedAt    int64             `json:"updated_at"`
-		IsGitRepo    bool              `json:"is_git_repo"`
-		GitRemote    string            `json:"git_remote,omitempty"`
-		GitBranch    string            `json:"git_branch,omitempty"`
-		Technologies []models.Technology `json:"technologies"`
-	}
+// exportWriter writes exported projects one at a time. Close completes the
+// output without closing the file.
+type exportWriter interface {
+	Write(project *models.Project) error
+	Close() error
+}
 
-	var exportProjects []ExportProject
-	for _, project := range projects {
-		techs, _ := techRepo.GetByProject(project.ID)
-		
-		exportProject := ExportProject{
-			Name:         project.Name,
-			Path:         project.Path,
-			Description:  project.Description,
-			Status:       string(project.Status),
-			Progress:     project.Progress,
-			CreatedAt:    project.CreatedAt.Unix(),
-			UpdatedAt:    project.UpdatedAt.Unix(),
-			IsGitRepo:    project.IsGitRepo,
-			GitRemote:    project.GitRemote,
-			GitBranch:    project.GitBranch,
-			Technologies: techs,
-		}
-		exportProjects = append(exportProjects, exportProject)
+// exportTechnologies returns the technologies exported for a project: its
+// direct dependencies, and with allDeps the transitive ones from lock files.
+func exportTechnologies(techRepo *repository.TechnologyRepository, projectID string, allDeps bool) ([]models.Technology, error) {
+	if allDeps {
+		return techRepo.GetAllByProject(projectID)
 	}
+	return techRepo.GetByProject(projectID)
+}
 
-	data, err := json.MarshalIndent(exportProjects, "", "  ")
+type exportProject struct {
+	Name         string                `json:"name"`
+	Path         string                `json:"path"`
+	Description  string                `json:"description"`
+	Status       string                `json:"status"`
+	Progress     int                   `json:"progress"`
+	CreatedAt    int64                 `json:"created_at"`
+	UpdatedAt    int64                 `json:"updated_at"`
+	IsGitRepo    bool                  `json:"is_git_repo"`
+	GitRemote    string                `json:"git_remote,omitempty"`
+	GitBranch    string                `json:"git_branch,omitempty"`
+	Technologies []models.Technology   `json:"technologies"`
+	Languages    []models.LanguageStat `json:"languages,omitempty"`
+}
+
+// jsonExportWriter writes an indented JSON array, an element per project.
+type jsonExportWriter struct {
+	w        *bufio.Writer
+	techRepo *repository.TechnologyRepository
+	statRepo *repository.LanguageStatRepository
+	allDeps  bool
+	count    int
+}
+
+func newJSONExportWriter(file *os.File, techRepo *repository.TechnologyRepository, statRepo *repository.LanguageStatRepository, allDeps bool) *jsonExportWriter {
+	return &jsonExportWriter{w: bufio.NewWriter(file), techRepo: techRepo, statRepo: statRepo, allDeps: allDeps}
+}
+
+func (e *jsonExportWriter) Write(project *models.Project) error {
+	techs, _ := exportTechnologies(e.techRepo, project.ID, e.allDeps)
+	languages, _ := e.statRepo.GetByProject(project.ID)
+
+	data, err := json.MarshalIndent(exportProject{
+		Name:         project.Name,
+		Path:         project.Path,
+		Description:  project.Description,
+		Status:       string(project.Status),
+		Progress:     project.Progress,
+		CreatedAt:    project.CreatedAt.Unix(),
+		UpdatedAt:    project.UpdatedAt.Unix(),
+		IsGitRepo:    project.IsGitRepo,
+		GitRemote:    project.GitRemote,
+		GitBranch:    project.GitBranch,
+		Technologies: techs,
+		Languages:    languages,
+	}, "  ", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	return os.WriteFile(outputFile, data, 0644)
+	separator := "[\n  "
+	if e.count > 0 {
+		separator = ",\n  "
+	}
+	e.count++
+	if _, err := e.w.WriteString(separator); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
 }
 
-func exportToCSV(projects []models.Project, techRepo *repository.TechnologyRepository, outputFile string) error {
-	file, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+func (e *jsonExportWriter) Close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	if _, err := e.w.WriteString(end); err != nil {
+		return err
 	}
-	defer file.Close()
+	return e.w.Flush()
+}
 
+// csvExportWriter writes a header row, then a row per project.
+type csvExportWriter struct {
+	w        *csv.Writer
+	techRepo *repository.TechnologyRepository
+	statRepo *repository.LanguageStatRepository
+	allDeps  bool
+}
+
+func newCSVExportWriter(file *os.File, techRepo *repository.TechnologyRepository, statRepo *repository.LanguageStatRepository, allDeps bool) (*csvExportWriter, error) {
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
-	// Write header
 	header := []string{
 		"Name", "Path", "Description", "Status", "Progress",
 		"Created At", "Updated At", "Is Git Repo", "Git Remote", "Git Branch",
-		"Technologies",
+		"Technologies", "Languages",
 	}
 	if err := writer.Write(header); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+		return nil, fmt.Errorf("failed to write header: %w", err)
 	}
+	return &csvExportWriter{w: writer, techRepo: techRepo, statRepo: statRepo, allDeps: allDeps}, nil
+}
 
-	// Write rows
-	for _, project := range projects {
-		techs, _ := techRepo.GetByProject(project.ID)
-		var techNames []string
-		for _, tech := range techs {
-			if tech.Version != "" {
-				techNames = append(techNames, fmt.Sprintf("%s %s", tech.Name, tech.Version))
-			} else {
-				techNames = append(techNames, tech.Name)
-			}
+func (e *csvExportWriter) Write(project *models.Project) error {
+	techs, _ := exportTechnologies(e.techRepo, project.ID, e.allDeps)
+	var techNames []string
+	for _, tech := range techs {
+		if tech.Version != "" {
+			techNames = append(techNames, fmt.Sprintf("%s %s", tech.Name, tech.Version))
+		} else {
+			techNames = append(techNames, tech.Name)
 		}
+	}
 
-		row := []string{
-			project.Name,
-			project.Path,
-			project.Description,
-			string(project.Status),
-			strconv.Itoa(project.Progress),
-			project.CreatedAt.Format("2006-01-02 15:04:05"),
-			project.UpdatedAt.Format("2006-01-02 15:04:05"),
-			strconv.FormatBool(project.IsGitRepo),
-			project.GitRemote,
-			project.GitBranch,
-			fmt.Sprintf("[%s]", strings.Join(techNames, ", ")),
-		}
+	languages, _ := e.statRepo.GetByProject(project.ID)
+	var languageLines []string
+	for _, l := range languages {
+		languageLines = append(languageLines, fmt.Sprintf("%s %d", l.Language, l.CodeLines))
+	}
 
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write row: %w", err)
-		}
+	row := []string{
+		project.Name,
+		project.Path,
+		project.Description,
+		string(project.Status),
+		strconv.Itoa(project.Progress),
+		project.CreatedAt.Format("2006-01-02 15:04:05"),
+		project.UpdatedAt.Format("2006-01-02 15:04:05"),
+		strconv.FormatBool(project.IsGitRepo),
+		project.GitRemote,
+		project.GitBranch,
+		fmt.Sprintf("[%s]", strings.Join(techNames, ", ")),
+		fmt.Sprintf("[%s]", strings.Join(languageLines, ", ")),
 	}
 
+	if err := e.w.Write(row); err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
 	return nil
 }
 
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
 func init() {
 	exportCmd.Flags().StringP("status", "s", "", "Filter by status (active, paused, completed, archived)")
+	exportCmd.Flags().String("filter", "", "Only export projects matching a filter, as in 'pmem list'")
+	exportCmd.Flags().String("sort", "", "Sort key, as in 'pmem list'")
+	exportCmd.Flags().Bool("all-deps", false, "Include transitive dependencies from lock files")
 	rootCmd.AddCommand(exportCmd)
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/ignore"
 	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
 	"github.com/snowarch/project-memory/internal/scanner"
@@ -64,12 +65,14 @@ var handoffCmd = &cobra.Command{
 
 func generateHandoffDoc(project *models.Project, techRepo *repository.TechnologyRepository, includeCode bool) (string, error) {
 	var doc strings.Builder
+	tasks := openTasks(project.ID)
 
 	// Header
 	doc.WriteString(fmt.Sprintf("# Developer Handoff: %s\n\n", project.Name))
 	doc.WriteString(fmt.Sprintf("**Generated:** %s  \n", time.Now().Format("2006-01-02 15:04:05")))
 	doc.WriteString(fmt.Sprintf("**Status:** %s  \n", project.Status))
 	doc.WriteString(fmt.Sprintf("**Progress:** %d%%  \n", project.Progress))
+	doc.WriteString(fmt.Sprintf("**Open Tasks:** %d  \n", len(tasks)))
 	doc.WriteString(fmt.Sprintf("**Location:** `%s`  \n\n", project.Path))
 
 	// Project Overview
@@ -155,21 +158,11 @@ func generateHandoffDoc(project *models.Project, techRepo *repository.Technology
 	// Project Structure
 	doc.WriteString("## Project Structure\n\n")
 	doc.WriteString("```\n")
-	err = filepath.Walk(project.Path, func(path string, info os.FileInfo, err error) error {
+	err = ignore.Walk(project.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
 
-		// Skip hidden files and common directories
-		if strings.HasPrefix(info.Name(), ".") || 
-		   strings.Contains(path, "node_modules") ||
-		   strings.Contains(path, "target") ||
-		   strings.Contains(path, "build") ||
-		   strings.Contains(path, "dist") ||
-		   strings.Contains(path, ".git") {
-			return nil
-		}
-
 		relPath, _ := filepath.Rel(project.Path, path)
 		if strings.Count(relPath, string(filepath.Separator)) <= 2 { // Only show top 2 levels
 			doc.WriteString(fmt.Sprintf("%s/\n", relPath))
@@ -196,6 +189,15 @@ func generateHandoffDoc(project *models.Project, techRepo *repository.Technology
 		doc.WriteString(fmt.Sprintf("%s\n\n", project.Notes))
 	}
 
+	// Open Tasks
+	if len(tasks) > 0 {
+		doc.WriteString("## Open Tasks\n\n")
+		for _, task := range tasks {
+			doc.WriteString(fmt.Sprintf("- [ ] #%d %s (%s)\n", task.ID, task.Content, task.Priority))
+		}
+		doc.WriteString("\n")
+	}
+
 	// Next Steps
 	doc.WriteString("## Recommended Next Steps\n\n")
 	doc.WriteString("1. **Review Current State** - Check recent changes and uncommitted work\n")
@@ -12,12 +12,14 @@ import (
 
 func generateHandoffDocContent(project *models.Project) (string, error) {
 	var doc strings.Builder
+	tasks := openTasks(project.ID)
 
 	// Header
 	doc.WriteString(fmt.Sprintf("# Developer Handoff: %s\n\n", project.Name))
 	doc.WriteString(fmt.Sprintf("**Generated:** %s  \n", time.Now().Format("2006-01-02 15:04:05")))
 	doc.WriteString(fmt.Sprintf("**Status:** %s  \n", project.Status))
 	doc.WriteString(fmt.Sprintf("**Progress:** %d%%  \n", project.Progress))
+	doc.WriteString(fmt.Sprintf("**Open Tasks:** %d  \n", len(tasks)))
 	doc.WriteString(fmt.Sprintf("**Location:** `%s`  \n\n", project.Path))
 
 	// Project Overview
@@ -115,6 +117,15 @@ func generateHandoffDocContent(project *models.Project) (string, error) {
 		doc.WriteString(fmt.Sprintf("%s\n\n", project.Notes))
 	}
 
+	// Open Tasks
+	if len(tasks) > 0 {
+		doc.WriteString("## Open Tasks\n\n")
+		for _, task := range tasks {
+			doc.WriteString(fmt.Sprintf("- [ ] #%d %s (%s)\n", task.ID, task.Content, task.Priority))
+		}
+		doc.WriteString("\n")
+	}
+
 	// Next Steps
 	doc.WriteString("## Recommended Next Steps\n\n")
 	doc.WriteString("1. **Review Current State** - Check recent changes and uncommitted work\n")
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"sort"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+	"github.com/snowarch/project-memory/internal/scanner"
+	"github.com/snowarch/project-memory/internal/utils"
+)
+
+// Tree drawing for workspace members in project listings. Every member but
+// the last of its parent gets a branch, and the levels below a member that
+// has siblings after it are drawn with a continuation line.
+const (
+	treeBranch     = "├─ "
+	treeLastBranch = "└─ "
+	treeLine       = "│  "
+	treeSpace      = "   "
+)
+
+// orderByHierarchy reorders projects so that workspace members directly
+// follow their parent, sorted by name, keeping the original order of the
+// top level projects. It also returns the tree prefix shown before every
+// project name. Members whose parent is not among projects are listed at
+// the top level.
+func orderByHierarchy(projects []models.Project) ([]models.Project, map[string]string) {
+	present := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		present[p.ID] = true
+	}
+
+	children := make(map[string][]models.Project)
+	var roots []models.Project
+	for _, p := range projects {
+		if p.ParentID != "" && present[p.ParentID] && p.ParentID != p.ID {
+			children[p.ParentID] = append(children[p.ParentID], p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+
+	for _, members := range children {
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].Name < members[j].Name
+		})
+	}
+
+	ordered := make([]models.Project, 0, len(projects))
+	prefixes := make(map[string]string, len(projects))
+	var visit func(p models.Project, prefix, indent string)
+	visit = func(p models.Project, prefix, indent string) {
+		if _, seen := prefixes[p.ID]; seen {
+			return
+		}
+		ordered = append(ordered, p)
+		prefixes[p.ID] = prefix
+		members := children[p.ID]
+		for i, child := range members {
+			if i == len(members)-1 {
+				visit(child, indent+treeLastBranch, indent+treeSpace)
+			} else {
+				visit(child, indent+treeBranch, indent+treeLine)
+			}
+		}
+	}
+	for _, p := range roots {
+		visit(p, "", "")
+	}
+
+	return ordered, prefixes
+}
+
+// workspaceMembers returns the member directories of a workspace root,
+// which are left out of its own language stats and TODOs.
+func workspaceMembers(path string) []string {
+	if ws := scanner.DetectWorkspace(path); ws != nil {
+		return ws.Members
+	}
+	return nil
+}
+
+// newAgentContext generates the agent context of a project, including its
+// place in a workspace hierarchy and the language breakdown of its last
+// scan.
+func newAgentContext(project *models.Project) (*utils.AgentContext, error) {
+	generator := utils.NewContextGenerator(project.Path)
+	context, err := generator.GenerateAgentContext(
+		project.Name,
+		string(project.Status),
+		project.Progress,
+		project.Notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	statRepo := repository.NewLanguageStatRepository(db.Conn())
+	if languages, err := statRepo.GetByProject(project.ID); err == nil {
+		context.Languages = languages
+	}
+
+	projectRepo := repository.NewProjectRepository(db.Conn())
+	if project.ParentID != "" {
+		if parent, err := projectRepo.GetByID(project.ParentID); err == nil {
+			ref := projectRef(parent)
+			context.Parent = &ref
+		}
+	}
+
+	children, err := projectRepo.ListChildren(project.ID)
+	if err == nil {
+		for i := range children {
+			context.Members = append(context.Members, projectRef(&children[i]))
+		}
+	}
+
+	return context, nil
+}
+
+func projectRef(p *models.Project) utils.ProjectRef {
+	return utils.ProjectRef{
+		ID:     p.ID,
+		Name:   p.Name,
+		Path:   p.Path,
+		Status: string(p.Status),
+	}
+}
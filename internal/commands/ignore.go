@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/ignore"
+	"github.com/snowarch/project-memory/internal/repository"
+)
+
+var ignoreCmd = &cobra.Command{
+	Use:   "ignore",
+	Short: "Manage global ignore patterns applied to every project",
+	Long: `Manage global ignore patterns applied to every project walk.
+
+Patterns follow .gitignore syntax. They are applied after the built-in
+defaults and before each project's .gitignore and .pmemignore files.`,
+}
+
+var ignoreListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List ignore patterns",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configRepo := repository.NewConfigRepository(db.Conn())
+		patterns, err := configRepo.GetIgnorePatterns()
+		if err != nil {
+			return fmt.Errorf("failed to load ignore patterns: %w", err)
+		}
+
+		fmt.Println("Built-in:")
+		for _, p := range ignore.DefaultPatterns {
+			fmt.Printf("  %s\n", p)
+		}
+		fmt.Println("Global:")
+		if len(patterns) == 0 {
+			fmt.Println("  (none)")
+		}
+		for _, p := range patterns {
+			fmt.Printf("  %s\n", p)
+		}
+		return nil
+	},
+}
+
+var ignoreAddCmd = &cobra.Command{
+	Use:   "add <pattern>...",
+	Short: "Add global ignore patterns",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configRepo := repository.NewConfigRepository(db.Conn())
+		patterns, err := configRepo.GetIgnorePatterns()
+		if err != nil {
+			return fmt.Errorf("failed to load ignore patterns: %w", err)
+		}
+
+		for _, arg := range args {
+			if !containsString(patterns, arg) {
+				patterns = append(patterns, arg)
+			}
+		}
+
+		if err := configRepo.SetIgnorePatterns(patterns); err != nil {
+			return fmt.Errorf("failed to save ignore patterns: %w", err)
+		}
+		fmt.Printf("Global ignore patterns: %d\n", len(patterns))
+		return nil
+	},
+}
+
+var ignoreRemoveCmd = &cobra.Command{
+	Use:   "remove <pattern>...",
+	Short: "Remove global ignore patterns",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configRepo := repository.NewConfigRepository(db.Conn())
+		patterns, err := configRepo.GetIgnorePatterns()
+		if err != nil {
+			return fmt.Errorf("failed to load ignore patterns: %w", err)
+		}
+
+		var kept []string
+		for _, p := range patterns {
+			if !containsString(args, p) {
+				kept = append(kept, p)
+			}
+		}
+
+		if err := configRepo.SetIgnorePatterns(kept); err != nil {
+			return fmt.Errorf("failed to save ignore patterns: %w", err)
+		}
+		fmt.Printf("Removed %d patterns\n", len(patterns)-len(kept))
+		return nil
+	},
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	ignoreCmd.AddCommand(ignoreListCmd)
+	ignoreCmd.AddCommand(ignoreAddCmd)
+	ignoreCmd.AddCommand(ignoreRemoveCmd)
+	rootCmd.AddCommand(ignoreCmd)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/snowarch/project-memory/internal/ai"
 	"github.com/snowarch/project-memory/internal/logger"
+	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
 	"github.com/snowarch/project-memory/internal/scanner"
 )
@@ -88,6 +89,12 @@ var insightsCmd = &cobra.Command{
 		activityText := fmt.Sprintf("Activity: %s, Confidence: %.2f, Insights: %v", 
 			activity, confidence, insights)
 
+		todos, err := repository.NewTodoRepository(db.Conn()).List(project.ID, repository.TodoFilter{Source: models.TodoSourceCode})
+		if err != nil {
+			logger.Warn("Failed to get todos: %v", err)
+		}
+		activityText += "\nOpen TODOs:\n" + formatTodos(todos, maxPromptTodos)
+
 		if detailed {
 			// Enhanced analysis
 			result, tokens, err := groqClient.AnalyzeProjectEnhanced(
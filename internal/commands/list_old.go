@@ -8,45 +8,100 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/filter"
+	"github.com/snowarch/project-memory/internal/logger"
 	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
 	"github.com/snowarch/project-memory/internal/utils"
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [filter]",
 	Short: "List all projects with interactive menu",
+	Long: `List all projects with interactive menu, or those matching a filter such as
+'status:active tech:go progress<50 updated>30d has:git remote:github.com/acme'.
+
+Terms must all match; values separated by commas match any of them and a
+leading '-' negates a term. Fields: status, tech, name, path, remote, branch,
+progress, updated, created, scanned (durations such as 30d or dates such as
+2006-01-02; updated>30d means updated in the last 30 days) and has (git,
+remote, description, notes, readme, todos, tasks, analysis, parent, missing).
+Words without a field match the name, description or path. Put '--' before
+a filter starting with '-', as in 'pmem list -- -has:git'.
+
+--sort orders projects by name, progress, updated, created, last_scanned or
+last_commit, ascending or, prefixed with '-', descending; the default is
+-updated. In non-blocking mode a cursor is printed when more projects remain;
+pass it to --cursor with the same filter and sort to list the next page.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		nonBlocking, _ := cmd.Flags().GetBool("non-blocking")
 		autoIDE, _ := cmd.Flags().GetBool("auto-ide")
 		ideName, _ := cmd.Flags().GetString("ide")
 		exportContext, _ := cmd.Flags().GetString("export-context")
+		sort, _ := cmd.Flags().GetString("sort")
+		cursor, _ := cmd.Flags().GetString("cursor")
+
+		f, err := parseProjectFilter(strings.Join(args, " "), statusFilter)
+		if err != nil {
+			return err
+		}
 
 		projectRepo := repository.NewProjectRepository(db.Conn())
 		
-		projects, err := projectRepo.List(statusFilter, limit, 0)
+		page, err := projectRepo.Page(repository.ProjectQuery{
+			Filter: f,
+			Sort:   sort,
+			Limit:  limit,
+			Cursor: cursor,
+			Offset: offset,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to list projects: %w", err)
 		}
 
-		if len(projects) == 0 {
+		if len(page.Projects) == 0 {
 			fmt.Println("No projects found. Run 'pmem scan' first.")
 			return nil
 		}
 
+		projects, prefixes := orderByHierarchy(page.Projects)
+
 		if nonBlocking {
-			return showNonBlockingMenu(projects, autoIDE, ideName, exportContext)
+			if err := showNonBlockingMenu(projects, prefixes, autoIDE, ideName, exportContext); err != nil {
+				return err
+			}
+			if page.NextCursor != "" {
+				fmt.Printf("\nMore projects: repeat with --cursor %s\n", page.NextCursor)
+			}
+			return nil
 		}
 
-		return showInteractiveMenu(projects, autoIDE, ideName, exportContext)
+		return showInteractiveMenu(projects, prefixes, autoIDE, ideName, exportContext)
 	},
 }
 
-func showInteractiveMenu(projects []models.Project, autoIDE bool, ideName string, exportContext string) error {
+// parseProjectFilter reads a filter expression, adding a status given on
+// its own as by --status.
+func parseProjectFilter(expr, status string) (*filter.Filter, error) {
+	now := time.Now()
+	f, err := filter.Parse(expr, now)
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		if err := f.Add(fmt.Sprintf("status:%q", status), now); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func showInteractiveMenu(projects []models.Project, prefixes map[string]string, autoIDE bool, ideName string, exportContext string) error {
+	taskCounts := openTaskCounts()
 	var choices []string
 	for _, p := range projects {
 		statusIndicator := getStatusIndicator(string(p.Status))
-		line := fmt.Sprintf("[%s] %s | %s | Progress: %d%%", statusIndicator, p.Name, p.Status, p.Progress)
+		line := fmt.Sprintf("[%s] %s%s | %s | Progress: %d%%%s", statusIndicator, prefixes[p.ID], p.Name, p.Status, p.Progress, taskSuffix(taskCounts[p.ID]))
 		choices = append(choices, line)
 	}
 
@@ -88,6 +143,7 @@ func showInteractiveMenu(projects []models.Project, autoIDE bool, ideName string
 	if strings.HasPrefix(projectPart, "[") {
 		if idx := strings.Index(projectPart, "]"); idx != -1 {
 			projectName := strings.TrimSpace(projectPart[idx+1:])
+			projectName = strings.TrimPrefix(projectName, treeBranch)
 			for _, p := range projects {
 				if p.Name == projectName {
 					if autoIDE {
@@ -102,15 +158,16 @@ func showInteractiveMenu(projects []models.Project, autoIDE bool, ideName string
 	return nil
 }
 
-func showNonBlockingMenu(projects []models.Project, autoIDE bool, ideName string, exportContext string) error {
+func showNonBlockingMenu(projects []models.Project, prefixes map[string]string, autoIDE bool, ideName string, exportContext string) error {
 	// Create a clean text-based menu for non-interactive environments
 	fmt.Println("Available Projects:")
 	fmt.Println("==================")
 	
+	taskCounts := openTaskCounts()
 	for i, p := range projects {
 		statusIndicator := getStatusIndicator(string(p.Status))
-		fmt.Printf("%d. [%s] %s | %s | Progress: %d%%\n", 
-			i+1, statusIndicator, p.Name, p.Status, p.Progress)
+		fmt.Printf("%d. [%s] %s%s | %s | Progress: %d%%%s\n", 
+			i+1, statusIndicator, prefixes[p.ID], p.Name, p.Status, p.Progress, taskSuffix(taskCounts[p.ID]))
 	}
 	
 	fmt.Println("\nAvailable Actions:")
@@ -325,14 +382,7 @@ func openProjectInIDE(projectPath, ideName string) error {
 }
 
 func generateProjectContext(project *models.Project, format string) error {
-	generator := utils.NewContextGenerator(project.Path)
-	
-	context, err := generator.GenerateAgentContext(
-		project.Name,
-		string(project.Status),
-		project.Progress,
-		project.Notes,
-	)
+	context, err := newAgentContext(project)
 	if err != nil {
 		return fmt.Errorf("failed to generate context: %w", err)
 	}
@@ -483,6 +533,7 @@ func updateProjectStatus(project *models.Project) error {
 	}
 
 	projectRepo := repository.NewProjectRepository(db.Conn())
+	before := *project
 	project.Status = models.ProjectStatus(newStatus)
 	project.UpdatedAt = time.Now()
 	
@@ -497,6 +548,11 @@ func updateProjectStatus(project *models.Project) error {
 		return fmt.Errorf("failed to update project: %w", err)
 	}
 
+	activityRepo := repository.NewActivityRepository(db.Conn())
+	if err := activityRepo.RecordChanges(&before, project, "list"); err != nil {
+		logger.Warn("Failed to log activity: %v", err)
+	}
+
 	fmt.Printf("Project status updated to: %s\n", newStatus)
 	return nil
 }
@@ -610,5 +666,7 @@ func init() {
 	listCmd.Flags().BoolP("auto-ide", "a", false, "Automatically open selected project in IDE")
 	listCmd.Flags().String("ide", "", "Specify IDE to use (vscode, windsurf, vim, nano)")
 	listCmd.Flags().String("export-context", "", "Export context to specified file")
+	listCmd.Flags().String("sort", "", "Sort by name, progress, updated, created, last_scanned or last_commit; prefix with - for descending (default -updated)")
+	listCmd.Flags().String("cursor", "", "Continue after the last project of a previous page")
 	rootCmd.AddCommand(listCmd)
 }
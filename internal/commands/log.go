@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/filter"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log [project-name]",
+	Short: "Show how projects evolved over time",
+	Long: `Show how projects evolved over time, the most recent change first.
+
+Status, progress and note changes, projects added, updated, relocated or
+gone missing during scans, projects removed by prune, AI analyses and tasks
+are all recorded; the history of a removed project is kept. --since
+takes a duration such as 7d, 2w or 12h, or a date such as 2024-05-01.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+		outputFormat, _ := cmd.Flags().GetString("format")
+
+		since, err := filter.ParseTime(sinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+
+		projectRepo := repository.NewProjectRepository(db.Conn())
+		projectID := ""
+		if len(args) == 1 {
+			projects, err := projectRepo.Search(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to search projects: %w", err)
+			}
+
+			if len(projects) == 0 {
+				return fmt.Errorf("project not found: %s", args[0])
+			}
+
+			projectID = projects[0].ID
+		}
+
+		activityRepo := repository.NewActivityRepository(db.Conn())
+		entries, err := activityRepo.List(projectID, since, limit)
+		if err != nil {
+			return fmt.Errorf("failed to read activity log: %w", err)
+		}
+
+		switch strings.ToLower(outputFormat) {
+		case "json":
+			if entries == nil {
+				entries = []models.ActivityLog{}
+			}
+			data, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		case "text":
+			return printActivity(projectRepo, entries)
+		default:
+			return fmt.Errorf("format must be 'text' or 'json'")
+		}
+		return nil
+	},
+}
+
+func printActivity(projectRepo *repository.ProjectRepository, entries []models.ActivityLog) error {
+	if len(entries) == 0 {
+		fmt.Println("No activity recorded")
+		return nil
+	}
+
+	names := make(map[string]string)
+	for _, e := range entries {
+		name, ok := names[e.ProjectID]
+		if !ok {
+			name = removedProjectName(entries, e.ProjectID)
+			if project, err := projectRepo.GetByID(e.ProjectID); err == nil {
+				name = project.Name
+			}
+			names[e.ProjectID] = name
+		}
+		fmt.Printf("%s  %-20s %-18s %s\n", e.Timestamp.Format("2006-01-02 15:04"), name, e.Action, describeActivity(e))
+	}
+	return nil
+}
+
+// removedProjectName returns the name of a project that no longer exists,
+// as recorded by its removal among entries, or its ID.
+func removedProjectName(entries []models.ActivityLog, projectID string) string {
+	for _, e := range entries {
+		var d struct{ Name string }
+		if e.ProjectID == projectID && e.Action == models.ActionProjectRemoved && json.Unmarshal(e.Details, &d) == nil && d.Name != "" {
+			return d.Name
+		}
+	}
+	return projectID
+}
+
+// describeActivity summarizes the details of an entry in one line.
+func describeActivity(e models.ActivityLog) string {
+	var d map[string]interface{}
+	if len(e.Details) == 0 || json.Unmarshal(e.Details, &d) != nil {
+		return string(e.Details)
+	}
+	str := func(key string) string {
+		if v, ok := d[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	by := ""
+	if s := str("by"); s != "" {
+		by = " (by " + s + ")"
+	}
+
+	switch e.Action {
+	case models.ActionStatusChanged:
+		return fmt.Sprintf("%s → %s%s", str("from"), str("to"), by)
+	case models.ActionProgressChanged:
+		return fmt.Sprintf("%s%% → %s%%%s", str("from"), str("to"), by)
+	case models.ActionNotesChanged:
+		if appended := str("appended"); appended != "" {
+			return fmt.Sprintf("added %q%s", truncate(appended, 60), by)
+		}
+		return "notes edited" + by
+	case models.ActionProjectAdded:
+		return str("path")
+	case models.ActionProjectRelocated:
+		return fmt.Sprintf("%s → %s", str("from"), str("to"))
+	case models.ActionProjectMissing:
+		return str("path") + " no longer exists"
+	case models.ActionProjectRemoved:
+		return str("path") + " removed" + by
+	case models.ActionProjectUpdated:
+		return describeScanChanges(d)
+	case models.ActionAnalyzed:
+		return fmt.Sprintf("%s with %s (%s tokens)", str("analysis_type"), str("model"), str("tokens_used"))
+	case models.ActionTaskAdded:
+		return fmt.Sprintf("#%s %s (%s)", str("id"), str("content"), str("priority"))
+	case models.ActionTaskDone:
+		return fmt.Sprintf("#%s %s", str("id"), str("content"))
+	}
+	return string(e.Details)
+}
+
+// describeScanChanges summarizes the details scanChanges records.
+func describeScanChanges(d map[string]interface{}) string {
+	var parts []string
+	if changes, ok := d["changes"].(map[string]interface{}); ok {
+		for _, field := range []string{"name", "description", "git_remote", "git_branch", "parent_id"} {
+			if c, ok := changes[field].(map[string]interface{}); ok {
+				parts = append(parts, fmt.Sprintf("%s %v → %v", strings.ReplaceAll(field, "_", " "), c["from"], c["to"]))
+			}
+		}
+	}
+	list := func(key, prefix string) {
+		if items, ok := d[key].([]interface{}); ok && len(items) > 0 {
+			var names []string
+			for _, item := range items {
+				names = append(names, fmt.Sprint(item))
+			}
+			parts = append(parts, prefix+strings.Join(names, ", "))
+		}
+	}
+	list("technologies_added", "+")
+	list("technologies_removed", "-")
+	if versions, ok := d["technologies_updated"].([]interface{}); ok {
+		for _, v := range versions {
+			if m, ok := v.(map[string]interface{}); ok {
+				parts = append(parts, fmt.Sprintf("%v %v → %v", m["name"], m["from"], m["to"]))
+			}
+		}
+	}
+	if n, ok := d["todos_added"].(float64); ok {
+		parts = append(parts, fmt.Sprintf("%d new TODOs", int(n)))
+	}
+	if n, ok := d["todos_completed"].(float64); ok {
+		parts = append(parts, fmt.Sprintf("%d TODOs gone", int(n)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(strings.ReplaceAll(s, "\n", " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "..."
+}
+
+func init() {
+	logCmd.Flags().String("since", "", "Only show activity since a duration ago (7d, 2w, 12h) or a date (2006-01-02)")
+	logCmd.Flags().IntP("limit", "n", 50, "Maximum number of entries (0 for all)")
+	logCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
+	rootCmd.AddCommand(logCmd)
+}
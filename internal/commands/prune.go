@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/logger"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+	"github.com/snowarch/project-memory/internal/scanner"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Find projects whose directories no longer exist",
+	Long: `Find projects whose directories no longer exist.
+
+Missing projects are relocated when a directory with the same .pmem-id
+marker, git remote or root commit is found under one of the --search paths.
+With --match-names a directory with the same name as a single missing
+project is also offered, after confirmation, since an unrelated project may
+share it. Projects that cannot be relocated are marked as missing, or
+archived or deleted with --archive and --delete.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archive, _ := cmd.Flags().GetBool("archive")
+		remove, _ := cmd.Flags().GetBool("delete")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		searchPaths, _ := cmd.Flags().GetStringSlice("search")
+		matchNames, _ := cmd.Flags().GetBool("match-names")
+
+		if archive && remove {
+			return fmt.Errorf("--archive and --delete cannot be used together")
+		}
+		if len(searchPaths) == 0 && rootPath != "" {
+			searchPaths = []string{rootPath}
+		}
+		if matchNames && len(searchPaths) == 0 {
+			return fmt.Errorf("--match-names needs --search or --path")
+		}
+
+		projectRepo := repository.NewProjectRepository(db.Conn())
+		missing, err := findMissingProjects(projectRepo)
+		if err != nil {
+			return err
+		}
+
+		if len(missing) == 0 {
+			logger.Info("No missing projects")
+			return nil
+		}
+
+		logger.Info("Found %d missing projects", len(missing))
+
+		reloc := scanner.NewRelocator(missing)
+		reloc.MatchNames = matchNames
+		if len(searchPaths) > 0 {
+			if err := relocateFrom(reloc, searchPaths, dryRun); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, p := range reloc.Remaining() {
+			action := "marked missing"
+			switch {
+			case remove:
+				action = "deleted"
+			case archive:
+				action = "archived"
+			}
+
+			if dryRun {
+				fmt.Printf("  would be %s: %s (%s)\n", action, p.Name, p.Path)
+				continue
+			}
+
+			if err := pruneProject(projectRepo, p, now, archive, remove); err != nil {
+				logger.Error("Failed to prune %s: %v", p.Name, err)
+				continue
+			}
+			fmt.Printf("  %s: %s (%s)\n", action, p.Name, p.Path)
+		}
+
+		return nil
+	},
+}
+
+// findMissingProjects returns the tracked projects whose path no longer
+// exists on disk.
+func findMissingProjects(projectRepo *repository.ProjectRepository) ([]models.Project, error) {
+	projects, err := projectRepo.List("", -1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	var missing []models.Project
+	for _, p := range projects {
+		// A relative path was stored by an older scan and resolves against
+		// the working directory, so whether it exists says nothing.
+		if !filepath.IsAbs(p.Path) {
+			continue
+		}
+		if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+func pruneProject(projectRepo *repository.ProjectRepository, p models.Project, now time.Time, archive, remove bool) error {
+	if remove {
+		// The activity log is kept, so the project's history ends with its
+		// removal; the entry names it as nothing else will.
+		return db.WithTx(func(tx *sql.Tx) error {
+			err := repository.NewActivityRepository(tx).Record(p.ID, models.ActionProjectRemoved, map[string]interface{}{
+				"name": p.Name, "path": p.Path, "by": "prune",
+			})
+			if err != nil {
+				return err
+			}
+			return repository.NewProjectRepository(tx).Delete(p.ID)
+		})
+	}
+
+	if err := markMissing(projectRepo, p, now); err != nil {
+		return err
+	}
+
+	if archive && p.Status != models.StatusArchived {
+		before := p
+		p.Status = models.StatusArchived
+		p.UpdatedAt = now
+		if p.MissingSince == nil {
+			p.MissingSince = &now
+		}
+		if err := projectRepo.Update(&p); err != nil {
+			return err
+		}
+		return repository.NewActivityRepository(db.Conn()).RecordChanges(&before, &p, "prune")
+	}
+	return nil
+}
+
+// markMissing flags a project whose directory no longer exists and logs it
+// the first time.
+func markMissing(projectRepo *repository.ProjectRepository, p models.Project, now time.Time) error {
+	if err := projectRepo.MarkMissing(p.ID, now); err != nil {
+		return err
+	}
+	if p.MissingSince != nil {
+		return nil
+	}
+	return repository.NewActivityRepository(db.Conn()).Record(p.ID, models.ActionProjectMissing, map[string]interface{}{
+		"path": p.Path,
+	})
+}
+
+// relocateFrom discovers projects under the search paths and moves missing
+// projects to the untracked directories that match them.
+func relocateFrom(reloc *scanner.Relocator, searchPaths []string, dryRun bool) error {
+	projectRepo := repository.NewProjectRepository(db.Conn())
+
+	var candidates []string
+	for _, searchPath := range searchPaths {
+		paths, err := scanner.New(searchPath).DiscoverProjects()
+		if err != nil {
+			return fmt.Errorf("failed to search %s: %w", searchPath, err)
+		}
+
+		for _, path := range paths {
+			existing, err := projectRepo.GetByPath(path)
+			if err != nil {
+				return fmt.Errorf("failed to check existing project: %w", err)
+			}
+			if existing == nil {
+				candidates = append(candidates, path)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := scanner.New(searchPaths[0])
+	return s.AnalyzeConcurrent(ctx, candidates, runtime.NumCPU(), func(res scanner.Result) error {
+		if res.Err != nil {
+			logger.Debug("Skipping %s: %v", res.Path, res.Err)
+			return nil
+		}
+
+		old, kind := reloc.Find(res)
+		if old == nil {
+			return nil
+		}
+
+		if dryRun {
+			reloc.Match(res)
+			fmt.Printf("  would be relocated: %s (%s -> %s, by %s)\n", old.Name, old.Path, res.Path, kind)
+			return nil
+		}
+
+		if kind == scanner.MatchName && !confirm(fmt.Sprintf("Relocate %s (%s -> %s)? Only the directory name matches.", old.Name, old.Path, res.Path)) {
+			return nil
+		}
+
+		if _, err := saveScanResult(&res, reloc); err != nil {
+			logger.Error("Failed to relocate %s: %v", old.Name, err)
+			return nil
+		}
+		fmt.Printf("  relocated: %s (%s -> %s)\n", old.Name, old.Path, res.Path)
+		return nil
+	})
+}
+
+// confirm asks a yes/no question on the terminal; anything but yes, or no
+// answer at all, is no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	pruneCmd.Flags().Bool("archive", false, "Archive projects that cannot be relocated")
+	pruneCmd.Flags().Bool("delete", false, "Delete projects that cannot be relocated")
+	pruneCmd.Flags().Bool("dry-run", false, "Show what would be done without changing anything")
+	pruneCmd.Flags().StringSlice("search", nil, "Directories to search for moved projects (defaults to --path)")
+	pruneCmd.Flags().Bool("match-names", false, "Also offer directories matching a missing project by name alone, asking for each")
+	rootCmd.AddCommand(pruneCmd)
+}
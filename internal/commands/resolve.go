@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+)
+
+// resolveProject finds the project a command argument refers to: a project
+// ID, then a project path, then a project name, each matched exactly. It
+// fails when several projects share the name, listing them so the ID or
+// path can be given instead, and suggests projects whose name, description
+// or path contain ref when none matches.
+func resolveProject(projectRepo *repository.ProjectRepository, ref string) (*models.Project, error) {
+	if project, err := projectRepo.GetByID(ref); err == nil {
+		return project, nil
+	}
+
+	if path, err := filepath.Abs(ref); err == nil {
+		project, err := projectRepo.GetByPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up project: %w", err)
+		}
+		if project != nil {
+			return project, nil
+		}
+	}
+
+	projects, err := projectRepo.ListByName(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up project: %w", err)
+	}
+	switch len(projects) {
+	case 1:
+		return &projects[0], nil
+	case 0:
+		similar, _ := projectRepo.Search(ref)
+		if len(similar) == 0 {
+			return nil, fmt.Errorf("project not found: %s", ref)
+		}
+		return nil, fmt.Errorf("project not found: %s; did you mean one of:\n%s", ref, projectCandidates(similar))
+	default:
+		return nil, fmt.Errorf("%d projects are named %s; give the ID or path of one of:\n%s", len(projects), ref, projectCandidates(projects))
+	}
+}
+
+// projectCandidates lists projects one per line with their ID and path.
+func projectCandidates(projects []models.Project) string {
+	var lines []string
+	for _, p := range projects {
+		lines = append(lines, fmt.Sprintf("  %s  %s  %s", p.ID, p.Name, p.Path))
+	}
+	return strings.Join(lines, "\n")
+}
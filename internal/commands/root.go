@@ -1,13 +1,16 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/snowarch/project-memory/internal/database"
+	"github.com/snowarch/project-memory/internal/ignore"
 	"github.com/snowarch/project-memory/internal/logger"
+	"github.com/snowarch/project-memory/internal/repository"
 )
 
 var (
@@ -35,7 +38,8 @@ var rootCmd = &cobra.Command{
 			logger.SetLevel(logger.LevelInfo)
 		}
 
-		if cmd.Name() == "init" {
+		// pmem db opens the database itself, without migrating it.
+		if cmd.Name() == "init" || cmd == dbCmd || cmd.Parent() == dbCmd {
 			return
 		}
 
@@ -43,9 +47,18 @@ var rootCmd = &cobra.Command{
 		db, err = database.New(dbPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Run 'pmem init' first\n")
+			if !errors.Is(err, database.ErrSchemaTooNew) {
+				fmt.Fprintf(os.Stderr, "Run 'pmem init' first\n")
+			}
 			os.Exit(1)
 		}
+
+		configRepo := repository.NewConfigRepository(db.Conn())
+		patterns, err := configRepo.GetIgnorePatterns()
+		if err != nil {
+			logger.Warn("Failed to load global ignore patterns: %v", err)
+		}
+		ignore.SetGlobalPatterns(patterns)
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		if db != nil {
@@ -1,11 +1,21 @@
 package commands
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/snowarch/project-memory/internal/logger"
+	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
 	"github.com/snowarch/project-memory/internal/scanner"
 )
@@ -27,71 +37,410 @@ var scanCmd = &cobra.Command{
 		if _, err := os.Stat(scanPath); os.IsNotExist(err) {
 			return fmt.Errorf("path does not exist: %s", scanPath)
 		}
+		if abs, err := filepath.Abs(scanPath); err == nil {
+			scanPath = abs
+		}
+
+		maxDepth, _ := cmd.Flags().GetInt("max-depth")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		full, _ := cmd.Flags().GetBool("full")
+		writeIDs, _ := cmd.Flags().GetBool("write-ids")
 
 		logger.Info("Scanning projects in: %s", scanPath)
 
 		s := scanner.New(scanPath)
-		projects, err := s.ScanProjects()
+		s.SetMaxDepth(maxDepth)
+
+		paths, err := s.DiscoverProjects()
 		if err != nil {
 			return fmt.Errorf("scan failed: %w", err)
 		}
 
-		logger.Info("Found %d projects", len(projects))
+		logger.Info("Found %d projects", len(paths))
+
+		if !full {
+			fileRepo := repository.NewProjectFileRepository(db.Conn())
+			previous, err := fileRepo.GetAllByPath()
+			if err != nil {
+				return fmt.Errorf("failed to load previous scan state: %w", err)
+			}
+			s.SetPreviousFingerprints(previous)
+		}
 
+		// Projects whose directories are gone may show up again among the
+		// discovered paths after being moved.
 		projectRepo := repository.NewProjectRepository(db.Conn())
-		techRepo := repository.NewTechnologyRepository(db.Conn())
+		if !full {
+			roots, err := projectRepo.RootCommitsByPath()
+			if err != nil {
+				return fmt.Errorf("failed to load previous scan state: %w", err)
+			}
+			s.SetPreviousRootCommits(roots)
+		}
+		missing, err := findMissingProjects(projectRepo)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		reloc := scanner.NewRelocator(missing)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
 		added := 0
 		updated := 0
+		relocated := 0
+		unchanged := 0
+		failed := 0
+		done := 0
 
-		for _, project := range projects {
-			existing, err := projectRepo.GetByPath(project.Path)
-			if err != nil {
-				return fmt.Errorf("failed to check existing project: %w", err)
+		// record saves a result and then, under it, the members of a
+		// workspace root. It returns the ID of the saved project.
+		var record func(res scanner.Result, parentID string) string
+		record = func(res scanner.Result, parentID string) string {
+			if res.Err != nil {
+				logger.Warn("Failed to analyze project %s: %v", res.Path, res.Err)
+				failed++
+				return ""
 			}
 
-			if existing == nil {
-				if err := projectRepo.Create(&project); err != nil {
-					logger.Error("Failed to add project %s: %v", project.Name, err)
-					continue
+			id := ""
+			if res.Unchanged {
+				var err error
+				if id, err = saveUnchangedResult(&res); err != nil {
+					logger.Warn("Failed to save %s: %v", res.Path, err)
 				}
-				added++
-				logger.Debug("Added new project: %s", project.Name)
+				unchanged++
+				logger.Debug("Unchanged project: %s", res.Path)
 			} else {
-				project.ID = existing.ID
-				project.Status = existing.Status
-				project.Progress = existing.Progress
-				project.Notes = existing.Notes
-				
-				if err := projectRepo.Update(&project); err != nil {
-					logger.Error("Failed to update project %s: %v", project.Name, err)
-					continue
+				res.Project.ParentID = parentID
+				outcome, err := saveScanResult(&res, reloc)
+				if err != nil {
+					logger.Error("Failed to save project %s: %v", res.Project.Name, err)
+					failed++
+					return ""
+				}
+				id = res.Project.ID
+
+				switch outcome {
+				case scanAdded:
+					added++
+					logger.Debug("Added new project: %s", res.Project.Name)
+				case scanRelocated:
+					relocated++
+					logger.Info("Relocated project %s to %s", res.Project.Name, res.Project.Path)
+				default:
+					updated++
+					logger.Debug("Updated existing project: %s", res.Project.Name)
 				}
-				updated++
-				logger.Debug("Updated existing project: %s", project.Name)
 			}
 
-			techs, err := s.DetectTechnologies(project.Path)
-			if err != nil {
-				logger.Error("Failed to detect technologies for %s: %v", project.Name, err)
-				continue
+			if writeIDs && id != "" && res.MarkerID != id {
+				if err := scanner.WriteIDMarker(res.Path, id); err != nil {
+					logger.Warn("Failed to write %s in %s: %v", scanner.IDMarkerFile, res.Path, err)
+				}
 			}
 
-			if err := techRepo.DeleteByProject(project.ID); err != nil {
-				logger.Warn("Failed to clear technologies for %s: %v", project.Name, err)
+			for _, member := range res.Members {
+				record(member, id)
 			}
+			return id
+		}
 
-			for i := range techs {
-				techs[i].ProjectID = project.ID
-				if err := techRepo.Create(&techs[i]); err != nil {
-					logger.Warn("Failed to save technology %s: %v", techs[i].Name, err)
-				}
+		err = s.AnalyzeConcurrent(ctx, paths, jobs, func(res scanner.Result) error {
+			done++
+			record(res, "")
+			if !res.Unchanged && res.Err == nil {
+				logger.Progress("  [%d/%d] %s (%s)", done, len(paths), res.Project.Name, res.Project.Path)
 			}
+			return nil
+		})
+
+		if errors.Is(err, context.Canceled) {
+			logger.Warn("Scan interrupted: %d added, %d updated before cancellation", added, updated)
+			return fmt.Errorf("scan interrupted after %d of %d projects", done, len(paths))
+		}
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
 
-			logger.Progress("  %s (%s)", project.Name, project.Path)
+		stillMissing := reloc.Remaining()
+		now := time.Now()
+		for _, p := range stillMissing {
+			if err := markMissing(projectRepo, p, now); err != nil {
+				logger.Warn("Failed to mark %s as missing: %v", p.Name, err)
+			}
+			logger.Debug("Missing project: %s (%s)", p.Name, p.Path)
 		}
 
-		logger.Info("\nScan complete: %d added, %d updated", added, updated)
+		logger.Info("\nScan complete: %d added, %d updated, %d relocated, %d unchanged, %d failed", added, updated, relocated, unchanged, failed)
+		if len(stillMissing) > 0 {
+			logger.Warn("%d projects no longer exist on disk; run 'pmem prune' to archive or delete them", len(stillMissing))
+		}
 		return nil
 	},
 }
+
+// scanOutcome describes what saveScanResult did with a project.
+type scanOutcome int
+
+const (
+	scanUpdated scanOutcome = iota
+	scanAdded
+	scanRelocated
+)
+
+// saveScanResult stores a scanned project, its technologies, its language
+// stats, the TODO comments found in its code and its file fingerprints in a
+// single transaction, so an interrupted scan never leaves a project half
+// written. Additions, relocations and changes to the project, its direct
+// technologies and its TODOs are recorded in the activity log. A project
+// found at a new path
+// that matches one of reloc's missing projects is moved in place instead of
+// added, keeping its ID and everything attached to it.
+func saveScanResult(res *scanner.Result, reloc *scanner.Relocator) (scanOutcome, error) {
+	outcome := scanUpdated
+	project := &res.Project
+
+	err := db.WithTx(func(tx *sql.Tx) error {
+		projectRepo := repository.NewProjectRepository(tx)
+		techRepo := repository.NewTechnologyRepository(tx)
+		fileRepo := repository.NewProjectFileRepository(tx)
+		statRepo := repository.NewLanguageStatRepository(tx)
+		todoRepo := repository.NewTodoRepository(tx)
+		activityRepo := repository.NewActivityRepository(tx)
+
+		existing, err := projectRepo.GetByPath(project.Path)
+		if err != nil {
+			return fmt.Errorf("failed to check existing project: %w", err)
+		}
+
+		if existing == nil {
+			if old := reloc.Match(*res); old != nil {
+				if err := projectRepo.Relocate(old.ID, project.Path, project.Name); err != nil {
+					return fmt.Errorf("failed to relocate project: %w", err)
+				}
+				existing = old
+				outcome = scanRelocated
+			}
+		}
+
+		var oldTechs []models.Technology
+		if existing != nil {
+			if oldTechs, err = techRepo.GetByProject(existing.ID); err != nil {
+				return fmt.Errorf("failed to get technologies: %w", err)
+			}
+		}
+
+		if existing == nil {
+			outcome = scanAdded
+			if res.MarkerID != "" {
+				// Adopt the marker's ID unless it belongs to another project
+				// that still exists, as happens when a directory is copied.
+				if other, _ := projectRepo.GetByID(res.MarkerID); other == nil {
+					project.ID = res.MarkerID
+				}
+			}
+			if err := projectRepo.Create(project); err != nil {
+				return fmt.Errorf("failed to add project: %w", err)
+			}
+		} else {
+			project.ID = existing.ID
+			project.Status = existing.Status
+			project.Progress = existing.Progress
+			project.Notes = existing.Notes
+
+			if err := projectRepo.Update(project); err != nil {
+				return fmt.Errorf("failed to update project: %w", err)
+			}
+		}
+
+		if err := techRepo.DeleteByProject(project.ID); err != nil {
+			return fmt.Errorf("failed to clear technologies: %w", err)
+		}
+
+		for i := range res.Technologies {
+			res.Technologies[i].ProjectID = project.ID
+			if err := techRepo.Create(&res.Technologies[i]); err != nil {
+				return fmt.Errorf("failed to save technology %s: %w", res.Technologies[i].Name, err)
+			}
+		}
+
+		if err := statRepo.ReplaceForProject(project.ID, res.Languages); err != nil {
+			return fmt.Errorf("failed to save language stats: %w", err)
+		}
+
+		todosAdded, todosCompleted, err := todoRepo.SyncExtracted(project.ID, res.Todos, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to save todos: %w", err)
+		}
+
+		if err := projectRepo.SaveReadme(project.ID, res.Readme, time.Now()); err != nil {
+			return fmt.Errorf("failed to save README: %w", err)
+		}
+
+		if err := fileRepo.ReplaceForProject(project.ID, res.Files); err != nil {
+			return fmt.Errorf("failed to save file fingerprints: %w", err)
+		}
+
+		if err := repository.NewSearchRepository(tx).Reindex(project.ID); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+
+		switch outcome {
+		case scanAdded:
+			err = activityRepo.Record(project.ID, models.ActionProjectAdded, map[string]interface{}{
+				"name":         project.Name,
+				"path":         project.Path,
+				"technologies": techNames(res.Technologies),
+				"todos":        todosAdded,
+			})
+		case scanRelocated:
+			details := scanChanges(existing, project, oldTechs, res.Technologies, todosAdded, todosCompleted)
+			details["from"], details["to"] = existing.Path, project.Path
+			err = activityRepo.Record(project.ID, models.ActionProjectRelocated, details)
+		default:
+			if details := scanChanges(existing, project, oldTechs, res.Technologies, todosAdded, todosCompleted); len(details) > 0 {
+				err = activityRepo.Record(project.ID, models.ActionProjectUpdated, details)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to log activity: %w", err)
+		}
+
+		return nil
+	})
+
+	return outcome, err
+}
+
+// saveUnchangedResult marks a project whose fingerprint did not change as
+// scanned. When its source files changed it also stores its language stats,
+// its TODOs, so that TODOs removed from the code are completed, and the
+// fingerprint recording the new source tree. It returns the ID of the
+// project, or "" when it is not tracked.
+func saveUnchangedResult(res *scanner.Result) (string, error) {
+	id := ""
+	err := db.WithTx(func(tx *sql.Tx) error {
+		projectRepo := repository.NewProjectRepository(tx)
+
+		project, err := projectRepo.GetByPath(res.Path)
+		if err != nil || project == nil {
+			return err
+		}
+		id = project.ID
+
+		now := time.Now()
+		if err := projectRepo.MarkScannedByPath(res.Path, now); err != nil {
+			return fmt.Errorf("failed to mark as scanned: %w", err)
+		}
+		if !res.SourcesChanged {
+			return nil
+		}
+		if err := repository.NewProjectFileRepository(tx).ReplaceForProject(project.ID, res.Files); err != nil {
+			return fmt.Errorf("failed to save file fingerprints: %w", err)
+		}
+		if err := repository.NewLanguageStatRepository(tx).ReplaceForProject(project.ID, res.Languages); err != nil {
+			return fmt.Errorf("failed to save language stats: %w", err)
+		}
+		todosAdded, todosCompleted, err := repository.NewTodoRepository(tx).SyncExtracted(project.ID, res.Todos, now)
+		if err != nil {
+			return fmt.Errorf("failed to save todos: %w", err)
+		}
+		if err := repository.NewSearchRepository(tx).Reindex(project.ID); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+
+		if details := scanChanges(project, project, nil, nil, todosAdded, todosCompleted); len(details) > 0 {
+			if err := repository.NewActivityRepository(tx).Record(project.ID, models.ActionProjectUpdated, details); err != nil {
+				return fmt.Errorf("failed to log activity: %w", err)
+			}
+		}
+		return nil
+	})
+	return id, err
+}
+
+// scanChanges describes what a scan changed in a project: the fields that
+// differ, the direct technologies added, removed or whose version changed,
+// and the TODOs found and completed. It is empty when nothing changed.
+func scanChanges(before, after *models.Project, oldTechs, newTechs []models.Technology, todosAdded, todosCompleted int) map[string]interface{} {
+	details := make(map[string]interface{})
+
+	fields := make(map[string]map[string]string)
+	for _, f := range []struct{ name, from, to string }{
+		{"name", before.Name, after.Name},
+		{"description", before.Description, after.Description},
+		{"git_remote", before.GitRemote, after.GitRemote},
+		{"git_branch", before.GitBranch, after.GitBranch},
+		{"parent_id", before.ParentID, after.ParentID},
+	} {
+		if f.from != f.to {
+			fields[f.name] = map[string]string{"from": f.from, "to": f.to}
+		}
+	}
+	if len(fields) > 0 {
+		details["changes"] = fields
+	}
+
+	type techKey struct{ typ, name string }
+	old := make(map[techKey]string)
+	for _, t := range oldTechs {
+		old[techKey{t.Type, t.Name}] = t.Version
+	}
+	var added []string
+	var versions []map[string]string
+	for _, t := range newTechs {
+		if t.Transitive {
+			continue
+		}
+		key := techKey{t.Type, t.Name}
+		version, existed := old[key]
+		delete(old, key)
+		switch {
+		case !existed:
+			added = append(added, t.Name)
+		case version != t.Version:
+			versions = append(versions, map[string]string{"name": t.Name, "from": version, "to": t.Version})
+		}
+	}
+	var removed []string
+	for key := range old {
+		removed = append(removed, key.name)
+	}
+	sort.Strings(removed)
+
+	if len(added) > 0 {
+		details["technologies_added"] = added
+	}
+	if len(removed) > 0 {
+		details["technologies_removed"] = removed
+	}
+	if len(versions) > 0 {
+		details["technologies_updated"] = versions
+	}
+	if todosAdded > 0 {
+		details["todos_added"] = todosAdded
+	}
+	if todosCompleted > 0 {
+		details["todos_completed"] = todosCompleted
+	}
+	return details
+}
+
+// techNames returns the names of the direct technologies in techs.
+func techNames(techs []models.Technology) []string {
+	names := []string{}
+	for _, t := range techs {
+		if !t.Transitive {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+func init() {
+	scanCmd.Flags().Int("max-depth", scanner.DefaultMaxDepth, "Maximum directory depth to search for projects (0 for unlimited)")
+	scanCmd.Flags().Bool("full", false, "Analyze every project even if its manifests and git HEAD are unchanged")
+	scanCmd.Flags().Bool("write-ids", false, "Write a "+scanner.IDMarkerFile+" marker into each project so it is recognized after being moved")
+	scanCmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "Number of projects to analyze concurrently")
+}
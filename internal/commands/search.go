@@ -1,52 +1,70 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/logger"
 	"github.com/snowarch/project-memory/internal/repository"
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search <query>",
-	Short: "Search projects by name, description, or path",
-	Args:  cobra.ExactArgs(1),
+	Short: "Search projects, their notes, READMEs, TODOs and AI analyses",
+	Long: `Search projects, their notes, READMEs, TODOs and AI analyses, the best
+matches first.
+
+Words match as prefixes and quoted phrases as written; every term must
+match. A term can be restricted to a field: name, path, description, notes,
+tech, readme, todo or analysis, as in 'tech:react'. 'status:active' keeps the
+projects with that status.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
+		query := strings.Join(args, " ")
 		status, _ := cmd.Flags().GetString("status")
 		limit, _ := cmd.Flags().GetInt("limit")
+		outputFormat, _ := cmd.Flags().GetString("format")
+
+		if status != "" {
+			query += " status:" + status
+		}
 
-		projectRepo := repository.NewProjectRepository(db.Conn())
+		searchRepo := repository.NewSearchRepository(db.Conn())
+		if !searchRepo.Indexed() {
+			logger.Debug("Full-text search is not available in this build; matching substrings")
+		}
 
-		projects, err := projectRepo.Search(query)
+		results, err := searchRepo.Search(query, limit)
 		if err != nil {
 			return fmt.Errorf("failed to search projects: %w", err)
 		}
 
-		// Filter by status if provided
-		if status != "" {
-			var filtered []models.Project
-			for _, project := range projects {
-				if string(project.Status) == status {
-					filtered = append(filtered, project)
-				}
+		switch strings.ToLower(outputFormat) {
+		case "json":
+			if results == nil {
+				results = []repository.SearchResult{}
 			}
-			projects = filtered
-		}
-
-		// Apply limit
-		if limit > 0 && len(projects) > limit {
-			projects = projects[:limit]
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		case "text":
+		default:
+			return fmt.Errorf("format must be 'text' or 'json'")
 		}
 
-		if len(projects) == 0 {
+		if len(results) == 0 {
 			fmt.Printf("No projects found matching: %s\n", query)
 			return nil
 		}
 
-		fmt.Printf("Found %d projects matching: %s\n\n", len(projects), query)
-		for i, project := range projects {
+		fmt.Printf("Found %d projects matching: %s\n\n", len(results), query)
+		for i, result := range results {
+			project := result.Project
 			statusIcon := getStatusIcon(string(project.Status))
 			fmt.Printf("%d. %s %s | %s | Progress: %d%%\n", 
 				i+1, statusIcon, project.Name, project.Status, project.Progress)
@@ -59,6 +77,9 @@ var searchCmd = &cobra.Command{
 				}
 				fmt.Printf("   Description: %s\n", desc)
 			}
+			if result.Snippet != "" {
+				fmt.Printf("   Match: %s\n", strings.Join(strings.Fields(result.Snippet), " "))
+			}
 			fmt.Println()
 		}
 
@@ -69,6 +90,7 @@ var searchCmd = &cobra.Command{
 func init() {
 	searchCmd.Flags().StringP("status", "s", "", "Filter by status (active, paused, completed, archived)")
 	searchCmd.Flags().IntP("limit", "l", 0, "Limit number of results")
+	searchCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
 	rootCmd.AddCommand(searchCmd)
 }
 
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/filter"
 	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
 	"github.com/snowarch/project-memory/internal/scanner"
@@ -30,6 +32,9 @@ var serverCmd = &cobra.Command{
 type APIServer struct {
 	projectRepo *repository.ProjectRepository
 	techRepo    *repository.TechnologyRepository
+	statRepo    *repository.LanguageStatRepository
+	activityRepo *repository.ActivityRepository
+	searchRepo  *repository.SearchRepository
 	router      *mux.Router
 }
 
@@ -42,14 +47,26 @@ type ProjectResponse struct {
 	Description string                 `json:"description"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
+	LastCommitAt *time.Time            `json:"last_commit_at,omitempty"`
 	IsGitRepo   bool                   `json:"is_git_repo"`
 	GitRemote   string                 `json:"git_remote,omitempty"`
 	GitBranch   string                 `json:"git_branch,omitempty"`
 	Notes       string                 `json:"notes,omitempty"`
+	ParentID    string                 `json:"parent_id,omitempty"`
+	Children    []utils.ProjectRef     `json:"children,omitempty"`
 	Technologies []models.Technology   `json:"technologies,omitempty"`
+	Languages   []models.LanguageStat  `json:"languages,omitempty"`
 	Context     *utils.AgentContext    `json:"context,omitempty"`
 }
 
+// SearchResponse is a project found by a search, with the part of its text
+// that matched and how well.
+type SearchResponse struct {
+	ProjectResponse
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
@@ -60,6 +77,9 @@ func startAPIServer(host string, port int) error {
 	server := &APIServer{
 		projectRepo: repository.NewProjectRepository(db.Conn()),
 		techRepo:    repository.NewTechnologyRepository(db.Conn()),
+		statRepo:    repository.NewLanguageStatRepository(db.Conn()),
+		activityRepo: repository.NewActivityRepository(db.Conn()),
+		searchRepo:  repository.NewSearchRepository(db.Conn()),
 		router:      mux.NewRouter(),
 	}
 	
@@ -68,10 +88,12 @@ func startAPIServer(host string, port int) error {
 	addr := fmt.Sprintf("%s:%d", host, port)
 	fmt.Printf("Starting Project Memory API Server on %s\n", addr)
 	fmt.Printf("Available endpoints:\n")
-	fmt.Printf("  GET    /api/v1/projects              - List all projects\n")
-	fmt.Printf("  GET    /api/v1/projects/{id}         - Get project details\n")
+	fmt.Printf("  GET    /api/v1/projects              - List projects (?filter=&sort=&cursor=, next page in X-Next-Cursor; ?parent={id} for workspace members)\n")
+	fmt.Printf("  GET    /api/v1/projects/{id}         - Get project details (?transitive=true for lock file dependencies)\n")
 	fmt.Printf("  GET    /api/v1/projects/{id}/context - Get project context\n")
 	fmt.Printf("  POST   /api/v1/projects/{id}/open    - Open project in IDE\n")
+	fmt.Printf("  GET    /api/v1/projects/{id}/activity - Project timeline (?since=7d&limit=50)\n")
+	fmt.Printf("  GET    /api/v1/activity              - Timeline of every project (?since=7d&limit=50)\n")
 	fmt.Printf("  GET    /api/v1/health                - Health check\n")
 	fmt.Printf("  GET    /api/v1/agents/info           - Agent integration info\n")
 	
@@ -88,6 +110,8 @@ func (s *APIServer) setupRoutes() {
 	s.router.HandleFunc("/api/v1/projects/{id}/context", s.getProjectContextHandler).Methods("GET")
 	s.router.HandleFunc("/api/v1/projects/{id}/open", s.openProjectHandler).Methods("POST")
 	s.router.HandleFunc("/api/v1/projects/{id}/handoff", s.generateHandoffHandler).Methods("POST")
+	s.router.HandleFunc("/api/v1/projects/{id}/activity", s.projectActivityHandler).Methods("GET")
+	s.router.HandleFunc("/api/v1/activity", s.activityHandler).Methods("GET")
 	
 	// Search endpoints
 	s.router.HandleFunc("/api/v1/search", s.searchProjectsHandler).Methods("GET")
@@ -111,7 +135,7 @@ func (s *APIServer) healthHandler(w http.ResponseWriter, r *http.Request) {
 func (s *APIServer) agentInfoHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	
-	projects, err := s.projectRepo.List("", 100, 0)
+	projects, err := s.projectRepo.Find(nil, 0, 0)
 	if err != nil {
 		s.sendError(w, "Failed to get projects", http.StatusInternalServerError)
 		return
@@ -154,15 +178,42 @@ func (s *APIServer) listProjectsHandler(w http.ResponseWriter, r *http.Request)
 			limit = l
 		}
 	}
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
 	
-	projects, err := s.projectRepo.List(status, limit, 0)
+	f, err := parseProjectFilter(r.URL.Query().Get("filter"), status)
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	page := &repository.ProjectPage{}
+	if parentID := r.URL.Query().Get("parent"); parentID != "" {
+		page.Projects, err = s.projectRepo.ListChildren(parentID)
+	} else {
+		page, err = s.projectRepo.Page(repository.ProjectQuery{
+			Filter: f,
+			Sort:   r.URL.Query().Get("sort"),
+			Limit:  limit,
+			Cursor: r.URL.Query().Get("cursor"),
+			Offset: offset,
+		})
+	}
+	if errors.Is(err, repository.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) {
+		s.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		s.sendError(w, "Failed to list projects", http.StatusInternalServerError)
 		return
 	}
 	
 	var responses []ProjectResponse
-	for _, p := range projects {
+	for _, p := range page.Projects {
 		techs, _ := s.techRepo.GetByProject(p.ID)
 		responses = append(responses, ProjectResponse{
 			ID:           p.ID,
@@ -171,8 +222,10 @@ func (s *APIServer) listProjectsHandler(w http.ResponseWriter, r *http.Request)
 			Status:       string(p.Status),
 			Progress:     p.Progress,
 			Description:  p.Description,
+			ParentID:     p.ParentID,
 			CreatedAt:    p.CreatedAt,
 			UpdatedAt:    p.UpdatedAt,
+			LastCommitAt: p.LastCommitAt,
 			IsGitRepo:    p.IsGitRepo,
 			GitRemote:    p.GitRemote,
 			GitBranch:    p.GitBranch,
@@ -181,6 +234,17 @@ func (s *APIServer) listProjectsHandler(w http.ResponseWriter, r *http.Request)
 		})
 	}
 	
+	// The body stays a bare array for existing clients; the next page is
+	// announced in headers instead.
+	if page.NextCursor != "" {
+		next := *r.URL
+		query := next.Query()
+		query.Set("cursor", page.NextCursor)
+		query.Del("offset")
+		next.RawQuery = query.Encode()
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+	}
 	json.NewEncoder(w).Encode(responses)
 }
 
@@ -196,7 +260,12 @@ func (s *APIServer) getProjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	techs, _ := s.techRepo.GetByProject(project.ID)
+	// ?transitive=true adds the dependencies resolved from lock files.
+	getTechs := s.techRepo.GetByProject
+	if r.URL.Query().Get("transitive") == "true" {
+		getTechs = s.techRepo.GetAllByProject
+	}
+	techs, _ := getTechs(project.ID)
 	
 	response := ProjectResponse{
 		ID:           project.ID,
@@ -205,8 +274,10 @@ func (s *APIServer) getProjectHandler(w http.ResponseWriter, r *http.Request) {
 		Status:       string(project.Status),
 		Progress:     project.Progress,
 		Description:  project.Description,
+		ParentID:     project.ParentID,
 		CreatedAt:    project.CreatedAt,
 		UpdatedAt:    project.UpdatedAt,
+		LastCommitAt: project.LastCommitAt,
 		IsGitRepo:    project.IsGitRepo,
 		GitRemote:    project.GitRemote,
 		GitBranch:    project.GitBranch,
@@ -214,6 +285,16 @@ func (s *APIServer) getProjectHandler(w http.ResponseWriter, r *http.Request) {
 		Technologies: techs,
 	}
 	
+	if languages, err := s.statRepo.GetByProject(project.ID); err == nil {
+		response.Languages = languages
+	}
+	
+	if children, err := s.projectRepo.ListChildren(project.ID); err == nil {
+		for i := range children {
+			response.Children = append(response.Children, projectRef(&children[i]))
+		}
+	}
+	
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -229,13 +310,7 @@ func (s *APIServer) getProjectContextHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	
-	generator := utils.NewContextGenerator(project.Path)
-	context, err := generator.GenerateAgentContext(
-		project.Name,
-		string(project.Status),
-		project.Progress,
-		project.Notes,
-	)
+	context, err := newAgentContext(project)
 	if err != nil {
 		s.sendError(w, "Failed to generate context", http.StatusInternalServerError)
 		return
@@ -334,23 +409,40 @@ func (s *APIServer) searchProjectsHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 	
-	projects, err := s.projectRepo.Search(query)
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+	
+	results, err := s.searchRepo.Search(query, limit)
+	if errors.Is(err, repository.ErrEmptyQuery) {
+		s.sendError(w, "Query parameter 'q' has no search terms", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		s.sendError(w, "Search failed", http.StatusInternalServerError)
 		return
 	}
 	
-	var responses []ProjectResponse
-	for _, p := range projects {
+	responses := []SearchResponse{}
+	for _, result := range results {
+		p := result.Project
 		techs, _ := s.techRepo.GetByProject(p.ID)
-		responses = append(responses, ProjectResponse{
-			ID:           p.ID,
-			Name:         p.Name,
-			Path:         p.Path,
-			Status:       string(p.Status),
-			Progress:     p.Progress,
-			Description:  p.Description,
-			Technologies: techs,
+		responses = append(responses, SearchResponse{
+			ProjectResponse: ProjectResponse{
+				ID:           p.ID,
+				Name:         p.Name,
+				Path:         p.Path,
+				Status:       string(p.Status),
+				Progress:     p.Progress,
+				Description:  p.Description,
+				ParentID:     p.ParentID,
+				Technologies: techs,
+			},
+			Snippet: result.Snippet,
+			Score:   result.Score,
 		})
 	}
 	
@@ -366,7 +458,8 @@ func (s *APIServer) searchByTechnologyHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 	
-	projects, err := s.projectRepo.List("", 100, 0)
+	f := &filter.Filter{Terms: []filter.Term{{Field: "tech", Op: filter.OpMatch, Values: []string{tech}}}}
+	projects, err := s.projectRepo.Find(f, 0, 0)
 	if err != nil {
 		s.sendError(w, "Failed to get projects", http.StatusInternalServerError)
 		return
@@ -375,20 +468,16 @@ func (s *APIServer) searchByTechnologyHandler(w http.ResponseWriter, r *http.Req
 	var responses []ProjectResponse
 	for _, p := range projects {
 		techs, _ := s.techRepo.GetByProject(p.ID)
-		for _, t := range techs {
-			if t.Name == tech {
-				responses = append(responses, ProjectResponse{
-					ID:           p.ID,
-					Name:         p.Name,
-					Path:         p.Path,
-					Status:       string(p.Status),
-					Progress:     p.Progress,
-					Description:  p.Description,
-					Technologies: techs,
-				})
-				break
-			}
-		}
+		responses = append(responses, ProjectResponse{
+			ID:           p.ID,
+			Name:         p.Name,
+			Path:         p.Path,
+			Status:       string(p.Status),
+			Progress:     p.Progress,
+			Description:  p.Description,
+			ParentID:     p.ParentID,
+			Technologies: techs,
+		})
 	}
 	
 	json.NewEncoder(w).Encode(responses)
@@ -420,13 +509,7 @@ func (s *APIServer) agentContextHandler(w http.ResponseWriter, r *http.Request)
 		}
 		
 		project := projects[0]
-		generator := utils.NewContextGenerator(project.Path)
-		context, err := generator.GenerateAgentContext(
-			project.Name,
-			string(project.Status),
-			project.Progress,
-			project.Notes,
-		)
+		context, err := newAgentContext(&project)
 		if err != nil {
 			continue
 		}
@@ -491,6 +574,55 @@ func (s *APIServer) discoverProjectsHandler(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// activityHandler returns the activity log of every project, the most
+// recent entry first.
+func (s *APIServer) activityHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	s.writeActivity(w, r, "")
+}
+
+func (s *APIServer) projectActivityHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	project, err := s.projectRepo.GetByID(mux.Vars(r)["id"])
+	if err != nil {
+		s.sendError(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	s.writeActivity(w, r, project.ID)
+}
+
+// writeActivity writes the entries selected by the since and limit query
+// parameters, which take the values of pmem log's flags.
+func (s *APIServer) writeActivity(w http.ResponseWriter, r *http.Request, projectID string) {
+	since, err := filter.ParseTime(r.URL.Query().Get("since"), time.Now())
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l >= 0 {
+			limit = l
+		}
+	}
+
+	entries, err := s.activityRepo.List(projectID, since, limit)
+	if err != nil {
+		s.sendError(w, "Failed to read activity log", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []models.ActivityLog{}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"activity": entries,
+		"count":    len(entries),
+	})
+}
+
 func (s *APIServer) sendError(w http.ResponseWriter, message string, code int) {
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(ErrorResponse{
@@ -501,7 +633,9 @@ func (s *APIServer) sendError(w http.ResponseWriter, message string, code int) {
 }
 
 func init() {
-	serverCmd.Flags().IntP("port", "p", 8080, "Port for the API server")
+	// No -p shorthand: it belongs to the persistent --path flag, and cobra
+	// panics on the clash as soon as the flags are parsed.
+	serverCmd.Flags().Int("port", 8080, "Port for the API server")
 	serverCmd.Flags().StringP("host", "H", "localhost", "Host for the API server")
 	rootCmd.AddCommand(serverCmd)
 }
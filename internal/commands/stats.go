@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+	"github.com/snowarch/project-memory/internal/stats"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats <project-name>",
+	Short: "Count files and lines of code per language",
+	Long: `Count files and lines of code per language.
+
+The project is counted again and the result stored, replacing the breakdown
+recorded by the last scan. Files excluded by the ignore rules, binary files
+and lock files are not counted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName := args[0]
+		outputFormat, _ := cmd.Flags().GetString("format")
+
+		projectRepo := repository.NewProjectRepository(db.Conn())
+		projects, err := projectRepo.Search(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to search projects: %w", err)
+		}
+
+		if len(projects) == 0 {
+			return fmt.Errorf("project not found: %s", projectName)
+		}
+
+		project := projects[0]
+
+		languages, err := stats.Count(project.Path, workspaceMembers(project.Path)...)
+		if err != nil {
+			return fmt.Errorf("failed to count lines: %w", err)
+		}
+
+		statRepo := repository.NewLanguageStatRepository(db.Conn())
+		if err := statRepo.ReplaceForProject(project.ID, languages); err != nil {
+			return fmt.Errorf("failed to save language stats: %w", err)
+		}
+
+		switch strings.ToLower(outputFormat) {
+		case "json":
+			data, err := json.MarshalIndent(languages, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		case "text":
+			printLanguageStats(project.Name, languages)
+		default:
+			return fmt.Errorf("format must be 'text' or 'json'")
+		}
+		return nil
+	},
+}
+
+func printLanguageStats(projectName string, languages []models.LanguageStat) {
+	fmt.Printf("Project: %s\n\n", projectName)
+	if len(languages) == 0 {
+		fmt.Println("No source files found")
+		return
+	}
+
+	var total models.LanguageStat
+	for _, l := range languages {
+		total.Files += l.Files
+		total.CodeLines += l.CodeLines
+		total.CommentLines += l.CommentLines
+		total.BlankLines += l.BlankLines
+	}
+
+	fmt.Printf("%-20s %8s %10s %10s %10s %7s\n", "Language", "Files", "Code", "Comment", "Blank", "Code %")
+	for _, l := range languages {
+		share := 0.0
+		if total.CodeLines > 0 {
+			share = float64(l.CodeLines) * 100 / float64(total.CodeLines)
+		}
+		fmt.Printf("%-20s %8d %10d %10d %10d %6.1f%%\n", l.Language, l.Files, l.CodeLines, l.CommentLines, l.BlankLines, share)
+	}
+	fmt.Printf("%-20s %8d %10d %10d %10d\n", "Total", total.Files, total.CodeLines, total.CommentLines, total.BlankLines)
+}
+
+func init() {
+	statsCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
+	rootCmd.AddCommand(statsCmd)
+}
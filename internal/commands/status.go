@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/logger"
 	"github.com/snowarch/project-memory/internal/models"
 	"github.com/snowarch/project-memory/internal/repository"
 )
@@ -33,6 +34,7 @@ var statusCmd = &cobra.Command{
 			fmt.Printf("Project: %s\n", project.Name)
 			fmt.Printf("Status: %s\n", project.Status)
 			fmt.Printf("Progress: %d%%\n", project.Progress)
+			fmt.Printf("Open tasks: %d\n", openTaskCounts()[project.ID])
 			return nil
 		}
 
@@ -59,6 +61,7 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("invalid status: %s (must be: active, paused, archived, completed)", newStatus)
 		}
 
+		before := project
 		project.Status = newStatus
 		
 		if newStatus == models.StatusCompleted && project.Progress < 100 {
@@ -69,6 +72,11 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("failed to update status: %w", err)
 		}
 
+		activityRepo := repository.NewActivityRepository(db.Conn())
+		if err := activityRepo.RecordChanges(&before, &project, "status"); err != nil {
+			logger.Warn("Failed to log activity: %v", err)
+		}
+
 		fmt.Printf("Status updated: %s → %s\n", project.Name, newStatus)
 		return nil
 	},
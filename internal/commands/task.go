@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/logger"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+	"github.com/snowarch/project-memory/internal/todo"
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Track tasks for projects by hand",
+	Long: `Track tasks for projects by hand.
+
+Tasks are kept with the TODO comments found in the code but never completed
+by a scan; mark them done with 'pmem task done'. Projects are given by ID,
+path or exact name.`,
+}
+
+var taskAddCmd = &cobra.Command{
+	Use:   "add <project> <task>",
+	Short: "Add a task to a project",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName, content := args[0], strings.TrimSpace(args[1])
+		priority, _ := cmd.Flags().GetString("priority")
+
+		priority = strings.ToLower(priority)
+		if priority != todo.PriorityHigh && priority != todo.PriorityMedium && priority != todo.PriorityLow {
+			return fmt.Errorf("invalid priority: %s (must be: high, medium, low)", priority)
+		}
+		if content == "" {
+			return fmt.Errorf("task text is empty")
+		}
+
+		projectRepo := repository.NewProjectRepository(db.Conn())
+		project, err := resolveProject(projectRepo, projectName)
+		if err != nil {
+			return err
+		}
+
+		task := &models.Todo{ProjectID: project.ID, Content: content, Priority: priority}
+		if err := repository.NewTodoRepository(db.Conn()).Create(task); err != nil {
+			return fmt.Errorf("failed to add task: %w", err)
+		}
+		if err := repository.NewSearchRepository(db.Conn()).Reindex(project.ID); err != nil {
+			logger.Warn("Failed to update search index: %v", err)
+		}
+
+		activityRepo := repository.NewActivityRepository(db.Conn())
+		err = activityRepo.Record(project.ID, models.ActionTaskAdded, map[string]interface{}{
+			"id": task.ID, "content": task.Content, "priority": task.Priority,
+		})
+		if err != nil {
+			logger.Warn("Failed to log activity: %v", err)
+		}
+
+		fmt.Printf("Task %d added to %s\n", task.ID, project.Name)
+		return nil
+	},
+}
+
+var taskDoneCmd = &cobra.Command{
+	Use:   "done <id>...",
+	Short: "Mark tasks as done",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		todoRepo := repository.NewTodoRepository(db.Conn())
+		activityRepo := repository.NewActivityRepository(db.Conn())
+		for _, arg := range args {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("invalid task id: %s", arg)
+			}
+
+			task, err := todoRepo.GetByID(id)
+			if err != nil {
+				return err
+			}
+			if task.Source != models.TodoSourceManual {
+				return fmt.Errorf("item %d is a %s comment in %s; it is completed when removed from the code", id, task.Kind, task.SourceFile)
+			}
+			if task.Completed {
+				fmt.Printf("Task %d was already done\n", id)
+				continue
+			}
+
+			if err := todoRepo.Complete(id, time.Now()); err != nil {
+				return fmt.Errorf("failed to complete task: %w", err)
+			}
+			if err := repository.NewSearchRepository(db.Conn()).Reindex(task.ProjectID); err != nil {
+				logger.Warn("Failed to update search index: %v", err)
+			}
+			err = activityRepo.Record(task.ProjectID, models.ActionTaskDone, map[string]interface{}{
+				"id": task.ID, "content": task.Content,
+			})
+			if err != nil {
+				logger.Warn("Failed to log activity: %v", err)
+			}
+			fmt.Printf("Task %d done: %s\n", id, task.Content)
+		}
+		return nil
+	},
+}
+
+var taskListCmd = &cobra.Command{
+	Use:   "list [project]",
+	Short: "List the open tasks of a project or of every project",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		allProjects, _ := cmd.Flags().GetBool("all-projects")
+		all, _ := cmd.Flags().GetBool("all")
+		priority, _ := cmd.Flags().GetString("priority")
+		outputFormat, _ := cmd.Flags().GetString("format")
+
+		if len(args) == 0 && !allProjects {
+			return fmt.Errorf("specify a project or --all-projects")
+		}
+
+		projectRepo := repository.NewProjectRepository(db.Conn())
+		projectID := ""
+		if len(args) == 1 {
+			project, err := resolveProject(projectRepo, args[0])
+			if err != nil {
+				return err
+			}
+
+			projectID = project.ID
+		}
+
+		tasks, err := repository.NewTodoRepository(db.Conn()).List(projectID, repository.TodoFilter{
+			Source:   models.TodoSourceManual,
+			Priority: priority,
+			All:      all,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		switch strings.ToLower(outputFormat) {
+		case "json":
+			if tasks == nil {
+				tasks = []models.Todo{}
+			}
+			data, err := json.MarshalIndent(tasks, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		case "text":
+			return printTasks(projectRepo, tasks)
+		default:
+			return fmt.Errorf("format must be 'text' or 'json'")
+		}
+		return nil
+	},
+}
+
+func printTasks(projectRepo *repository.ProjectRepository, tasks []models.Todo) error {
+	if len(tasks) == 0 {
+		fmt.Println("No tasks found")
+		return nil
+	}
+
+	names := make(map[string]string)
+	fmt.Printf("%-5s %-8s %-20s %-10s %s\n", "ID", "Priority", "Project", "Added", "Task")
+	for _, t := range tasks {
+		name, ok := names[t.ProjectID]
+		if !ok {
+			project, err := projectRepo.GetByID(t.ProjectID)
+			if err != nil {
+				return fmt.Errorf("failed to get project: %w", err)
+			}
+			name = project.Name
+			names[t.ProjectID] = name
+		}
+
+		content := t.Content
+		if t.Completed {
+			content += " (done)"
+		}
+		fmt.Printf("%-5d %-8s %-20s %-10s %s\n", t.ID, t.Priority, name, t.CreatedAt.Format("2006-01-02"), content)
+	}
+	return nil
+}
+
+// openTaskCounts returns the number of open manual tasks per project ID.
+// Counts are left out when they cannot be read, so listings still work.
+func openTaskCounts() map[string]int {
+	counts, err := repository.NewTodoRepository(db.Conn()).OpenCounts(models.TodoSourceManual)
+	if err != nil {
+		return map[string]int{}
+	}
+	return counts
+}
+
+// openTasks returns the open manual tasks of a project, the highest
+// priority first.
+func openTasks(projectID string) []models.Todo {
+	tasks, err := repository.NewTodoRepository(db.Conn()).List(projectID, repository.TodoFilter{Source: models.TodoSourceManual})
+	if err != nil {
+		return nil
+	}
+	return tasks
+}
+
+// taskSuffix describes the open tasks of a project in listings, or returns
+// "" when it has none.
+func taskSuffix(open int) string {
+	if open == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | Tasks: %d open", open)
+}
+
+func init() {
+	taskAddCmd.Flags().String("priority", todo.PriorityMedium, "Task priority (high, medium, low)")
+	taskListCmd.Flags().Bool("all-projects", false, "List the tasks of every project")
+	taskListCmd.Flags().BoolP("all", "a", false, "Include done tasks")
+	taskListCmd.Flags().String("priority", "", "Only show tasks of this priority (high, medium, low)")
+	taskListCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
+
+	taskCmd.AddCommand(taskAddCmd)
+	taskCmd.AddCommand(taskDoneCmd)
+	taskCmd.AddCommand(taskListCmd)
+	rootCmd.AddCommand(taskCmd)
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/repository"
+	"github.com/snowarch/project-memory/internal/todo"
+)
+
+var todosCmd = &cobra.Command{
+	Use:   "todos <project>",
+	Short: "List the TODO, FIXME, HACK and XXX comments of a project",
+	Long: `List the TODO, FIXME, HACK and XXX comments of a project, given by ID,
+path or exact name.
+
+The project is searched again and the stored items reconciled with what is
+found: items that moved keep their identity, new ones are added and those no
+longer in the code are marked completed. FIXME and XXX are high priority,
+TODO medium and HACK low, unless the comment says otherwise with a tag such
+as TODO(high) or TODO(p3), exclamation marks or words such as "urgent".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectName := args[0]
+		outputFormat, _ := cmd.Flags().GetString("format")
+		priority, _ := cmd.Flags().GetString("priority")
+		kind, _ := cmd.Flags().GetString("kind")
+		file, _ := cmd.Flags().GetString("file")
+		completed, _ := cmd.Flags().GetBool("completed")
+		all, _ := cmd.Flags().GetBool("all")
+
+		projectRepo := repository.NewProjectRepository(db.Conn())
+		project, err := resolveProject(projectRepo, projectName)
+		if err != nil {
+			return err
+		}
+
+		found, err := todo.Extract(project.Path, workspaceMembers(project.Path)...)
+		if err != nil {
+			return fmt.Errorf("failed to extract todos: %w", err)
+		}
+
+		todoRepo := repository.NewTodoRepository(db.Conn())
+		if _, _, err := todoRepo.SyncExtracted(project.ID, found, time.Now()); err != nil {
+			return fmt.Errorf("failed to save todos: %w", err)
+		}
+		if err := repository.NewSearchRepository(db.Conn()).Reindex(project.ID); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+
+		todos, err := todoRepo.List(project.ID, repository.TodoFilter{
+			Source:    models.TodoSourceCode,
+			Priority:  priority,
+			Kind:      kind,
+			File:      file,
+			Completed: completed,
+			All:       all,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list todos: %w", err)
+		}
+
+		switch strings.ToLower(outputFormat) {
+		case "json":
+			if todos == nil {
+				todos = []models.Todo{}
+			}
+			data, err := json.MarshalIndent(todos, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		case "text":
+			printTodos(project.Name, todos)
+		default:
+			return fmt.Errorf("format must be 'text' or 'json'")
+		}
+		return nil
+	},
+}
+
+func printTodos(projectName string, todos []models.Todo) {
+	fmt.Printf("Project: %s\n\n", projectName)
+	if len(todos) == 0 {
+		fmt.Println("No todos found")
+		return
+	}
+
+	fmt.Printf("%-8s %-6s %-40s %s\n", "Priority", "Kind", "Location", "Text")
+	for _, t := range todos {
+		location := fmt.Sprintf("%s:%d", t.SourceFile, t.LineNumber)
+		content := t.Content
+		if t.Completed {
+			content += " (done)"
+		}
+		fmt.Printf("%-8s %-6s %-40s %s\n", t.Priority, t.Kind, location, content)
+	}
+	fmt.Printf("\n%d items\n", len(todos))
+}
+
+// formatTodos lists todos one per line for AI prompts, keeping the first
+// limit of them.
+func formatTodos(todos []models.Todo, limit int) string {
+	if len(todos) == 0 {
+		return "(No open TODOs)"
+	}
+
+	var b strings.Builder
+	for i, t := range todos {
+		if i == limit {
+			fmt.Fprintf(&b, "... and %d more\n", len(todos)-limit)
+			break
+		}
+		fmt.Fprintf(&b, "- [%s] %s %s:%d %s\n", t.Priority, t.Kind, t.SourceFile, t.LineNumber, t.Content)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func init() {
+	todosCmd.Flags().StringP("format", "f", "text", "Output format (text, json)")
+	todosCmd.Flags().String("priority", "", "Only show items of this priority (high, medium, low)")
+	todosCmd.Flags().StringP("kind", "k", "", "Only show items of this kind (TODO, FIXME, HACK, XXX)")
+	todosCmd.Flags().String("file", "", "Only show items in files under this path")
+	todosCmd.Flags().Bool("completed", false, "Show completed items instead of open ones")
+	todosCmd.Flags().BoolP("all", "a", false, "Show open and completed items")
+	rootCmd.AddCommand(todosCmd)
+}
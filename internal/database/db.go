@@ -2,51 +2,107 @@ package database
 
 import (
 	"database/sql"
-	_ "embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-//go:embed schema.sql
-var schemaSQL string
-
 type DB struct {
-	conn *sql.DB
+	conn       *sql.DB
+	migrations []Migration
 }
 
+// New opens the database at dbPath, creating it when missing, applies any
+// pending migration and sets up the search index.
 func New(dbPath string) (*DB, error) {
+	db, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if err := db.setupSearchIndex(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set up search index: %w", err)
+	}
+
+	return db, nil
+}
+
+// Open opens the database at dbPath without migrating it. It refuses a
+// database whose schema is newer than this binary.
+func Open(dbPath string) (*DB, error) {
+	return open(dbPath, migrationFiles)
+}
+
+// Inspect opens the database at dbPath without migrating it or checking
+// its schema version, so the version of a database migrated by a newer
+// binary can still be reported. Nothing else should use such a database.
+func Inspect(dbPath string) (*DB, error) {
+	return connect(dbPath, migrationFiles)
+}
+
+func open(dbPath string, fsys fs.FS) (*DB, error) {
+	db, err := connect(dbPath, fsys)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.checkVersion(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func connect(dbPath string, fsys fs.FS) (*DB, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite3", dbPath)
+	// Foreign keys are off by default in SQLite; without them deleting a
+	// project would leave its technologies, files and analyses behind.
+	conn, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	if err := conn.Ping(); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	conn.SetMaxOpenConns(1)
 
-	db := &DB{conn: conn}
+	return &DB{conn: conn, migrations: migrations}, nil
+}
 
-	if err := db.initSchema(); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+// WithTx runs fn inside a transaction, committing when fn succeeds and
+// rolling back otherwise.
+func (db *DB) WithTx(fn func(tx *sql.Tx) error) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	return db, nil
-}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
 
-func (db *DB) initSchema() error {
-	_, err := db.conn.Exec(schemaSQL)
-	return err
+	return tx.Commit()
 }
 
 func (db *DB) Close() error {
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the up-migrations, named like 0002_add_search.sql.
+// Versions start at 1 and have no gaps; a migration is never edited once
+// released, changes go into a new one.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is returned when a database was migrated by a newer
+// version of pmem than this one.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of pmem")
+
+// Migration is a numbered change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus tells whether a migration was applied to a database, and
+// when. Migrations recorded in the database but unknown to this binary have
+// an empty SQL.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// loadMigrations reads the migrations of fsys sorted by version.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, p := range paths {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(path.Base(p), ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>.sql", p)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %d_%s: expected version %d", m.Version, m.Name, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// SchemaVersion returns the version of the last migration applied, or 0 for
+// a database without any.
+func (db *DB) SchemaVersion() (int, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return 0, err
+	}
+	var version int
+	err := db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// LatestVersion returns the schema version this binary migrates to.
+func (db *DB) LatestVersion() int {
+	return len(db.migrations)
+}
+
+// MigrationStatus lists the migrations known to this binary and those
+// recorded in the database, by version.
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var status []MigrationStatus
+	for _, m := range db.migrations {
+		s := MigrationStatus{Migration: m}
+		if a, ok := applied[m.Version]; ok {
+			s.AppliedAt = a.AppliedAt
+			delete(applied, m.Version)
+		}
+		status = append(status, s)
+	}
+	for _, a := range applied {
+		status = append(status, a)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Version < status[j].Version
+	})
+	return status, nil
+}
+
+// Migrate applies the pending migrations in order, each in its own
+// transaction together with its record in schema_migrations, and returns
+// those applied. It stops at the first one that fails, leaving the
+// database at the previous version.
+func (db *DB) Migrate() ([]Migration, error) {
+	current, err := db.checkVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range db.migrations[current:] {
+		err := db.WithTx(func(tx *sql.Tx) error {
+			if m.Version == 1 {
+				if err := adoptLegacySchema(tx); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.Exec(m.SQL); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+				m.Version, m.Name, time.Now().Unix())
+			return err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// checkVersion returns the schema version of the database, refusing one
+// newer than this binary.
+func (db *DB) checkVersion() (int, error) {
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return 0, err
+	}
+	if current > db.LatestVersion() {
+		return 0, fmt.Errorf("%w: version %d, this binary supports up to %d; upgrade pmem", ErrSchemaTooNew, current, db.LatestVersion())
+	}
+	return current, nil
+}
+
+func (db *DB) ensureMigrationsTable() error {
+	_, err := db.conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`)
+	return err
+}
+
+func (db *DB) appliedMigrations() (map[int]MigrationStatus, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(`SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]MigrationStatus)
+	for rows.Next() {
+		var s MigrationStatus
+		var appliedAt int64
+		if err := rows.Scan(&s.Version, &s.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+		t := time.Unix(appliedAt, 0)
+		s.AppliedAt = &t
+		applied[s.Version] = s
+	}
+	return applied, rows.Err()
+}
+
+// legacyColumns lists the columns added to tables before migrations
+// existed, when the schema was created with CREATE TABLE IF NOT EXISTS on
+// every open and these were added when missing.
+var legacyColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"project_files", "content_hash", "TEXT"},
+	{"projects", "missing_since", "INTEGER"},
+	{"projects", "root_commit", "TEXT"},
+	{"projects", "parent_id", "TEXT REFERENCES projects(id) ON DELETE SET NULL"},
+	{"technologies", "declared_version", "TEXT"},
+	{"technologies", "scope", "TEXT"},
+	{"technologies", "transitive", "INTEGER NOT NULL DEFAULT 0"},
+	{"technologies", "source", "TEXT"},
+	{"technologies", "features", "TEXT"},
+	{"todos", "kind", "TEXT"},
+	{"todos", "completed_at", "INTEGER"},
+	{"todos", "source", "TEXT NOT NULL DEFAULT 'code'"},
+}
+
+// adoptLegacySchema brings a database created before migrations existed to
+// the schema of the first migration, whose tables it may already have with
+// fewer columns. It does nothing to a new database.
+func adoptLegacySchema(tx *sql.Tx) error {
+	for _, c := range legacyColumns {
+		tableExists, exists, err := columnExists(tx, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if !tableExists || exists {
+			continue
+		}
+
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", c.table, c.column, err)
+		}
+	}
+
+	// The schema version replaces the version once kept in config.
+	_, err := tx.Exec(`DELETE FROM config WHERE key = 'version'`)
+	if err != nil && !strings.Contains(err.Error(), "no such table") {
+		return err
+	}
+	return nil
+}
+
+// columnExists reports whether a table exists and whether it has a column.
+func columnExists(tx *sql.Tx, table, column string) (tableExists, exists bool, err error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tableExists = true
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, false, err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	return tableExists, exists, rows.Err()
+}
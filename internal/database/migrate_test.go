@@ -0,0 +1,197 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func testMigrations(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, sql := range files {
+		fsys["migrations/"+name] = &fstest.MapFile{Data: []byte(sql)}
+	}
+	return fsys
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.db")
+
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion() failed: %v", err)
+	}
+	if version != db.LatestVersion() || version == 0 {
+		t.Errorf("SchemaVersion() = %d, want %d", version, db.LatestVersion())
+	}
+
+	applied, err := db.Migrate()
+	if err != nil || len(applied) != 0 {
+		t.Errorf("second Migrate() = %v, %v; want nothing to apply", applied, err)
+	}
+
+	status, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus() failed: %v", err)
+	}
+	for _, m := range status {
+		if m.AppliedAt == nil {
+			t.Errorf("migration %d_%s is pending", m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.db")
+
+	// A database as created before migrations existed, before missing_since
+	// and the later columns were added.
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.Exec(`
+		CREATE TABLE projects (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			path TEXT UNIQUE NOT NULL,
+			description TEXT,
+			status TEXT DEFAULT 'active',
+			progress INTEGER DEFAULT 0,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			last_scanned_at INTEGER,
+			is_git_repo BOOLEAN DEFAULT 0,
+			git_remote TEXT,
+			git_branch TEXT,
+			notes TEXT
+		);
+		CREATE TABLE config (key TEXT PRIMARY KEY, value TEXT NOT NULL, updated_at INTEGER NOT NULL);
+		INSERT INTO config (key, value, updated_at) VALUES ('version', '1.0.0', 0);
+		INSERT INTO projects (id, name, path, created_at, updated_at) VALUES ('p1', 'app', '/src/app', 0, 0);
+	`)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer db.Close()
+
+	var name string
+	var missingSince, parentID sql.NullString
+	err = db.Conn().QueryRow(`SELECT name, missing_since, parent_id FROM projects WHERE id = 'p1'`).Scan(&name, &missingSince, &parentID)
+	if err != nil || name != "app" {
+		t.Fatalf("project after migrating = %q, %v; want it kept with the new columns", name, err)
+	}
+
+	var versions int
+	if err := db.Conn().QueryRow(`SELECT COUNT(*) FROM config WHERE key = 'version'`).Scan(&versions); err != nil || versions != 0 {
+		t.Errorf("config version rows = %d, %v; want it removed", versions, err)
+	}
+}
+
+func TestMigrate_FailedMigrationRollsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.db")
+	fsys := testMigrations(map[string]string{
+		"0001_first.sql":  `CREATE TABLE a (id INTEGER);`,
+		"0002_broken.sql": `CREATE TABLE b (id INTEGER); INSERT INTO missing VALUES (1);`,
+	})
+
+	db, err := open(path, fsys)
+	if err != nil {
+		t.Fatalf("open() failed: %v", err)
+	}
+	defer db.Close()
+
+	applied, err := db.Migrate()
+	if err == nil {
+		t.Fatal("Migrate() succeeded with a broken migration")
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Errorf("Migrate() applied %+v, want only the first migration", applied)
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil || version != 1 {
+		t.Errorf("SchemaVersion() = %d, %v; want 1", version, err)
+	}
+	var tables int
+	db.Conn().QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'b'`).Scan(&tables)
+	if tables != 0 {
+		t.Error("table of the failed migration was left behind")
+	}
+}
+
+func TestOpen_RefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.db")
+	newer := testMigrations(map[string]string{
+		"0001_first.sql":  `CREATE TABLE a (id INTEGER);`,
+		"0002_second.sql": `CREATE TABLE b (id INTEGER);`,
+	})
+	older := testMigrations(map[string]string{
+		"0001_first.sql": `CREATE TABLE a (id INTEGER);`,
+	})
+
+	db, err := open(path, newer)
+	if err != nil {
+		t.Fatalf("open() failed: %v", err)
+	}
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate() failed: %v", err)
+	}
+	db.Close()
+
+	if _, err := open(path, older); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("open() with older migrations = %v, want ErrSchemaTooNew", err)
+	}
+
+	// The version can still be reported.
+	inspected, err := connect(path, older)
+	if err != nil {
+		t.Fatalf("connect() with older migrations failed: %v", err)
+	}
+	defer inspected.Close()
+	if version, err := inspected.SchemaVersion(); err != nil || version != 2 {
+		t.Errorf("SchemaVersion() = %d, %v, want 2", version, err)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr bool
+	}{
+		{"ordered", map[string]string{"0002_b.sql": "", "0001_a.sql": ""}, false},
+		{"gap", map[string]string{"0001_a.sql": "", "0003_c.sql": ""}, true},
+		{"duplicate version", map[string]string{"0001_a.sql": "", "0001_b.sql": ""}, true},
+		{"no version", map[string]string{"initial.sql": ""}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(testMigrations(tt.files))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (migrations[0].Name != "a" || migrations[1].Version != 2) {
+				t.Errorf("loadMigrations() = %+v, want a then b", migrations)
+			}
+		})
+	}
+
+	if _, err := loadMigrations(migrationFiles); err != nil {
+		t.Errorf("embedded migrations: %v", err)
+	}
+}
@@ -1,4 +1,7 @@
 -- Schema para Project Memory Bank
+--
+-- Tables are created only when missing so that databases created before
+-- migrations existed can adopt this schema; see adoptLegacySchema.
 
 CREATE TABLE IF NOT EXISTS projects (
     id TEXT PRIMARY KEY,
@@ -13,7 +16,10 @@ CREATE TABLE IF NOT EXISTS projects (
     is_git_repo BOOLEAN DEFAULT 0,
     git_remote TEXT,
     git_branch TEXT,
-    notes TEXT
+    notes TEXT,
+    root_commit TEXT,
+    missing_since INTEGER,
+    parent_id TEXT REFERENCES projects(id) ON DELETE SET NULL
 );
 
 CREATE INDEX IF NOT EXISTS idx_projects_status ON projects(status);
@@ -26,6 +32,11 @@ CREATE TABLE IF NOT EXISTS technologies (
     type TEXT NOT NULL,
     name TEXT NOT NULL,
     version TEXT,
+    declared_version TEXT,
+    scope TEXT,
+    transitive INTEGER NOT NULL DEFAULT 0,
+    source TEXT,
+    features TEXT,
     detected_from TEXT,
     FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
     UNIQUE(project_id, type, name)
@@ -41,6 +52,7 @@ CREATE TABLE IF NOT EXISTS project_files (
     file_type TEXT NOT NULL,
     size_bytes INTEGER,
     last_modified INTEGER NOT NULL,
+    content_hash TEXT,
     FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
     UNIQUE(project_id, file_path)
 );
@@ -48,15 +60,32 @@ CREATE TABLE IF NOT EXISTS project_files (
 CREATE INDEX IF NOT EXISTS idx_project_files_project ON project_files(project_id);
 CREATE INDEX IF NOT EXISTS idx_project_files_modified ON project_files(last_modified DESC);
 
+CREATE TABLE IF NOT EXISTS language_stats (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    language TEXT NOT NULL,
+    files INTEGER NOT NULL DEFAULT 0,
+    code_lines INTEGER NOT NULL DEFAULT 0,
+    comment_lines INTEGER NOT NULL DEFAULT 0,
+    blank_lines INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    UNIQUE(project_id, language)
+);
+
+CREATE INDEX IF NOT EXISTS idx_language_stats_project ON language_stats(project_id);
+
 CREATE TABLE IF NOT EXISTS todos (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     project_id TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'code',
     content TEXT NOT NULL,
     source_file TEXT,
     line_number INTEGER,
+    kind TEXT,
     priority TEXT DEFAULT 'medium' CHECK(priority IN ('low', 'medium', 'high')),
     completed BOOLEAN DEFAULT 0,
     created_at INTEGER NOT NULL,
+    completed_at INTEGER,
     FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
 );
 
@@ -94,6 +123,6 @@ CREATE TABLE IF NOT EXISTS config (
     value TEXT NOT NULL
 );
 
-INSERT OR IGNORE INTO config (key, value) VALUES ('version', '1.0.0');
 INSERT OR IGNORE INTO config (key, value) VALUES ('scan_directories', '[]');
 INSERT OR IGNORE INTO config (key, value) VALUES ('groq_api_key', '');
+INSERT OR IGNORE INTO config (key, value) VALUES ('ignore_patterns', '[]');
@@ -0,0 +1,8 @@
+-- README contents of each project, kept so they can be searched.
+
+CREATE TABLE project_readmes (
+    project_id TEXT PRIMARY KEY,
+    content TEXT NOT NULL,
+    updated_at INTEGER NOT NULL,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
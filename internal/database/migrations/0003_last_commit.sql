@@ -0,0 +1,4 @@
+-- When the commit checked out in each project was made, so projects can be
+-- sorted by their last commit.
+
+ALTER TABLE projects ADD COLUMN last_commit_at INTEGER;
@@ -0,0 +1,20 @@
+-- The activity log outlives the projects it describes: entries used to be
+-- deleted with their project, taking the record of its removal with them.
+-- SQLite cannot drop a foreign key, so the table is rebuilt without it.
+
+CREATE TABLE activity_log_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    details TEXT,
+    timestamp INTEGER NOT NULL
+);
+
+INSERT INTO activity_log_new (id, project_id, action, details, timestamp)
+SELECT id, project_id, action, details, timestamp FROM activity_log;
+
+DROP TABLE activity_log;
+ALTER TABLE activity_log_new RENAME TO activity_log;
+
+CREATE INDEX idx_activity_log_project ON activity_log(project_id);
+CREATE INDEX idx_activity_log_timestamp ON activity_log(timestamp DESC);
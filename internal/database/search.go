@@ -0,0 +1,168 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// The search index is an FTS5 table with one row per project, holding its
+// metadata, direct technologies, README, open TODOs and AI analyses.
+// Triggers on projects rebuild the row of a project whenever the project
+// changes. Writes to the other tables come in batches, such as the
+// technologies of a scan, so rather than rebuilding the row once per
+// written row, writers call ReindexProject once they are done.
+//
+// FTS5 is only compiled into SQLite with the sqlite_fts5 build tag, so the
+// index is not part of the migrations: it is created when a binary with
+// FTS5 opens the database, and its triggers are dropped when one without
+// it does, since they would make every write to these tables fail.
+
+const searchIndexSQL = `
+CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+    project_id UNINDEXED,
+    name,
+    path,
+    description,
+    notes,
+    status,
+    tech,
+    readme,
+    todo,
+    analysis,
+    tokenize = 'unicode61 remove_diacritics 2'
+)`
+
+// searchRowSQL selects the search_index row of the projects matching the
+// WHERE clause appended to it.
+const searchRowSQL = `
+INSERT INTO search_index (project_id, name, path, description, notes, status, tech, readme, todo, analysis)
+SELECT p.id, p.name, p.path, COALESCE(p.description, ''), COALESCE(p.notes, ''), COALESCE(p.status, ''),
+    COALESCE((SELECT group_concat(name, ' ') FROM technologies WHERE project_id = p.id AND transitive = 0), ''),
+    COALESCE((SELECT content FROM project_readmes WHERE project_id = p.id), ''),
+    COALESCE((SELECT group_concat(content, ' ') FROM todos WHERE project_id = p.id AND completed = 0), ''),
+    COALESCE((SELECT group_concat(result, ' ') FROM ai_analyses WHERE project_id = p.id), '')
+FROM projects p`
+
+// searchTriggers returns the triggers that keep search_index in sync, by
+// name.
+func searchTriggers() map[string]string {
+	refresh := func(id string) string {
+		return fmt.Sprintf("DELETE FROM search_index WHERE project_id = %s;\n%s WHERE p.id = %s;", id, searchRowSQL, id)
+	}
+
+	return map[string]string{
+		"search_projects_insert": "AFTER INSERT ON projects BEGIN " + refresh("NEW.id") + " END",
+		"search_projects_update": "AFTER UPDATE ON projects BEGIN DELETE FROM search_index WHERE project_id = OLD.id; " + refresh("NEW.id") + " END",
+		"search_projects_delete": "AFTER DELETE ON projects BEGIN DELETE FROM search_index WHERE project_id = OLD.id; END",
+	}
+}
+
+// Querier is satisfied by both *sql.DB and *sql.Tx.
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// ReindexProject rebuilds the search_index row of a project from its
+// technologies, README, TODOs and analyses. It does nothing when the index
+// is not kept up to date, as when SQLite lacks FTS5.
+func ReindexProject(q Querier, projectID string) error {
+	var maintained bool
+	err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'trigger' AND name = 'search_projects_update')`).Scan(&maintained)
+	if err != nil || !maintained {
+		return err
+	}
+	if _, err := q.Exec(`DELETE FROM search_index WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+	_, err = q.Exec(searchRowSQL+` WHERE p.id = ?`, projectID)
+	return err
+}
+
+// fts5Available reports whether SQLite was built with FTS5.
+func fts5Available(conn *sql.DB) bool {
+	var used bool
+	err := conn.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&used)
+	return err == nil && used
+}
+
+// setupSearchIndex creates search_index and its triggers when FTS5 is
+// available, filling the index when its triggers were missing or differ
+// from those of this binary, and drops the triggers otherwise.
+func (db *DB) setupSearchIndex() error {
+	triggers := searchTriggers()
+
+	existing, err := db.searchTriggerNames()
+	if err != nil {
+		return err
+	}
+
+	if !fts5Available(db.conn) {
+		if len(existing) == 0 {
+			return nil
+		}
+		return db.WithTx(func(tx *sql.Tx) error {
+			return dropTriggers(tx, existing)
+		})
+	}
+
+	current := len(existing) == len(triggers)
+	for _, name := range existing {
+		_, known := triggers[name]
+		current = current && known
+	}
+	if current {
+		return nil
+	}
+
+	// The index was never built, went stale without its triggers or was
+	// kept by the triggers of another version.
+	return db.WithTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(searchIndexSQL); err != nil {
+			return err
+		}
+		if err := dropTriggers(tx, existing); err != nil {
+			return err
+		}
+		for name, body := range triggers {
+			if _, err := tx.Exec(fmt.Sprintf("CREATE TRIGGER %s %s", name, body)); err != nil {
+				return fmt.Errorf("failed to create trigger %s: %w", name, err)
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM search_index`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(strings.TrimSpace(searchRowSQL))
+		return err
+	})
+}
+
+// searchTriggerNames returns the names of the search index triggers in the
+// database.
+func (db *DB) searchTriggerNames() ([]string, error) {
+	rows, err := db.conn.Query(`SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'search\_%' ESCAPE '\'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func dropTriggers(tx *sql.Tx, names []string) error {
+	for _, name := range names {
+		if _, err := tx.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
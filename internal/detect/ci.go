@@ -0,0 +1,62 @@
+package detect
+
+import (
+	"path"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// ciSystems maps the configuration files of CI systems to their names.
+var ciSystems = []struct {
+	pattern string
+	name    string
+}{
+	{".github/workflows/*.yml", "GitHub Actions"},
+	{".github/workflows/*.yaml", "GitHub Actions"},
+	{".gitlab-ci.yml", "GitLab CI"},
+	{".circleci/config.yml", "CircleCI"},
+	{"Jenkinsfile", "Jenkins"},
+	{"azure-pipelines.yml", "Azure Pipelines"},
+	{".travis.yml", "Travis CI"},
+	{"bitbucket-pipelines.yml", "Bitbucket Pipelines"},
+	{".drone.yml", "Drone"},
+	{".woodpecker.yml", "Woodpecker"},
+	{".woodpecker/*.yml", "Woodpecker"},
+	{".buildkite/pipeline.yml", "Buildkite"},
+	{"appveyor.yml", "AppVeyor"},
+	{".teamcity/settings.kts", "TeamCity"},
+}
+
+type ciDetector struct{}
+
+func init() {
+	Register(ciDetector{})
+}
+
+func (ciDetector) Name() string { return "ci" }
+
+func (ciDetector) IndicatesProject() bool { return false }
+
+func (ciDetector) Files() []string {
+	patterns := make([]string, len(ciSystems))
+	for i, system := range ciSystems {
+		patterns[i] = system.pattern
+	}
+	return patterns
+}
+
+// Detect records each CI system configured, from its first file.
+func (ciDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	var techs []models.Technology
+	seen := make(map[string]bool)
+	for _, file := range files {
+		for _, system := range ciSystems {
+			if ok, _ := path.Match(system.pattern, file); !ok || seen[system.name] {
+				continue
+			}
+			seen[system.name] = true
+			techs = append(techs, models.Technology{Type: "ci", Name: system.name, DetectedFrom: file})
+		}
+	}
+	return techs, nil
+}
@@ -0,0 +1,320 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// cmakeLanguages maps the languages of project() to their names.
+var cmakeLanguages = map[string]string{
+	"C":       "C",
+	"CXX":     "C++",
+	"CUDA":    "CUDA",
+	"OBJC":    "Objective-C",
+	"OBJCXX":  "Objective-C++",
+	"Fortran": "Fortran",
+	"Swift":   "Swift",
+	"HIP":     "HIP",
+	"ASM":     "Assembly",
+}
+
+// cmakeFrameworks lists packages recorded as frameworks; test frameworks
+// are dev dependencies.
+var cmakeFrameworks = map[string]models.DependencyScope{
+	"Qt5":       models.ScopeProd,
+	"Qt6":       models.ScopeProd,
+	"wxWidgets": models.ScopeProd,
+	"GTest":     models.ScopeDev,
+	"Catch2":    models.ScopeDev,
+	"doctest":   models.ScopeDev,
+}
+
+// cmakePackageKeywords end the component list of find_package.
+var cmakePackageKeywords = map[string]bool{
+	"REQUIRED": true, "QUIET": true, "CONFIG": true, "MODULE": true, "NO_MODULE": true,
+	"COMPONENTS": true, "OPTIONAL_COMPONENTS": true, "GLOBAL": true,
+}
+
+var cmakeCommand = regexp.MustCompile(`(?i)\b(cmake_minimum_required|project|find_package|fetchcontent_declare|cpmaddpackage|set)\s*\(`)
+
+type cmakeDetector struct{}
+
+func init() {
+	Register(cmakeDetector{})
+}
+
+func (cmakeDetector) Name() string { return "cmake" }
+
+func (cmakeDetector) Files() []string { return []string{"CMakeLists.txt"} }
+
+func (cmakeDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "CMakeLists.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	var techs []models.Technology
+	seen := make(map[string]bool)
+	add := func(tech models.Technology) {
+		tech.DetectedFrom = "CMakeLists.txt"
+		if key := tech.Type + "/" + tech.Name; !seen[key] {
+			seen[key] = true
+			techs = append(techs, tech)
+		}
+	}
+
+	standards := make(map[string]string)
+	var languages []string
+	for _, cmd := range cmakeCommands(string(data)) {
+		args := cmd.args
+		switch cmd.name {
+		case "cmake_minimum_required":
+			if i := indexOf(args, "VERSION"); i >= 0 && i+1 < len(args) {
+				// "3.16" or a policy range such as "3.16...3.28".
+				minimum, _, _ := strings.Cut(args[i+1], "...")
+				add(models.Technology{Type: "build", Name: "CMake", Version: minimum, DeclaredVersion: ">=" + minimum})
+			}
+		case "project":
+			languages = cmakeProjectLanguages(args)
+		case "set":
+			if len(args) > 1 && strings.HasPrefix(args[0], "CMAKE_") && strings.HasSuffix(args[0], "_STANDARD") {
+				standards[strings.TrimSuffix(strings.TrimPrefix(args[0], "CMAKE_"), "_STANDARD")] = args[1]
+			}
+		case "find_package":
+			if len(args) > 0 {
+				add(cmakePackage(args))
+			}
+		case "fetchcontent_declare", "cpmaddpackage":
+			if dep, ok := cmakeFetchedPackage(cmd.name, args); ok {
+				add(dep)
+			}
+		}
+	}
+
+	// Languages come first; project() usually follows the commands that
+	// set their standards, so they are added once all are read.
+	runtimes := make([]models.Technology, 0, len(languages))
+	for _, lang := range languages {
+		runtimes = append(runtimes, models.Technology{
+			Type:         "runtime",
+			Name:         cmakeLanguages[lang],
+			Version:      standards[lang],
+			DetectedFrom: "CMakeLists.txt",
+		})
+	}
+	return append(runtimes, techs...), nil
+}
+
+type cmakeCall struct {
+	name string
+	args []string
+}
+
+// cmakeCommands returns the calls of the commands the detector reads, with
+// their arguments unquoted. Comments are dropped and calls may span lines.
+func cmakeCommands(content string) []cmakeCall {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		lines = append(lines, stripCMakeComment(line))
+	}
+	content = strings.Join(lines, "\n")
+
+	var calls []cmakeCall
+	for _, loc := range cmakeCommand.FindAllStringSubmatchIndex(content, -1) {
+		end := strings.IndexByte(content[loc[1]:], ')')
+		if end < 0 {
+			continue
+		}
+		calls = append(calls, cmakeCall{
+			name: strings.ToLower(content[loc[2]:loc[3]]),
+			args: cmakeArgs(content[loc[1] : loc[1]+end]),
+		})
+	}
+	return calls
+}
+
+// cmakeArgs splits arguments on whitespace, keeping quoted ones whole.
+func cmakeArgs(s string) []string {
+	var args []string
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			break
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				end = len(s) - 1
+			}
+			args = append(args, s[1:end+1])
+			s = s[min(end+2, len(s)):]
+			continue
+		}
+		end := strings.IndexAny(s, " \t\r\n")
+		if end < 0 {
+			end = len(s)
+		}
+		args = append(args, s[:end])
+		s = s[end:]
+	}
+	return args
+}
+
+func stripCMakeComment(line string) string {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case '#':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// cmakeProjectLanguages returns the languages project() enables: those
+// after LANGUAGES, or listed after the name in the short form, or C and
+// C++ by default.
+func cmakeProjectLanguages(args []string) []string {
+	var languages []string
+	collecting := indexOf(args, "LANGUAGES") < 0
+	for i, arg := range args {
+		if i == 0 {
+			continue
+		}
+		switch {
+		case arg == "LANGUAGES":
+			collecting = true
+		case cmakeLanguages[arg] != "":
+			if collecting {
+				languages = append(languages, arg)
+			}
+		default:
+			if indexOf(args, "LANGUAGES") >= 0 {
+				collecting = false
+			}
+		}
+	}
+	if len(languages) == 0 && indexOf(args, "NONE") < 0 {
+		languages = []string{"C", "CXX"}
+	}
+	return languages
+}
+
+// cmakePackage reads find_package(Name [version] [EXACT] [REQUIRED]
+// [COMPONENTS ...]); components are recorded as features.
+func cmakePackage(args []string) models.Technology {
+	tech := models.Technology{Type: "dependency", Name: args[0], Scope: models.ScopeProd}
+	if scope, ok := cmakeFrameworks[args[0]]; ok {
+		tech.Type = "framework"
+		tech.Scope = scope
+	}
+	if len(args) > 1 && exactVersion.MatchString(args[1]) {
+		if indexOf(args, "EXACT") >= 0 {
+			tech.Version = args[1]
+			tech.DeclaredVersion = "=" + args[1]
+		} else {
+			tech.DeclaredVersion = ">=" + args[1]
+		}
+	}
+	for _, keyword := range []string{"COMPONENTS", "OPTIONAL_COMPONENTS"} {
+		i := indexOf(args, keyword)
+		if i < 0 {
+			continue
+		}
+		for _, c := range args[i+1:] {
+			if cmakePackageKeywords[c] {
+				break
+			}
+			tech.Features = append(tech.Features, c)
+		}
+	}
+	return tech
+}
+
+// cmakeFetchedPackage reads FetchContent_Declare(name GIT_REPOSITORY url
+// GIT_TAG tag) and CPMAddPackage(NAME name GITHUB_REPOSITORY org/repo
+// VERSION version), or the short form CPMAddPackage("gh:org/repo@1.0").
+func cmakeFetchedPackage(command string, args []string) (models.Technology, bool) {
+	tech := models.Technology{Type: "dependency", Scope: models.ScopeProd}
+	option := func(key string) string {
+		if i := indexOf(args, key); i >= 0 && i+1 < len(args) {
+			return args[i+1]
+		}
+		return ""
+	}
+
+	var repo, tag string
+	switch {
+	case command == "fetchcontent_declare" && len(args) > 0:
+		tech.Name = args[0]
+		repo, tag = option("GIT_REPOSITORY"), option("GIT_TAG")
+		if repo == "" {
+			tech.Source = option("URL")
+		}
+	case len(args) == 1:
+		// "gh:org/repo@1.0.0" or "https://host/repo.git@1.0.0".
+		spec, version := args[0], ""
+		if i := strings.LastIndex(spec, "@"); i > 0 {
+			spec, version = spec[:i], spec[i+1:]
+		}
+		if strings.HasPrefix(spec, "gh:") {
+			spec = "https://github.com/" + strings.TrimPrefix(spec, "gh:")
+		}
+		repo, tag = spec, version
+		tech.Name = cmakeRepositoryName(spec)
+	default:
+		tech.Name = option("NAME")
+		repo, tag = option("GIT_REPOSITORY"), option("GIT_TAG")
+		if gh := option("GITHUB_REPOSITORY"); gh != "" {
+			repo = "https://github.com/" + gh
+			if tech.Name == "" {
+				tech.Name = cmakeRepositoryName(gh)
+			}
+		}
+		if v := option("VERSION"); v != "" {
+			tech.Version = v
+			tech.DeclaredVersion = v
+		}
+	}
+	if tech.Name == "" {
+		return tech, false
+	}
+	if scope, ok := cmakeFrameworks[tech.Name]; ok {
+		tech.Type = "framework"
+		tech.Scope = scope
+	}
+
+	if repo != "" {
+		tech.Source = "git+" + repo
+		if tag != "" {
+			tech.Source += "?tag=" + tag
+			if v := strings.TrimPrefix(tag, "v"); exactVersion.MatchString(v) && tech.Version == "" {
+				tech.Version = v
+			}
+		}
+	}
+	return tech, true
+}
+
+// cmakeRepositoryName names a package after its repository, as CPM does.
+func cmakeRepositoryName(repo string) string {
+	return path.Base(strings.TrimSuffix(strings.TrimRight(repo, "/"), ".git"))
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,108 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/yaml"
+)
+
+type composeDetector struct{}
+
+func init() {
+	Register(composeDetector{})
+}
+
+func (composeDetector) Name() string { return "compose" }
+
+func (composeDetector) IndicatesProject() bool { return false }
+
+func (composeDetector) Files() []string {
+	return []string{
+		"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml",
+		"compose.*.yaml", "compose.*.yml", "docker-compose.*.yaml", "docker-compose.*.yml",
+	}
+}
+
+func (composeDetector) Includes(projectPath string, files []string) []string {
+	return []string{".env"}
+}
+
+// Detect records Docker Compose as a deploy tool and each service it
+// defines. Services run from an image also record the image as a
+// container; those built from a Dockerfile name its context as their source.
+func (composeDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	vars := readDotEnv(filepath.Join(projectPath, ".env"))
+
+	var services []models.Technology
+	var images imageSet
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(projectPath, file))
+		if err != nil {
+			return nil, err
+		}
+		doc, err := yaml.Parse(data)
+		if err != nil {
+			continue
+		}
+
+		defined := yaml.Map(doc, "services")
+		for _, name := range sortedKeys(defined) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			svc := yaml.Map(defined, name)
+			service := models.Technology{Type: "service", Name: name, DetectedFrom: file}
+			image := expandVariables(yaml.String(svc, "image"), vars)
+			switch build := svc["build"].(type) {
+			case string:
+				service.Source = "path+" + path.Clean(build)
+			case map[string]interface{}:
+				service.Source = "path+" + path.Clean(yaml.String(build, "context"))
+			default:
+				service.Source = image
+				if image != "" {
+					images.add(image, models.ScopeProd, file)
+				}
+			}
+			services = append(services, service)
+		}
+	}
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	techs := []models.Technology{{Type: "deploy", Name: "Docker Compose", DetectedFrom: services[0].DetectedFrom}}
+	techs = append(techs, services...)
+	return append(techs, images.techs...), nil
+}
+
+// readDotEnv reads the variables of the .env file Compose substitutes in
+// the files it loads.
+func readDotEnv(path string) map[string]string {
+	vars := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return vars
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(sc.Text()), "export "))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, "="); ok {
+			vars[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return vars
+}
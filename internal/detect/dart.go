@@ -0,0 +1,168 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/yaml"
+)
+
+var dartFrameworks = map[string]bool{
+	"flutter_bloc":     true,
+	"flutter_riverpod": true,
+	"shelf":            true,
+	"dart_frog":        true,
+	"serverpod":        true,
+}
+
+type dartDetector struct{}
+
+func init() {
+	Register(dartDetector{})
+}
+
+func (dartDetector) Name() string { return "dart" }
+
+func (dartDetector) Files() []string { return []string{"pubspec.yaml", "pubspec.lock"} }
+
+func (dartDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "pubspec.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pubspec, err := yaml.Parse(data)
+	if err != nil {
+		return nil, nil
+	}
+
+	env := yaml.Map(pubspec, "environment")
+	sdk := yaml.String(env, "sdk")
+	techs := []models.Technology{{
+		Type:            "runtime",
+		Name:            "Dart",
+		Version:         lowerBound(sdk),
+		DeclaredVersion: sdk,
+		DetectedFrom:    "pubspec.yaml",
+	}}
+
+	locked := readPubspecLock(filepath.Join(projectPath, "pubspec.lock"))
+	direct := make(map[string]bool)
+	for _, section := range []struct {
+		key   string
+		scope models.DependencyScope
+	}{
+		{"dependencies", models.ScopeProd},
+		{"dev_dependencies", models.ScopeDev},
+	} {
+		deps := yaml.Map(pubspec, section.key)
+		for _, name := range sortedKeys(deps) {
+			if direct[name] {
+				continue
+			}
+			direct[name] = true
+
+			dep := parsePubDependency(name, deps[name])
+			if dep.source == "sdk+flutter" {
+				// Packages of the Flutter SDK, such as flutter_test, come with it.
+				if name == "flutter" {
+					flutter := yaml.String(env, "flutter")
+					techs = append(techs, models.Technology{
+						Type:            "framework",
+						Name:            "Flutter",
+						Version:         lowerBound(flutter),
+						DeclaredVersion: flutter,
+						DetectedFrom:    "pubspec.yaml",
+					})
+				}
+				continue
+			}
+
+			techType := "dependency"
+			if dartFrameworks[name] {
+				techType = "framework"
+			}
+			version := pinnedVersion(dep.constraint)
+			if v := yaml.String(yaml.Map(locked, name), "version"); v != "" {
+				version = v
+			}
+			techs = append(techs, models.Technology{
+				Type:            techType,
+				Name:            name,
+				Version:         version,
+				DeclaredVersion: dep.constraint,
+				Scope:           section.scope,
+				Source:          dep.source,
+				DetectedFrom:    "pubspec.yaml",
+			})
+		}
+	}
+
+	// pubspec.lock marks transitive packages but does not record which
+	// package needs them, so their scope is unknown.
+	for _, name := range sortedKeys(locked) {
+		pkg := yaml.Map(locked, name)
+		if direct[name] || yaml.String(pkg, "dependency") != "transitive" {
+			continue
+		}
+		techs = append(techs, models.Technology{
+			Type:         "dependency",
+			Name:         name,
+			Version:      yaml.String(pkg, "version"),
+			Transitive:   true,
+			DetectedFrom: "pubspec.lock",
+		})
+	}
+	return techs, nil
+}
+
+// parsePubDependency reads a dependency written as a version constraint or
+// as a map naming its source: sdk, git, path or a hosted repository.
+func parsePubDependency(name string, v interface{}) declaredDependency {
+	dep := declaredDependency{name: name}
+	switch spec := v.(type) {
+	case string:
+		dep.constraint = spec
+	case map[string]interface{}:
+		dep.constraint = yaml.String(spec, "version")
+		switch {
+		case yaml.String(spec, "sdk") != "":
+			dep.source = "sdk+" + yaml.String(spec, "sdk")
+		case spec["git"] != nil:
+			git := yaml.Map(spec, "git")
+			url := yaml.String(spec, "git")
+			if git != nil {
+				url = yaml.String(git, "url")
+			}
+			dep.source = "git+" + url
+			if ref := yaml.String(git, "ref"); ref != "" {
+				dep.source += "?ref=" + ref
+			}
+		case yaml.String(spec, "path") != "":
+			dep.source = "path+" + yaml.String(spec, "path")
+		case spec["hosted"] != nil:
+			hosted := yaml.String(spec, "hosted")
+			if hosted == "" {
+				hosted = yaml.String(yaml.Map(spec, "hosted"), "url")
+			}
+			dep.source = "hosted+" + hosted
+		}
+	}
+	return dep
+}
+
+// readPubspecLock returns the packages of pubspec.lock, or nil.
+func readPubspecLock(path string) map[string]interface{} {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lock, err := yaml.Parse(data)
+	if err != nil {
+		return nil
+	}
+	return yaml.Map(lock, "packages")
+}
@@ -0,0 +1,149 @@
+// Package detect identifies the technologies used by a project from the
+// manifests found in its root directory.
+//
+// Each ecosystem is handled by a Detector registered with Register, usually
+// from an init function in its own file. The scanner and the agent context
+// generator both call Detect, so they always report the same technologies.
+package detect
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// Detector recognizes one ecosystem.
+type Detector interface {
+	// Name identifies the detector, e.g. "node".
+	Name() string
+
+	// Files lists the file names or glob patterns, relative to the project
+	// root, that the detector reads. It runs only when one of them exists.
+	Files() []string
+
+	// Detect returns the technologies described by the matched files, given
+	// relative to projectPath in the order of Files.
+	Detect(projectPath string, files []string) ([]models.Technology, error)
+}
+
+// Includer is implemented by detectors that read files besides the ones
+// Files matched, such as version files or files a manifest includes.
+type Includer interface {
+	// Includes returns those files for the matched files, relative to
+	// projectPath. They need not exist.
+	Includes(projectPath string, files []string) []string
+}
+
+// ProjectIndicator is implemented by detectors that report whether their
+// files mark a directory as a project. Deployment and CI configuration,
+// such as a docker-compose.yml next to the services it runs, also sits in
+// directories that only group projects, so those detectors return false.
+// Detectors that do not implement it indicate a project.
+type ProjectIndicator interface {
+	IndicatesProject() bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Detector
+)
+
+// Register adds a detector. Detectors run in registration order; files are
+// compiled in alphabetical order, so a detector in node.go runs before one
+// in python.go.
+func Register(d Detector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, d)
+}
+
+// Detectors returns the registered detectors.
+func Detectors() []Detector {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Detector(nil), registry...)
+}
+
+// Detect runs every detector whose files are present in projectPath and
+// returns the technologies they found. A detector that fails does not stop
+// the others; the first error is returned along with all the results.
+func Detect(projectPath string) ([]models.Technology, error) {
+	var techs []models.Technology
+	var firstErr error
+
+	for _, d := range Detectors() {
+		files := matchFiles(projectPath, d.Files())
+		if len(files) == 0 {
+			continue
+		}
+
+		found, err := d.Detect(projectPath, files)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		techs = append(techs, found...)
+	}
+
+	return techs, firstErr
+}
+
+// Matches reports whether any registered detector that indicates a project
+// recognizes a file in projectPath.
+func Matches(projectPath string) bool {
+	for _, d := range Detectors() {
+		if in, ok := d.(ProjectIndicator); ok && !in.IndicatesProject() {
+			continue
+		}
+		if len(matchFiles(projectPath, d.Files())) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ManifestFiles returns the files read by any detector, relative to
+// projectPath and sorted. Files an Includer reports are listed whether or
+// not they exist.
+func ManifestFiles(projectPath string) []string {
+	seen := make(map[string]bool)
+	for _, d := range Detectors() {
+		matched := matchFiles(projectPath, d.Files())
+		if len(matched) == 0 {
+			continue
+		}
+		for _, f := range matched {
+			seen[f] = true
+		}
+		if in, ok := d.(Includer); ok {
+			for _, f := range in.Includes(projectPath, matched) {
+				seen[f] = true
+			}
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for f := range seen {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func matchFiles(projectPath string, patterns []string) []string {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(projectPath, pattern))
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			if rel, err := filepath.Rel(projectPath, m); err == nil {
+				files = append(files, filepath.ToSlash(rel))
+			}
+		}
+	}
+	return files
+}
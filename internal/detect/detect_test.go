@@ -0,0 +1,1558 @@
+package detect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type fakeDetector struct{}
+
+func (fakeDetector) Name() string { return "fake" }
+
+func (fakeDetector) Files() []string { return []string{"*.fake"} }
+
+func (fakeDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	var techs []models.Technology
+	for _, f := range files {
+		techs = append(techs, models.Technology{Type: "runtime", Name: "Fake", DetectedFrom: f})
+	}
+	return techs, nil
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"dependencies": {"react": "^18.2.0", "lodash": "~4.17.21"}}`,
+		"go.mod":       "module example.com/app\n\ngo 1.22\n",
+	})
+
+	techs, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	found := make(map[string]models.Technology)
+	for _, tech := range techs {
+		found[tech.Name] = tech
+	}
+
+	expected := map[string]string{
+		"Node.js": "runtime",
+		"react":   "framework",
+		"lodash":  "dependency",
+		"Go":      "runtime",
+	}
+	for name, techType := range expected {
+		tech, ok := found[name]
+		if !ok {
+			t.Errorf("Detect() did not report %s", name)
+			continue
+		}
+		if tech.Type != techType {
+			t.Errorf("%s type = %s, want %s", name, tech.Type, techType)
+		}
+	}
+	if found["react"].Version != "18.2.0" {
+		t.Errorf("react version = %s, want 18.2.0", found["react"].Version)
+	}
+	if found["Go"].Version != "1.22" {
+		t.Errorf("Go version = %s, want 1.22", found["Go"].Version)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(fakeDetector{})
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"b.fake": "", "a.fake": ""})
+
+	if !Matches(root) {
+		t.Error("Matches() = false, want true for a registered glob")
+	}
+
+	techs, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+	if len(techs) != 2 || techs[0].DetectedFrom != "a.fake" || techs[1].DetectedFrom != "b.fake" {
+		t.Errorf("Detect() = %v, want Fake from a.fake and b.fake", techs)
+	}
+
+	manifests := ManifestFiles(root)
+	if len(manifests) != 2 || manifests[0] != "a.fake" {
+		t.Errorf("ManifestFiles() = %v, want [a.fake b.fake]", manifests)
+	}
+}
+
+func TestManifestFiles_Includes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"requirements.txt":      "-r requirements/base.txt\nflask\n",
+		"requirements/base.txt": "-r ../deps/common.txt\nrequests\n",
+		"deps/common.txt":       "six\n",
+		".python-version":       "3.12\n",
+	})
+
+	manifests := ManifestFiles(root)
+	for _, want := range []string{".python-version", "requirements.txt", "requirements/base.txt", "deps/common.txt"} {
+		found := false
+		for _, f := range manifests {
+			found = found || f == want
+		}
+		if !found {
+			t.Errorf("ManifestFiles() = %v, missing %s", manifests, want)
+		}
+	}
+}
+
+func TestDetect_JVM(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string // "type/name" -> version
+	}{
+		{
+			name: "maven spring boot",
+			files: map[string]string{
+				"pom.xml": `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.1</version>
+  </parent>
+  <groupId>com.example</groupId>
+  <artifactId>demo</artifactId>
+  <version>0.1.0</version>
+  <properties>
+    <java.version>17</java.version>
+    <junit.version>5.10.1</junit.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>${junit.version}</version>
+    </dependency>
+  </dependencies>
+</project>`,
+			},
+			expected: map[string]string{
+				"runtime/Java":             "17",
+				"build/Maven":              "",
+				"package/com.example:demo": "0.1.0",
+				"framework/Spring Boot":    "3.2.1",
+				"framework/JUnit":          "5.10.1",
+				"dependency/org.springframework.boot:spring-boot-starter-web": "3.2.1",
+			},
+		},
+		{
+			name: "gradle kotlin dsl with version catalog",
+			files: map[string]string{
+				"build.gradle.kts": `plugins {
+    kotlin("jvm") version "1.9.22"
+    alias(libs.plugins.spring.boot)
+}
+
+dependencies {
+    implementation(libs.bundles.web)
+    // implementation("com.example:commented:1.0")
+    testImplementation("org.junit.jupiter:junit-jupiter:5.10.1")
+}
+
+kotlin {
+    jvmToolchain(21)
+}
+`,
+				"gradle/libs.versions.toml": `[versions]
+boot = "3.2.1"
+jackson = "2.16.0"
+
+[libraries]
+boot-web = { module = "org.springframework.boot:spring-boot-starter-web", version.ref = "boot" }
+jackson-kotlin = { group = "com.fasterxml.jackson.module", name = "jackson-module-kotlin", version.ref = "jackson" }
+
+[bundles]
+web = ["boot-web", "jackson-kotlin"]
+
+[plugins]
+spring-boot = { id = "org.springframework.boot", version.ref = "boot" }
+`,
+				"gradle/wrapper/gradle-wrapper.properties": "distributionUrl=https\\://services.gradle.org/distributions/gradle-8.5-bin.zip\n",
+			},
+			expected: map[string]string{
+				"runtime/Java":          "21",
+				"build/Gradle":          "8.5",
+				"runtime/Kotlin":        "1.9.22",
+				"framework/Spring Boot": "3.2.1",
+				"framework/JUnit":       "5.10.1",
+				"dependency/com.fasterxml.jackson.module:jackson-module-kotlin": "2.16.0",
+			},
+		},
+		{
+			name: "gradle groovy android",
+			files: map[string]string{
+				"settings.gradle": "include ':app'\n",
+				"app/build.gradle": `apply plugin: 'com.android.application'
+
+android {
+    compileSdkVersion 34
+    compileOptions {
+        sourceCompatibility JavaVersion.VERSION_1_8
+    }
+}
+
+dependencies {
+    implementation 'androidx.appcompat:appcompat:1.6.1'
+    testImplementation group: 'junit', name: 'junit', version: '4.13.2'
+}
+`,
+			},
+			expected: map[string]string{
+				"runtime/Java":      "1.8",
+				"framework/Android": "34",
+				"framework/JUnit":   "4.13.2",
+				"dependency/androidx.appcompat:appcompat": "1.6.1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			techs, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect() failed: %v", err)
+			}
+
+			found := make(map[string]string)
+			for _, tech := range techs {
+				found[tech.Type+"/"+tech.Name] = tech.Version
+			}
+			for key, version := range tt.expected {
+				got, ok := found[key]
+				if !ok {
+					t.Errorf("Detect() did not report %s; got %v", key, found)
+					continue
+				}
+				if got != version {
+					t.Errorf("%s version = %q, want %q", key, got, version)
+				}
+			}
+			if _, ok := found["dependency/com.example:commented"]; ok {
+				t.Error("Detect() reported a commented-out dependency")
+			}
+		})
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		spec    string
+		name    string
+		version string
+	}{
+		{"django==4.2.7", "django", "==4.2.7"},
+		{"Django >= 4.2, < 5", "Django", ">=4.2,<5"},
+		{"uvicorn[standard]>=0.23", "uvicorn", ">=0.23"},
+		{`pywin32>=306; sys_platform == "win32"`, "pywin32", ">=306"},
+		{"requests (>=2.31)", "requests", ">=2.31"},
+		{"pkg @ https://example.com/pkg-1.0.tar.gz", "pkg", ""},
+		{"numpy==1.26.*", "numpy", "==1.26.*"},
+		{"flask", "flask", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			req, ok := parseRequirement(tt.spec)
+			if !ok {
+				t.Fatalf("parseRequirement(%q) failed", tt.spec)
+			}
+			if req.name != tt.name || req.constraint != tt.version {
+				t.Errorf("parseRequirement(%q) = %q %q, want %q %q", tt.spec, req.name, req.constraint, tt.name, tt.version)
+			}
+		})
+	}
+}
+
+func TestDetect_Python(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string // "type/name" -> "version|declared|scope"
+		absent   []string
+	}{
+		{
+			name: "requirements with includes",
+			files: map[string]string{
+				"requirements.txt": `# web stack
+-r requirements/base.txt
+-c constraints.txt
+fastapi>=0.104 --hash=sha256:abc
+-e git+https://github.com/example/tool.git#egg=tool
+./local-package
+--index-url https://pypi.org/simple
+`,
+				"requirements/base.txt": "Flask==3.0.0 \\\n    ; python_version >= \"3.8\"\n-r ../requirements.txt\n",
+				"constraints.txt":       "urllib3<2\n",
+			},
+			expected: map[string]string{
+				"runtime/Python":    "||",
+				"framework/FastAPI": "|>=0.104|prod",
+				"framework/Flask":   "3.0.0|==3.0.0|prod",
+				"dependency/tool":   "||prod",
+			},
+			absent: []string{"dependency/urllib3", "dependency/--index-url", "dependency/./local-package"},
+		},
+		{
+			name: "pep 621 with uv lock",
+			files: map[string]string{
+				"pyproject.toml": `[build-system]
+requires = ["hatchling"]
+build-backend = "hatchling.build"
+
+[project]
+name = "service"
+version = "1.2.0"
+requires-python = ">=3.11"
+dependencies = ["django>=4.2", "psycopg[binary]>=3.1"]
+
+[project.optional-dependencies]
+ml = ["torch>=2.1"]
+
+[dependency-groups]
+dev = ["pytest>=7"]
+`,
+				"uv.lock": `version = 1
+
+[[package]]
+name = "django"
+version = "4.2.9"
+
+[[package]]
+name = "psycopg"
+version = "3.1.18"
+`,
+			},
+			expected: map[string]string{
+				"runtime/Python":     "3.11||",
+				"build/Hatch":        "||",
+				"build/uv":           "||",
+				"package/service":    "1.2.0||",
+				"framework/Django":   "4.2.9|>=4.2|prod",
+				"framework/PyTorch":  "|>=2.1|prod",
+				"dependency/psycopg": "3.1.18|>=3.1|prod",
+				"dependency/pytest":  "|>=7|dev",
+			},
+		},
+		{
+			name: "poetry",
+			files: map[string]string{
+				"pyproject.toml": `[tool.poetry]
+name = "app"
+version = "0.3.0"
+
+[tool.poetry.dependencies]
+python = "^3.10"
+flask = "^3.0"
+requests = { version = "2.31.0", extras = ["socks"] }
+
+[tool.poetry.group.dev.dependencies]
+black = "*"
+`,
+			},
+			expected: map[string]string{
+				"runtime/Python":      "3.10||",
+				"build/Poetry":        "||",
+				"package/app":         "0.3.0||",
+				"framework/Flask":     "|^3.0|prod",
+				"dependency/requests": "2.31.0|2.31.0|prod",
+				"dependency/black":    "||dev",
+			},
+			absent: []string{"dependency/python"},
+		},
+		{
+			name: "pipfile with lock",
+			files: map[string]string{
+				"Pipfile": `[packages]
+django = "*"
+
+[dev-packages]
+pytest = ">=7.0"
+
+[requires]
+python_version = "3.12"
+`,
+				"Pipfile.lock": `{"_meta": {"pipfile-spec": 6}, "default": {"django": {"version": "==5.0.1"}}, "develop": {}}`,
+			},
+			expected: map[string]string{
+				"runtime/Python":    "3.12||",
+				"build/Pipenv":      "||",
+				"framework/Django":  "5.0.1||prod",
+				"dependency/pytest": "|>=7.0|dev",
+			},
+		},
+		{
+			name: "setup.cfg",
+			files: map[string]string{
+				"setup.cfg": `[metadata]
+name = legacy
+version = attr: legacy.__version__
+
+[options]
+python_requires = >=3.8
+install_requires =
+    tensorflow>=2.15
+    numpy
+
+[options.extras_require]
+test = pytest
+`,
+			},
+			expected: map[string]string{
+				"runtime/Python":       "3.8||",
+				"build/setuptools":     "||",
+				"package/legacy":       "||",
+				"framework/TensorFlow": "|>=2.15|prod",
+				"dependency/numpy":     "||prod",
+				"dependency/pytest":    "||dev",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			techs, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect() failed: %v", err)
+			}
+
+			found := make(map[string]string)
+			for _, tech := range techs {
+				found[tech.Type+"/"+tech.Name] = tech.Version + "|" + tech.DeclaredVersion + "|" + string(tech.Scope)
+			}
+			for key, version := range tt.expected {
+				got, ok := found[key]
+				if !ok {
+					t.Errorf("Detect() did not report %s; got %v", key, found)
+					continue
+				}
+				if got != version {
+					t.Errorf("%s = %q, want %q", key, got, version)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := found[key]; ok {
+					t.Errorf("Detect() reported %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestDetect_Lockfiles(t *testing.T) {
+	packageJSON := `{
+  "dependencies": {"react": "^18.2.0", "left-pad": ">=1 <3"},
+  "devDependencies": {"typescript": "~5.3.0"}
+}`
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string // "type/name" -> "version|declared|scope|transitive"
+		absent   []string
+	}{
+		{
+			name: "package-lock v3",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"package-lock.json": `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/react": {"version": "18.2.0", "dependencies": {"loose-envify": "^1.1.0"}},
+    "node_modules/loose-envify": {"version": "1.4.0", "dependencies": {"js-tokens": "^4.0.0"}},
+    "node_modules/js-tokens": {"version": "4.0.0"},
+    "node_modules/left-pad": {"version": "1.3.0"},
+    "node_modules/typescript": {"version": "5.3.3", "dev": true},
+    "node_modules/typescript/node_modules/js-tokens": {"version": "3.0.0"},
+    "node_modules/unused": {"version": "1.0.0"}
+  }
+}`,
+			},
+			expected: map[string]string{
+				"framework/react":         "18.2.0|^18.2.0|prod|false",
+				"dependency/left-pad":     "1.3.0|>=1 <3|prod|false",
+				"dependency/typescript":   "5.3.3|~5.3.0|dev|false",
+				"dependency/loose-envify": "1.4.0||prod|true",
+				"dependency/js-tokens":    "4.0.0||prod|true",
+			},
+			absent: []string{"dependency/unused"},
+		},
+		{
+			name: "yarn v1",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"yarn.lock": `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"left-pad@>=1 <3":
+  version "1.3.0"
+
+react@^18.2.0:
+  version "18.2.0"
+  dependencies:
+    loose-envify "^1.1.0"
+
+loose-envify@^1.1.0:
+  version "1.4.0"
+
+typescript@~5.3.0:
+  version "5.3.3"
+`,
+			},
+			expected: map[string]string{
+				"framework/react":         "18.2.0|^18.2.0|prod|false",
+				"dependency/left-pad":     "1.3.0|>=1 <3|prod|false",
+				"dependency/typescript":   "5.3.3|~5.3.0|dev|false",
+				"dependency/loose-envify": "1.4.0||prod|true",
+			},
+		},
+		{
+			name: "yarn berry",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"yarn.lock": `__metadata:
+  version: 6
+  cacheKey: 8
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "app@workspace:."
+
+"react@npm:^18.2.0":
+  version: 18.2.0
+  dependencies:
+    loose-envify: ^1.1.0
+
+"loose-envify@npm:^1.1.0":
+  version: 1.4.0
+
+"typescript@npm:~5.3.0, typescript@patch:typescript@~5.3.0#builtin<compat/typescript>":
+  version: 5.3.3
+`,
+			},
+			expected: map[string]string{
+				"framework/react":         "18.2.0|^18.2.0|prod|false",
+				"dependency/typescript":   "5.3.3|~5.3.0|dev|false",
+				"dependency/loose-envify": "1.4.0||prod|true",
+			},
+			absent: []string{"dependency/app"},
+		},
+		{
+			name: "pnpm v9",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"pnpm-lock.yaml": `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      react:
+        specifier: ^18.2.0
+        version: 18.2.0
+    devDependencies:
+      typescript:
+        specifier: ~5.3.0
+        version: 5.3.3
+
+packages:
+
+  loose-envify@1.4.0:
+    resolution: {integrity: sha512-abc==}
+    hasBin: true
+
+  react@18.2.0:
+    resolution: {integrity: sha512-def==}
+
+snapshots:
+
+  loose-envify@1.4.0: {}
+
+  react@18.2.0:
+    dependencies:
+      loose-envify: 1.4.0
+`,
+			},
+			expected: map[string]string{
+				"framework/react":         "18.2.0|^18.2.0|prod|false",
+				"dependency/left-pad":     "|>=1 <3|prod|false",
+				"dependency/typescript":   "5.3.3|~5.3.0|dev|false",
+				"dependency/loose-envify": "1.4.0||prod|true",
+			},
+		},
+		{
+			name: "pnpm v5",
+			files: map[string]string{
+				"package.json": packageJSON,
+				"pnpm-lock.yaml": `lockfileVersion: 5.4
+
+specifiers:
+  react: ^18.2.0
+  typescript: ~5.3.0
+
+dependencies:
+  react: 18.2.0
+
+devDependencies:
+  typescript: 5.3.3
+
+packages:
+
+  /loose-envify/1.4.0:
+    resolution: {integrity: sha512-abc==}
+    dev: false
+
+  /react/18.2.0:
+    dependencies:
+      loose-envify: 1.4.0
+    dev: false
+`,
+			},
+			expected: map[string]string{
+				"framework/react":         "18.2.0|^18.2.0|prod|false",
+				"dependency/typescript":   "5.3.3|~5.3.0|dev|false",
+				"dependency/loose-envify": "1.4.0||prod|true",
+			},
+		},
+		{
+			name: "go.mod and go.sum",
+			files: map[string]string{
+				"go.mod": `module example.com/app
+
+go 1.21
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/spf13/pflag v1.0.5 // indirect
+)
+`,
+				"go.sum": `github.com/inconshreveable/mousetrap v1.1.0 h1:abc=
+github.com/inconshreveable/mousetrap v1.1.0/go.mod h1:def=
+github.com/old/only v1.0.0/go.mod h1:ghi=
+github.com/spf13/pflag v1.0.5 h1:jkl=
+`,
+			},
+			expected: map[string]string{
+				"runtime/Go":                                      "1.21|||false",
+				"framework/github.com/spf13/cobra":                "v1.8.0|v1.8.0||false",
+				"dependency/github.com/spf13/pflag":               "v1.0.5|v1.0.5||true",
+				"dependency/github.com/inconshreveable/mousetrap": "v1.1.0|||true",
+			},
+			absent: []string{"dependency/github.com/old/only"},
+		},
+		{
+			name: "Cargo.lock",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"app\"\nedition = \"2021\"\n\n[dependencies]\nserde = \"1\"\nsyn = \"2\"\n",
+				"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "serde",
+ "syn 2.0.48",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.195"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "syn 1.0.109",
+]
+
+[[package]]
+name = "syn"
+version = "1.0.109"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "syn"
+version = "2.0.48"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+dependencies = [
+ "unicode-ident",
+]
+
+[[package]]
+name = "unicode-ident"
+version = "1.0.12"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			},
+			expected: map[string]string{
+				"dependency/serde":         "1.0.195|1|prod|false",
+				"dependency/syn":           "2.0.48|2|prod|false",
+				"dependency/unicode-ident": "1.0.12||prod|true",
+			},
+			absent: []string{"dependency/app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			techs, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect() failed: %v", err)
+			}
+
+			found := make(map[string]string)
+			for _, tech := range techs {
+				found[tech.Type+"/"+tech.Name] = fmt.Sprintf("%s|%s|%s|%t", tech.Version, tech.DeclaredVersion, tech.Scope, tech.Transitive)
+			}
+			for key, want := range tt.expected {
+				got, ok := found[key]
+				if !ok {
+					t.Errorf("Detect() did not report %s; got %v", key, found)
+					continue
+				}
+				if got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := found[key]; ok {
+					t.Errorf("Detect() reported %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestDetect_GoMod(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.22.0
+
+toolchain go1.22.3
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	github.com/labstack/echo/v4 v4.11.4
+	google.golang.org/grpc v1.60.0 // indirect
+	"example.com/quoted" v0.1.0
+	example.com/local v0.0.0-00010101000000-000000000000
+	example.com/forked v1.0.0
+	example.com/pinned v1.2.0
+)
+
+require gorm.io/gorm v1.25.5
+
+replace example.com/local => ../local
+
+replace (
+	example.com/forked => github.com/someone/forked v1.0.1 // patched
+	example.com/pinned v1.1.0 => example.com/pinned v1.1.1
+)
+
+exclude example.com/excluded v1.0.0
+`,
+	})
+
+	techs, err := Detect(root)
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	found := make(map[string]string)
+	for _, tech := range techs {
+		found[tech.Type+"/"+tech.Name] = fmt.Sprintf("%s|%s|%t", tech.Version, tech.DeclaredVersion, tech.Transitive)
+	}
+	expected := map[string]string{
+		"runtime/Go":                            "1.22.0||false",
+		"build/Go toolchain":                    "1.22.3||false",
+		"framework/github.com/gin-gonic/gin":    "v1.9.1|v1.9.1|false",
+		"framework/github.com/labstack/echo/v4": "v4.11.4|v4.11.4|false",
+		"framework/gorm.io/gorm":                "v1.25.5|v1.25.5|false",
+		"dependency/google.golang.org/grpc":     "v1.60.0|v1.60.0|true",
+		"dependency/example.com/quoted":         "v0.1.0|v0.1.0|false",
+		"dependency/example.com/local":          "../local|v0.0.0-00010101000000-000000000000|false",
+		"dependency/example.com/forked":         "v1.0.1|v1.0.0|false",
+		"dependency/example.com/pinned":         "v1.2.0|v1.2.0|false",
+	}
+	for key, want := range expected {
+		got, ok := found[key]
+		if !ok {
+			t.Errorf("Detect() did not report %s; got %v", key, found)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := found["dependency/example.com/excluded"]; ok {
+		t.Error("Detect() reported an excluded module")
+	}
+}
+
+func TestDetect_Cargo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"Cargo.toml": `[workspace]
+members = ["crates/*"]
+
+[workspace.package]
+edition = "2021"
+
+[workspace.dependencies]
+serde = { version = "1.0", features = ["derive"] }
+util = { path = "crates/util" }
+`,
+		"Cargo.lock": `version = 3
+
+[[package]]
+name = "server"
+version = "0.1.0"
+dependencies = [
+ "serde",
+ "tokio",
+ "util",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.195"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "tokio"
+version = "1.35.1"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "util"
+version = "0.1.0"
+`,
+		"crates/server/Cargo.toml": `# edition = "2015" in a comment must not count
+[package]
+name = "server"
+rust-version = "1.70"
+edition.workspace = true
+
+[dependencies]
+serde = { workspace = true, features = ["rc"] }
+util.workspace = true
+tokio = { version = "1", features = ["full"] }
+json = { package = "serde_json", version = "=1.0.111" }
+axum = { git = "https://github.com/tokio-rs/axum", branch = "main" }
+
+[target.'cfg(unix)'.dependencies]
+nix = "0.27"
+
+[build-dependencies]
+cc = "1.0"
+
+[dev-dependencies]
+tokio = { version = "1", features = ["test-util"] }
+insta = "1.34"
+`,
+	})
+
+	techs, err := Detect(filepath.Join(root, "crates", "server"))
+	if err != nil {
+		t.Fatalf("Detect() failed: %v", err)
+	}
+
+	found := make(map[string]string)
+	for _, tech := range techs {
+		found[tech.Type+"/"+tech.Name] = fmt.Sprintf("%s|%s|%s|%s|%s", tech.Version, tech.DeclaredVersion, tech.Scope, tech.Source, strings.Join(tech.Features, ","))
+	}
+	expected := map[string]string{
+		"runtime/Rust":          "2021||||",
+		"framework/tokio":       "1.35.1|1|prod||full",
+		"framework/axum":        "||prod|git+https://github.com/tokio-rs/axum?branch=main|",
+		"dependency/serde":      "1.0.195|1.0|prod||derive,rc",
+		"dependency/util":       "0.1.0||prod|path+../util|",
+		"dependency/serde_json": "1.0.111|=1.0.111|prod||",
+		"dependency/nix":        "|0.27|prod||",
+		"dependency/cc":         "|1.0|build||",
+		"dependency/insta":      "|1.34|dev||",
+	}
+	for key, want := range expected {
+		got, ok := found[key]
+		if !ok {
+			t.Errorf("Detect() did not report %s; got %v", key, found)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := found["dependency/json"]; ok {
+		t.Error("Detect() reported a renamed dependency under its alias")
+	}
+}
+
+func TestDetect_Ecosystems(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+		absent   []string
+	}{
+		{
+			name: ".NET solution",
+			files: map[string]string{
+				"App.sln": `Microsoft Visual Studio Solution File, Format Version 12.00
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Api", "src\Api\Api.csproj", "{11111111-1111-1111-1111-111111111111}"
+EndProject
+Project("{2150E333-8FDC-42A3-9474-1A3956D46DE8}") = "tests", "tests", "{22222222-2222-2222-2222-222222222222}"
+EndProject
+Project("{FAE04EC0-301F-11D3-BF4B-00C04F79EFBC}") = "Api.Tests", "tests\Api.Tests\Api.Tests.csproj", "{33333333-3333-3333-3333-333333333333}"
+EndProject
+`,
+				"global.json":              `{"sdk": {"version": "8.0.100"}}`,
+				"Directory.Packages.props": `<Project><ItemGroup><PackageVersion Include="Serilog" Version="3.1.1" /></ItemGroup></Project>`,
+				"src/Api/Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Microsoft.EntityFrameworkCore" Version="8.0.0" />
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="StyleCop.Analyzers" Version="[1.1.118]">
+      <PrivateAssets>all</PrivateAssets>
+    </PackageReference>
+  </ItemGroup>
+</Project>`,
+				"src/Api/packages.lock.json": `{"version": 1, "dependencies": {"net8.0": {
+  "Microsoft.EntityFrameworkCore": {"type": "Direct", "requested": "[8.0.0, )", "resolved": "8.0.0", "dependencies": {"Microsoft.Extensions.Caching.Memory": "8.0.0"}},
+  "Microsoft.Extensions.Caching.Memory": {"type": "Transitive", "resolved": "8.0.0"}
+}}}`,
+				"tests/Api.Tests/Api.Tests.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><TargetFrameworks>net8.0;net48</TargetFrameworks></PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Microsoft.NET.Test.Sdk" Version="17.8.0" />
+    <PackageReference Include="xunit" Version="2.6.2" />
+  </ItemGroup>
+</Project>`,
+			},
+			expected: map[string]string{
+				"runtime/.NET":                                   "8.0||||false",
+				"runtime/.NET Framework":                         "4.8||||false",
+				"framework/ASP.NET Core":                         "||||false",
+				"build/.NET SDK":                                 "8.0.100||||false",
+				"framework/Microsoft.EntityFrameworkCore":        "8.0.0|8.0.0|prod||false",
+				"dependency/Serilog":                             "|3.1.1|prod||false",
+				"dependency/StyleCop.Analyzers":                  "1.1.118|[1.1.118]|dev||false",
+				"framework/xunit":                                "|2.6.2|dev||false",
+				"dependency/Microsoft.Extensions.Caching.Memory": "8.0.0||prod||true",
+			},
+		},
+		{
+			name: "Composer",
+			files: map[string]string{
+				"composer.json": `{
+  "require": {"php": "^8.2", "ext-json": "*", "laravel/framework": "^11.0", "guzzlehttp/guzzle": "7.8.1"},
+  "require-dev": {"phpunit/phpunit": "^10.5"}
+}`,
+				"composer.lock": `{
+  "packages": [
+    {"name": "laravel/framework", "version": "v11.0.3", "require": {"php": "^8.2", "symfony/console": "^7.0"}},
+    {"name": "guzzlehttp/guzzle", "version": "7.8.1"},
+    {"name": "symfony/console", "version": "v7.0.4"}
+  ],
+  "packages-dev": [
+    {"name": "phpunit/phpunit", "version": "10.5.10"}
+  ]
+}`,
+			},
+			expected: map[string]string{
+				"runtime/PHP":                  "8.2|^8.2|||false",
+				"framework/laravel/framework":  "v11.0.3|^11.0|prod||false",
+				"dependency/guzzlehttp/guzzle": "7.8.1|7.8.1|prod||false",
+				"framework/phpunit/phpunit":    "10.5.10|^10.5|dev||false",
+				"dependency/symfony/console":   "v7.0.4||prod||true",
+			},
+			absent: []string{"dependency/ext-json", "dependency/php"},
+		},
+		{
+			name: "Bundler",
+			files: map[string]string{
+				"Gemfile": `source "https://rubygems.org"
+
+ruby "~> 3.2"
+
+gem "rails", "~> 7.1", ">= 7.1.2"
+gem "pg" # database
+gem "sidekiq", github: "sidekiq/sidekiq", branch: "main"
+gem "debug", group: [:development, :test]
+
+group :development, :test do
+  gem "rspec-rails"
+end
+
+group :production do
+  gem "lograge"
+end
+`,
+				"Gemfile.lock": `GEM
+  remote: https://rubygems.org/
+  specs:
+    actionpack (7.1.2)
+      rack (>= 2.2.4)
+    nokogiri (1.15.5-x86_64-linux)
+      racc (~> 1.4)
+    pg (1.5.4)
+    rack (3.0.8)
+    racc (1.7.3)
+    rails (7.1.2)
+      actionpack (= 7.1.2)
+
+RUBY VERSION
+   ruby 3.2.2p53
+
+BUNDLED WITH
+   2.4.22
+`,
+			},
+			expected: map[string]string{
+				"runtime/Ruby":           "3.2.2|~> 3.2|||false",
+				"build/Bundler":          "2.4.22||||false",
+				"framework/rails":        "7.1.2|~> 7.1, >= 7.1.2|prod||false",
+				"dependency/pg":          "1.5.4||prod||false",
+				"dependency/sidekiq":     "||prod|git+https://github.com/sidekiq/sidekiq.git?branch=main|false",
+				"dependency/debug":       "||dev||false",
+				"dependency/rspec-rails": "||dev||false",
+				"dependency/lograge":     "||prod||false",
+				"dependency/actionpack":  "7.1.2||prod||true",
+				"dependency/rack":        "3.0.8||prod||true",
+			},
+			absent: []string{"dependency/nokogiri"},
+		},
+		{
+			name: "Mix",
+			files: map[string]string{
+				"mix.exs": `defmodule App.MixProject do
+  use Mix.Project
+
+  def project do
+    [app: :app, version: "0.1.0", elixir: "~> 1.15", deps: deps()]
+  end
+
+  defp deps do
+    [
+      {:phoenix, "~> 1.7.10"},
+      # {:commented, "~> 1.0"},
+      {:credo, "~> 1.7", only: [:dev, :test], runtime: false},
+      {:telemetry, "~> 1.0", only: [:dev, :prod]},
+      {:my_lib, github: "acme/my_lib", tag: "v0.2.0"}
+    ]
+  end
+end
+`,
+				"mix.lock": `%{
+  "credo": {:hex, :credo, "1.7.3", "abc", [:mix], [{:jason, "~> 1.0", [hex: :jason, repo: "hexpm", optional: false]}], "hexpm", "def"},
+  "jason": {:hex, :jason, "1.4.1", "abc", [:mix], [], "hexpm", "def"},
+  "my_lib": {:git, "https://github.com/acme/my_lib.git", "0123abc", [tag: "v0.2.0"]},
+  "phoenix": {:hex, :phoenix, "1.7.10", "abc", [:mix], [{:plug, "~> 1.14", [hex: :plug, repo: "hexpm", optional: false]}], "hexpm", "def"},
+  "plug": {:hex, :plug, "1.15.2", "abc", [:mix], [], "hexpm", "def"},
+}
+`,
+			},
+			expected: map[string]string{
+				"runtime/Elixir":       "1.15|~> 1.15|||false",
+				"framework/phoenix":    "1.7.10|~> 1.7.10|prod||false",
+				"dependency/credo":     "1.7.3|~> 1.7|dev||false",
+				"dependency/telemetry": "|~> 1.0|prod||false",
+				"dependency/my_lib":    "||prod|git+https://github.com/acme/my_lib.git?tag=v0.2.0|false",
+				"dependency/plug":      "1.15.2||prod||true",
+				"dependency/jason":     "1.4.1||dev||true",
+			},
+			absent: []string{"dependency/commented"},
+		},
+		{
+			name: "Flutter",
+			files: map[string]string{
+				"pubspec.yaml": `name: app
+environment:
+  sdk: ">=3.2.0 <4.0.0"
+  flutter: ">=3.16.0"
+
+dependencies:
+  flutter:
+    sdk: flutter
+  http: ^1.1.0
+  flutter_riverpod: ^2.4.9
+  shared:
+    path: ../shared
+  charts:
+    git:
+      url: https://github.com/acme/charts.git
+      ref: main
+
+dev_dependencies:
+  flutter_test:
+    sdk: flutter
+  lints: ^3.0.0
+`,
+				"pubspec.lock": `packages:
+  async:
+    dependency: transitive
+    source: hosted
+    version: "2.11.0"
+  http:
+    dependency: "direct main"
+    source: hosted
+    version: "1.1.2"
+  lints:
+    dependency: "direct dev"
+    source: hosted
+    version: "3.0.0"
+sdks:
+  dart: ">=3.2.0 <4.0.0"
+`,
+			},
+			expected: map[string]string{
+				"runtime/Dart":               "3.2.0|>=3.2.0 <4.0.0|||false",
+				"framework/Flutter":          "3.16.0|>=3.16.0|||false",
+				"dependency/http":            "1.1.2|^1.1.0|prod||false",
+				"framework/flutter_riverpod": "|^2.4.9|prod||false",
+				"dependency/shared":          "||prod|path+../shared|false",
+				"dependency/charts":          "||prod|git+https://github.com/acme/charts.git?ref=main|false",
+				"dependency/lints":           "3.0.0|^3.0.0|dev||false",
+				"dependency/async":           "2.11.0||||true",
+			},
+			absent: []string{"dependency/flutter", "dependency/flutter_test"},
+		},
+		{
+			name: "Swift package",
+			files: map[string]string{
+				"Package.swift": `// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "App",
+    dependencies: [
+        .package(url: "https://github.com/vapor/vapor.git", from: "4.89.0"),
+        .package(url: "https://github.com/apple/swift-log.git", exact: "1.5.3"),
+        .package(url: "https://github.com/acme/Utils.git", branch: "main"),
+        .package(path: "../Shared"),
+    ],
+    targets: [.executableTarget(name: "App", dependencies: [.product(name: "Vapor", package: "vapor")])]
+)
+`,
+				"Package.resolved": `{
+  "pins": [
+    {"identity": "vapor", "kind": "remoteSourceControl", "location": "https://github.com/vapor/vapor.git", "state": {"revision": "abc", "version": "4.89.3"}},
+    {"identity": "swift-nio", "kind": "remoteSourceControl", "location": "https://github.com/apple/swift-nio.git", "state": {"revision": "def", "version": "2.62.0"}}
+  ],
+  "version": 2
+}`,
+			},
+			expected: map[string]string{
+				"runtime/Swift":        "5.9||||false",
+				"framework/vapor":      "4.89.3|from: 4.89.0|prod|git+https://github.com/vapor/vapor.git|false",
+				"dependency/swift-log": "1.5.3|exact: 1.5.3|prod|git+https://github.com/apple/swift-log.git|false",
+				"dependency/utils":     "||prod|git+https://github.com/acme/Utils.git?branch=main|false",
+				"dependency/shared":    "||prod|path+../Shared|false",
+				"dependency/swift-nio": "2.62.0||||true",
+			},
+		},
+		{
+			name: "Zig",
+			files: map[string]string{
+				"build.zig": "const std = @import(\"std\");\n",
+				"build.zig.zon": `.{
+    .name = .app,
+    .version = "0.1.0",
+    .minimum_zig_version = "0.13.0",
+    // Fetched with zig fetch --save.
+    .dependencies = .{
+        .zap = .{
+            .url = "https://github.com/zigzap/zap/archive/refs/tags/v0.8.0.tar.gz",
+            .hash = "12209936c3333b53b53edcf453b1670babb9ae8c2197b1ca627c01e72670e20c1a8",
+        },
+        .@"known-folders" = .{ .path = "deps/known-folders", .lazy = true },
+    },
+    .paths = .{ "build.zig", "build.zig.zon", "src" },
+}
+`,
+			},
+			expected: map[string]string{
+				"runtime/Zig":              "0.13.0||||false",
+				"framework/zap":            "||prod|https://github.com/zigzap/zap/archive/refs/tags/v0.8.0.tar.gz|false",
+				"dependency/known-folders": "||prod|path+deps/known-folders|false",
+			},
+		},
+		{
+			name: "CMake",
+			files: map[string]string{
+				"CMakeLists.txt": `cmake_minimum_required(VERSION 3.20...3.28)
+# find_package(Ignored)
+set(CMAKE_CXX_STANDARD 20)
+project(App VERSION 1.0
+        LANGUAGES CXX)
+
+find_package(Boost 1.80 REQUIRED COMPONENTS system filesystem)
+find_package(Qt6 REQUIRED COMPONENTS Widgets)
+find_package(GTest)
+
+include(FetchContent)
+FetchContent_Declare(
+  fmt
+  GIT_REPOSITORY https://github.com/fmtlib/fmt.git
+  GIT_TAG 10.1.1
+)
+CPMAddPackage("gh:nlohmann/json@3.11.3")
+`,
+			},
+			expected: map[string]string{
+				"runtime/C++":      "20||||false",
+				"build/CMake":      "3.20|>=3.20|||false",
+				"dependency/Boost": "|>=1.80|prod|system,filesystem|false",
+				"framework/Qt6":    "||prod|Widgets|false",
+				"framework/GTest":  "||dev||false",
+				"dependency/fmt":   "10.1.1||prod|git+https://github.com/fmtlib/fmt.git?tag=10.1.1|false",
+				"dependency/json":  "3.11.3||prod|git+https://github.com/nlohmann/json?tag=3.11.3|false",
+			},
+			absent: []string{"runtime/C", "dependency/Ignored"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			techs, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect() failed: %v", err)
+			}
+
+			found := technologyFields(techs)
+			for key, want := range tt.expected {
+				got, ok := found[key]
+				if !ok {
+					t.Errorf("Detect() did not report %s; got %v", key, found)
+					continue
+				}
+				if got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := found[key]; ok {
+					t.Errorf("Detect() reported %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestDetect_Infrastructure(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+		absent   []string
+	}{
+		{
+			name: "Dockerfile stages",
+			files: map[string]string{
+				"Dockerfile": `# syntax=docker/dockerfile:1
+ARG NODE_VERSION=16
+FROM --platform=$BUILDPLATFORM node:${NODE_VERSION}-alpine AS deps
+RUN npm ci
+
+FROM deps AS build
+RUN npm run build
+
+FROM gcr.io/distroless/nodejs:18@sha256:0123 AS runtime
+COPY --from=build /app /app
+`,
+				"worker.Dockerfile": "FROM python:3.12-slim\nFROM scratch\n",
+				"Dockerfile.dev": `FROM node:16 AS base
+FROM base
+`,
+			},
+			expected: map[string]string{
+				"container/gcr.io/distroless/nodejs": "18||prod||false",
+				"container/node":                     "16||prod||false",
+				"container/python":                   "3.12-slim||build||false",
+			},
+			absent: []string{"container/deps", "container/scratch", "container/base"},
+		},
+		{
+			name: "Compose",
+			files: map[string]string{
+				".env": "TAG=7.2\n",
+				"docker-compose.yml": `services:
+  web:
+    build:
+      context: ./web
+    image: acme/web
+  db:
+    image: postgres:15
+  cache:
+    image: "redis:${TAG:-latest}"
+`,
+			},
+			expected: map[string]string{
+				"deploy/Docker Compose": "||||false",
+				"service/web":           "|||path+web|false",
+				"service/db":            "|||postgres:15|false",
+				"service/cache":         "|||redis:7.2|false",
+				"container/postgres":    "15||prod||false",
+				"container/redis":       "7.2||prod||false",
+			},
+			absent: []string{"container/acme/web"},
+		},
+		{
+			name: "Kubernetes",
+			files: map[string]string{
+				"k8s/kustomization.yaml": "resources:\n  - app.yaml\n",
+				"k8s/app.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: ghcr.io/acme/migrate:1.0
+      containers:
+        - name: api
+          image: ghcr.io/acme/api:2.3.1
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: report
+              image: busybox
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+`,
+			},
+			expected: map[string]string{
+				"deploy/Kubernetes":              "|||CronJob,Deployment,Service|false",
+				"deploy/Kustomize":               "||||false",
+				"container/ghcr.io/acme/api":     "2.3.1||prod||false",
+				"container/ghcr.io/acme/migrate": "1.0||prod||false",
+				"container/busybox":              "||prod||false",
+			},
+		},
+		{
+			name: "Helm chart",
+			files: map[string]string{
+				"charts/api/Chart.yaml": `apiVersion: v2
+name: api
+version: 0.4.0
+dependencies:
+  - name: postgresql
+    version: ~12.5.0
+    repository: https://charts.bitnami.com/bitnami
+  - name: common
+    version: 1.0.0
+    repository: file://../common
+`,
+				"charts/api/Chart.lock": `dependencies:
+- name: postgresql
+  repository: https://charts.bitnami.com/bitnami
+  version: 12.5.6
+`,
+				"charts/api/values.yaml": `image:
+  registry: ghcr.io
+  repository: acme/api
+  tag: "2.3.1"
+sidecar:
+  image: envoyproxy/envoy:v1.28.0
+`,
+			},
+			expected: map[string]string{
+				"deploy/Helm":                "|||api|false",
+				"dependency/postgresql":      "12.5.6|~12.5.0|prod|https://charts.bitnami.com/bitnami|false",
+				"dependency/common":          "1.0.0|1.0.0|prod|path+charts/common|false",
+				"container/ghcr.io/acme/api": "2.3.1||prod||false",
+				"container/envoyproxy/envoy": "v1.28.0||prod||false",
+			},
+		},
+		{
+			name: "Terraform",
+			files: map[string]string{
+				"terraform/main.tf": `terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0" # pinned by the lock file
+    }
+    random = { source = "hashicorp/random", version = "3.6.0" }
+  }
+}
+
+/* The default provider. */
+provider "aws" {
+  region = var.region
+  default_tags {
+    tags = merge(var.tags, {
+      Project = "app"
+    })
+  }
+}
+
+resource "aws_iam_policy" "read" {
+  policy = <<-EOT
+    { "Version": "2012-10-17" }
+  EOT
+}
+
+locals {
+  names = { for k, v in var.users : k => v.name }
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+}
+
+module "network" {
+  source = "./modules/network"
+}
+
+module "dns" {
+  source = "git::https://github.com/acme/tf-dns.git?ref=v1.2.0"
+}
+`,
+				"terraform/.terraform.lock.hcl": `provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/tls" {
+  version = "4.0.5"
+}
+`,
+			},
+			expected: map[string]string{
+				"deploy/Terraform":                     "1.5.0|>= 1.5.0|||false",
+				"provider/hashicorp/aws":               "5.31.0|~> 5.0|prod||false",
+				"provider/hashicorp/random":            "3.6.0|3.6.0|prod||false",
+				"provider/hashicorp/tls":               "4.0.5||prod||false",
+				"module/terraform-aws-modules/vpc/aws": "5.1.2|5.1.2|prod||false",
+				"module/network":                       "||prod|path+terraform/modules/network|false",
+				"module/dns":                           "||prod|git+https://github.com/acme/tf-dns.git?ref=v1.2.0|false",
+			},
+		},
+		{
+			name: "CI pipelines",
+			files: map[string]string{
+				".github/workflows/ci.yml":      "on: push\n",
+				".github/workflows/release.yml": "on: push\n",
+				".gitlab-ci.yml":                "test:\n  script: make test\n",
+				"Jenkinsfile":                   "pipeline {}\n",
+			},
+			expected: map[string]string{
+				"ci/GitHub Actions": "||||false",
+				"ci/GitLab CI":      "||||false",
+				"ci/Jenkins":        "||||false",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			techs, err := Detect(root)
+			if err != nil {
+				t.Fatalf("Detect() failed: %v", err)
+			}
+
+			found := technologyFields(techs)
+			for key, want := range tt.expected {
+				got, ok := found[key]
+				if !ok {
+					t.Errorf("Detect() did not report %s; got %v", key, found)
+					continue
+				}
+				if got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := found[key]; ok {
+					t.Errorf("Detect() reported %s", key)
+				}
+			}
+		})
+	}
+}
+
+// technologyFields maps each technology, as "type/name", to its version,
+// declared version, scope, source or features, and whether it is
+// transitive, joined by "|".
+func technologyFields(techs []models.Technology) map[string]string {
+	found := make(map[string]string)
+	for _, tech := range techs {
+		detail := tech.Source
+		if len(tech.Features) > 0 {
+			detail = strings.Join(tech.Features, ",")
+		}
+		found[tech.Type+"/"+tech.Name] = fmt.Sprintf("%s|%s|%s|%s|%t", tech.Version, tech.DeclaredVersion, tech.Scope, detail, tech.Transitive)
+	}
+	return found
+}
@@ -0,0 +1,175 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var (
+	dockerInstruction = regexp.MustCompile(`(?i)^(FROM|ARG)\s+(.*)$`)
+	dockerVariable    = regexp.MustCompile(`\$\{(\w+)(?::?[-+]([^}]*))?\}|\$(\w+)`)
+)
+
+type dockerDetector struct{}
+
+func init() {
+	Register(dockerDetector{})
+}
+
+func (dockerDetector) Name() string { return "docker" }
+
+func (dockerDetector) IndicatesProject() bool { return false }
+
+func (dockerDetector) Files() []string {
+	return []string{"Dockerfile", "Containerfile", "Dockerfile.*", "*.Dockerfile", "*.dockerfile"}
+}
+
+// Detect records the base image of every stage as a container. Images
+// other than the one the final stage is built from only serve to build it.
+func (dockerDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	var images imageSet
+	for _, file := range files {
+		refs, final, err := readDockerfile(filepath.Join(projectPath, file))
+		if err != nil {
+			return images.techs, err
+		}
+		for _, ref := range refs {
+			scope := models.ScopeBuild
+			if ref == final {
+				scope = models.ScopeProd
+			}
+			images.add(ref, scope, file)
+		}
+	}
+	return images.techs, nil
+}
+
+// readDockerfile returns the base images of the stages of a Dockerfile and
+// the image its final stage is built from, with the arguments declared
+// before the first FROM substituted. Stages built from an earlier stage,
+// and scratch, add no image.
+func readDockerfile(path string) (images []string, final string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	args := make(map[string]string)
+	stages := make(map[string]string)
+	sawFrom := false
+	var line string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		text := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasSuffix(text, `\`) {
+			line += strings.TrimSuffix(text, `\`) + " "
+			continue
+		}
+		line += text
+		m := dockerInstruction.FindStringSubmatch(line)
+		line = ""
+		if m == nil {
+			continue
+		}
+
+		fields := strings.Fields(m[2])
+		if strings.EqualFold(m[1], "ARG") {
+			// Only arguments declared before the first FROM apply to it.
+			if !sawFrom && len(fields) > 0 {
+				name, value, _ := strings.Cut(fields[0], "=")
+				args[name] = strings.Trim(value, `"'`)
+			}
+			continue
+		}
+
+		sawFrom = true
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		image := expandVariables(fields[0], args)
+		if base, ok := stages[strings.ToLower(image)]; ok {
+			image = base
+		} else if image != "scratch" {
+			images = append(images, image)
+		}
+		if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+			stages[strings.ToLower(fields[2])] = image
+		}
+		final = image
+	}
+	return images, final, sc.Err()
+}
+
+// expandVariables substitutes $VAR, ${VAR} and ${VAR:-default} with the
+// values in vars, falling back to the default written in the reference.
+func expandVariables(s string, vars map[string]string) string {
+	return dockerVariable.ReplaceAllStringFunc(s, func(v string) string {
+		m := dockerVariable.FindStringSubmatch(v)
+		name := m[1] + m[3]
+		if value := vars[name]; value != "" {
+			return value
+		}
+		if strings.Contains(v, "+") {
+			return ""
+		}
+		return m[2]
+	})
+}
+
+// parseImageReference splits an image reference such as
+// "ghcr.io/org/app:1.2@sha256:..." into its repository and its tag, or its
+// digest when it has no tag.
+func parseImageReference(ref string) (name, version string) {
+	name, digest, _ := strings.Cut(ref, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, version = name[:i], name[i+1:]
+	}
+	if version == "" {
+		version = digest
+	}
+	return name, version
+}
+
+// imageSet collects container images, each recorded once. An image used
+// both to build and to run is recorded as prod.
+type imageSet struct {
+	techs []models.Technology
+	index map[string]int
+}
+
+func (s *imageSet) add(ref string, scope models.DependencyScope, file string) {
+	name, version := parseImageReference(ref)
+	if name == "" {
+		return
+	}
+	tech := models.Technology{
+		Type:         "container",
+		Name:         name,
+		Version:      version,
+		Scope:        scope,
+		DetectedFrom: file,
+	}
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+	if i, ok := s.index[name]; ok {
+		if scope == models.ScopeProd && s.techs[i].Scope != models.ScopeProd {
+			s.techs[i] = tech
+		}
+		return
+	}
+	s.index[name] = len(s.techs)
+	s.techs = append(s.techs, tech)
+}
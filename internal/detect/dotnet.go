@@ -0,0 +1,390 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// dotnetSDKFrameworks maps project SDKs to the framework they build on.
+var dotnetSDKFrameworks = map[string]string{
+	"Microsoft.NET.Sdk.Web":               "ASP.NET Core",
+	"Microsoft.NET.Sdk.BlazorWebAssembly": "Blazor",
+	"Microsoft.NET.Sdk.WindowsDesktop":    "Windows Desktop",
+	"Microsoft.NET.Sdk.Functions":         "Azure Functions",
+	"Aspire.AppHost.Sdk":                  ".NET Aspire",
+	"Microsoft.NET.Sdk.Razor":             "Razor",
+	"MSTest.Sdk":                          "MSTest",
+}
+
+var dotnetFrameworks = map[string]bool{
+	"Microsoft.EntityFrameworkCore": true,
+	"Microsoft.Maui.Controls":       true,
+	"Xamarin.Forms":                 true,
+	"Avalonia":                      true,
+	"Grpc.AspNetCore":               true,
+	"Microsoft.Orleans.Server":      true,
+	"xunit":                         true,
+	"NUnit":                         true,
+	"MSTest.TestFramework":          true,
+}
+
+var (
+	dotnetSolutionProject = regexp.MustCompile(`^Project\("[^"]*"\)\s*=\s*"[^"]*",\s*"([^"]+)"`)
+	dotnetModernTFM       = regexp.MustCompile(`^net(?:coreapp)?(\d+\.\d+)`)
+	dotnetFrameworkTFM    = regexp.MustCompile(`^net(\d)(\d)(\d)?$`)
+	dotnetStandardTFM     = regexp.MustCompile(`^netstandard(\d+\.\d+)$`)
+)
+
+type msbuildPackage struct {
+	Include             string `xml:"Include,attr"`
+	VersionAttr         string `xml:"Version,attr"`
+	Version             string `xml:"Version"`
+	PrivateAssetsAttr   string `xml:"PrivateAssets,attr"`
+	PrivateAssets       string `xml:"PrivateAssets"`
+	VersionOverrideAttr string `xml:"VersionOverride,attr"`
+}
+
+func (p msbuildPackage) version() string {
+	for _, v := range []string{p.VersionOverrideAttr, p.VersionAttr, p.Version} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type msbuildProject struct {
+	Sdk            string `xml:"Sdk,attr"`
+	PropertyGroups []struct {
+		TargetFramework  string `xml:"TargetFramework"`
+		TargetFrameworks string `xml:"TargetFrameworks"`
+		UseWPF           string `xml:"UseWPF"`
+		UseWindowsForms  string `xml:"UseWindowsForms"`
+		UseMaui          string `xml:"UseMaui"`
+		IsTestProject    string `xml:"IsTestProject"`
+	} `xml:"PropertyGroup"`
+	Packages []msbuildPackage `xml:"ItemGroup>PackageReference"`
+	Versions []msbuildPackage `xml:"ItemGroup>PackageVersion"`
+}
+
+type dotnetDetector struct{}
+
+func init() {
+	Register(dotnetDetector{})
+}
+
+func (dotnetDetector) Name() string { return "dotnet" }
+
+func (dotnetDetector) Files() []string {
+	return []string{"*.sln", "*.csproj", "*.fsproj", "*.vbproj"}
+}
+
+// Includes returns the shared props files, the projects the solutions list
+// and the packages.config and packages.lock.json next to every project.
+func (dotnetDetector) Includes(projectPath string, files []string) []string {
+	includes := []string{"Directory.Packages.props", "global.json"}
+	for _, f := range files {
+		projects := []string{f}
+		if strings.HasSuffix(f, ".sln") {
+			projects = readSolutionProjects(filepath.Join(projectPath, f))
+			includes = append(includes, projects...)
+		}
+		for _, project := range projects {
+			dir := path.Dir(project)
+			includes = append(includes, path.Join(dir, "packages.config"), path.Join(dir, "packages.lock.json"))
+		}
+	}
+	return includes
+}
+
+// dotnetCollector gathers the technologies of the projects of a solution.
+// A package referenced by several projects is recorded once, from the
+// first project that references it.
+type dotnetCollector struct {
+	projectPath string
+	central     map[string]string
+	lock        *lockGraph
+	techs       []models.Technology
+	seen        map[string]bool
+	direct      map[string]models.DependencyScope
+}
+
+func (dotnetDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	c := &dotnetCollector{
+		projectPath: projectPath,
+		central:     readCentralPackageVersions(projectPath),
+		lock:        newLockGraph("packages.lock.json"),
+		seen:        make(map[string]bool),
+		direct:      make(map[string]models.DependencyScope),
+	}
+
+	var projects []string
+	for _, f := range files {
+		if strings.HasSuffix(f, ".sln") {
+			projects = append(projects, readSolutionProjects(filepath.Join(projectPath, f))...)
+		} else {
+			projects = append(projects, f)
+		}
+	}
+
+	visited := make(map[string]bool)
+	for _, project := range projects {
+		if visited[project] {
+			continue
+		}
+		visited[project] = true
+		c.addProject(project)
+	}
+
+	if version := readDotnetSDKVersion(projectPath); version != "" {
+		c.add(models.Technology{Type: "build", Name: ".NET SDK", Version: version, DetectedFrom: "global.json"})
+	}
+	return append(c.techs, c.lock.transitive(c.direct)...), nil
+}
+
+func (c *dotnetCollector) add(tech models.Technology) {
+	key := tech.Type + "/" + tech.Name
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.techs = append(c.techs, tech)
+}
+
+// addProject records the target frameworks, SDK and packages of a project
+// file, given relative to the project root.
+func (c *dotnetCollector) addProject(rel string) {
+	data, err := os.ReadFile(filepath.Join(c.projectPath, filepath.FromSlash(rel)))
+	if err != nil {
+		return
+	}
+	var proj msbuildProject
+	if err := xml.Unmarshal(data, &proj); err != nil {
+		return
+	}
+
+	test := false
+	for _, pg := range proj.PropertyGroups {
+		for _, tfm := range strings.Split(pg.TargetFramework+";"+pg.TargetFrameworks, ";") {
+			if name, version := dotnetRuntime(strings.TrimSpace(tfm)); name != "" {
+				c.add(models.Technology{Type: "runtime", Name: name, Version: version, DetectedFrom: rel})
+			}
+		}
+		for _, ui := range []struct{ enabled, name string }{
+			{pg.UseWPF, "WPF"},
+			{pg.UseWindowsForms, "Windows Forms"},
+			{pg.UseMaui, ".NET MAUI"},
+		} {
+			if strings.EqualFold(strings.TrimSpace(ui.enabled), "true") {
+				c.add(models.Technology{Type: "framework", Name: ui.name, DetectedFrom: rel})
+			}
+		}
+		test = test || strings.EqualFold(strings.TrimSpace(pg.IsTestProject), "true")
+	}
+
+	sdk, _, _ := strings.Cut(proj.Sdk, "/")
+	if name := dotnetSDKFrameworks[sdk]; name != "" {
+		c.add(models.Technology{Type: "framework", Name: name, DetectedFrom: rel})
+	}
+
+	packages := proj.Packages
+	if len(packages) == 0 {
+		packages = readPackagesConfig(path.Join(path.Dir(rel), "packages.config"), c.projectPath)
+	}
+	for _, pkg := range packages {
+		test = test || pkg.Include == "Microsoft.NET.Test.Sdk"
+	}
+
+	readNuGetLock(filepath.Join(c.projectPath, filepath.FromSlash(path.Join(path.Dir(rel), "packages.lock.json"))), c.lock)
+	for _, pkg := range packages {
+		if pkg.Include == "" {
+			continue
+		}
+		scope := models.ScopeProd
+		if test || strings.EqualFold(pkg.PrivateAssetsAttr+pkg.PrivateAssets, "all") {
+			scope = models.ScopeDev
+		}
+		c.addPackage(pkg, scope, rel)
+	}
+}
+
+// addPackage records a NuGet package reference. A bare version is a
+// minimum; only "[1.2.3]" names an exact version without a lock file.
+func (c *dotnetCollector) addPackage(pkg msbuildPackage, scope models.DependencyScope, source string) {
+	declared := pkg.version()
+	if declared == "" {
+		declared = c.central[pkg.Include]
+	}
+	version := ""
+	if strings.HasPrefix(declared, "[") && strings.HasSuffix(declared, "]") && !strings.Contains(declared, ",") {
+		version = strings.Trim(declared, "[]")
+	}
+	if v := c.lock.versions[pkg.Include]; v != "" {
+		version = v
+	}
+
+	techType := "dependency"
+	if dotnetFrameworks[pkg.Include] {
+		techType = "framework"
+	}
+	if _, ok := c.direct[pkg.Include]; !ok {
+		c.direct[pkg.Include] = scope
+	}
+	c.add(models.Technology{
+		Type:            techType,
+		Name:            pkg.Include,
+		Version:         version,
+		DeclaredVersion: declared,
+		Scope:           scope,
+		DetectedFrom:    source,
+	})
+}
+
+// dotnetRuntime names the runtime a target framework moniker such as
+// net8.0, netcoreapp3.1, net48 or netstandard2.0 targets.
+func dotnetRuntime(tfm string) (string, string) {
+	if m := dotnetModernTFM.FindStringSubmatch(tfm); m != nil {
+		return ".NET", m[1]
+	}
+	if m := dotnetFrameworkTFM.FindStringSubmatch(tfm); m != nil {
+		version := m[1] + "." + m[2]
+		if m[3] != "" {
+			version += "." + m[3]
+		}
+		return ".NET Framework", version
+	}
+	if m := dotnetStandardTFM.FindStringSubmatch(tfm); m != nil {
+		return ".NET Standard", m[1]
+	}
+	return "", ""
+}
+
+// readSolutionProjects returns the project files a .sln lists, relative to
+// the directory of the solution. Solution folders are skipped.
+func readSolutionProjects(slnPath string) []string {
+	f, err := os.Open(slnPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var projects []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		m := dotnetSolutionProject.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if m == nil {
+			continue
+		}
+		p := path.Clean(strings.ReplaceAll(m[1], `\`, "/"))
+		switch path.Ext(p) {
+		case ".csproj", ".fsproj", ".vbproj":
+			projects = append(projects, p)
+		}
+	}
+	return projects
+}
+
+// readCentralPackageVersions reads Directory.Packages.props, which holds
+// the versions of projects using central package management.
+func readCentralPackageVersions(projectPath string) map[string]string {
+	versions := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(projectPath, "Directory.Packages.props"))
+	if err != nil {
+		return versions
+	}
+	var props msbuildProject
+	if err := xml.Unmarshal(data, &props); err != nil {
+		return versions
+	}
+	for _, pkg := range props.Versions {
+		versions[pkg.Include] = pkg.version()
+	}
+	return versions
+}
+
+// readPackagesConfig reads the packages.config of a project that predates
+// PackageReference items, where every version is exact.
+func readPackagesConfig(rel, projectPath string) []msbuildPackage {
+	data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	var config struct {
+		Packages []struct {
+			ID          string `xml:"id,attr"`
+			Version     string `xml:"version,attr"`
+			Development string `xml:"developmentDependency,attr"`
+		} `xml:"package"`
+	}
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+
+	packages := make([]msbuildPackage, 0, len(config.Packages))
+	for _, p := range config.Packages {
+		pkg := msbuildPackage{Include: p.ID, VersionAttr: "[" + p.Version + "]"}
+		if p.Development == "true" {
+			pkg.PrivateAssetsAttr = "all"
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// readNuGetLock adds a project's packages.lock.json to g. The lock lists
+// the resolved packages of each target framework.
+func readNuGetLock(path string, g *lockGraph) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var lock struct {
+		Dependencies map[string]map[string]struct {
+			Resolved     string            `json:"resolved"`
+			Dependencies map[string]string `json:"dependencies"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return
+	}
+
+	targets := make([]string, 0, len(lock.Dependencies))
+	for target := range lock.Dependencies {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		for name, pkg := range lock.Dependencies[target] {
+			g.addVersion(name, pkg.Resolved)
+			for dep := range pkg.Dependencies {
+				g.deps[name] = append(g.deps[name], dep)
+			}
+		}
+	}
+}
+
+// readDotnetSDKVersion returns the SDK version global.json pins.
+func readDotnetSDKVersion(projectPath string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, "global.json"))
+	if err != nil {
+		return ""
+	}
+	var global struct {
+		SDK struct {
+			Version string `json:"version"`
+		} `json:"sdk"`
+	}
+	if err := json.Unmarshal(data, &global); err != nil {
+		return ""
+	}
+	return global.SDK.Version
+}
@@ -0,0 +1,211 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var elixirFrameworks = map[string]bool{
+	"phoenix":           true,
+	"phoenix_live_view": true,
+	"ecto":              true,
+	"nerves":            true,
+	"absinthe":          true,
+}
+
+var (
+	mixElixirVersion = regexp.MustCompile(`\belixir:\s*"([^"]+)"`)
+	mixDepsFunction  = regexp.MustCompile(`defp?\s+deps\b[^\n]*\bdo\b`)
+	mixLockEntry     = regexp.MustCompile(`^\s*"([^"]+)":\s*\{:(\w+),\s*(?::"?([\w]+)"?,\s*"([^"]+)")?`)
+	mixLockDep       = regexp.MustCompile(`\{:(\w+),\s*"[^"]*",\s*\[`)
+)
+
+type elixirDetector struct{}
+
+func init() {
+	Register(elixirDetector{})
+}
+
+func (elixirDetector) Name() string { return "elixir" }
+
+func (elixirDetector) Files() []string { return []string{"mix.exs", "mix.lock"} }
+
+func (elixirDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "mix.exs"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+
+	elixir := models.Technology{Type: "runtime", Name: "Elixir", DetectedFrom: "mix.exs"}
+	if m := mixElixirVersion.FindStringSubmatch(content); m != nil {
+		elixir.Version = lowerBound(m[1])
+		elixir.DeclaredVersion = m[1]
+	}
+	techs := []models.Technology{elixir}
+
+	deps := readMixDeps(content)
+	scopes := make(map[string]models.DependencyScope, len(deps))
+	for _, dep := range deps {
+		scopes[dep.name] = dep.scope
+	}
+
+	lock := readMixLock(filepath.Join(projectPath, "mix.lock"))
+	for _, dep := range deps {
+		techType := "dependency"
+		if elixirFrameworks[dep.name] {
+			techType = "framework"
+		}
+		version := ""
+		if lock != nil {
+			version = lock.versions[dep.name]
+		}
+		techs = append(techs, models.Technology{
+			Type:            techType,
+			Name:            dep.name,
+			Version:         version,
+			DeclaredVersion: dep.constraint,
+			Scope:           dep.scope,
+			Source:          dep.source,
+			DetectedFrom:    "mix.exs",
+		})
+	}
+
+	if lock != nil {
+		techs = append(techs, lock.transitive(scopes)...)
+	}
+	return techs, nil
+}
+
+// readMixDeps reads the dependency tuples of the list the deps function of
+// mix.exs returns, such as {:phoenix, "~> 1.7"} or
+// {:credo, "~> 1.7", only: [:dev, :test], runtime: false}. Their syntax
+// is close enough to Ruby's for the Gemfile helpers to split it.
+func readMixDeps(content string) []declaredDependency {
+	loc := mixDepsFunction.FindStringIndex(content)
+	if loc == nil {
+		return nil
+	}
+	body := content[loc[1]:]
+	start := strings.Index(body, "[")
+	if start < 0 {
+		return nil
+	}
+
+	var deps []declaredDependency
+	seen := make(map[string]bool)
+	depth := 0
+	tuple := -1
+	for i := start; i < len(body); i++ {
+		switch body[i] {
+		case '"':
+			if end := strings.IndexByte(body[i+1:], '"'); end >= 0 {
+				i += end + 1
+			}
+		case '#':
+			if end := strings.IndexByte(body[i:], '\n'); end >= 0 {
+				i += end
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '{':
+			if depth == 1 && tuple < 0 {
+				tuple = i + 1
+			}
+		case '}':
+			if depth == 1 && tuple >= 0 {
+				if dep, ok := parseMixDep(body[tuple:i]); ok && !seen[dep.name] {
+					seen[dep.name] = true
+					deps = append(deps, dep)
+				}
+				tuple = -1
+			}
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].name < deps[j].name })
+	return deps
+}
+
+// parseMixDep reads the elements of a dependency tuple. A dependency only
+// for some environments is dev unless one of them is :prod.
+func parseMixDep(tuple string) (declaredDependency, bool) {
+	args := rubyArgs(tuple)
+	if len(args) == 0 || !strings.HasPrefix(args[0], ":") {
+		return declaredDependency{}, false
+	}
+	dep := declaredDependency{name: strings.TrimPrefix(args[0], ":"), scope: models.ScopeProd}
+
+	var ref string
+	for _, arg := range args[1:] {
+		if opt := rubyOption.FindStringSubmatch(arg); opt != nil {
+			value, _ := rubyString(opt[2])
+			switch opt[1] {
+			case "only":
+				dep.scope = models.ScopeDev
+				for _, env := range rubyArgs(strings.Trim(opt[2], "[]")) {
+					if strings.TrimSpace(env) == ":prod" {
+						dep.scope = models.ScopeProd
+					}
+				}
+			case "git", "github", "path":
+				dep.source = rubySource(opt[1], value)
+			case "branch", "tag", "ref":
+				ref = "?" + opt[1] + "=" + value
+			}
+			continue
+		}
+		if s, ok := rubyString(arg); ok && dep.constraint == "" {
+			dep.constraint = s
+		}
+	}
+	if strings.HasPrefix(dep.source, "git+") {
+		dep.source += ref
+	}
+	return dep, true
+}
+
+// readMixLock reads mix.lock, which maps each dependency to a tuple such as
+// {:hex, :phoenix, "1.7.10", hash, [:mix], deps, "hexpm", hash}. Git
+// dependencies are locked to a commit and get no version.
+func readMixLock(path string) *lockGraph {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	g := newLockGraph("mix.lock")
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		m := mixLockEntry.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := m[1]
+		version := ""
+		if m[2] == "hex" {
+			version = m[4]
+		}
+		g.addVersion(name, version)
+		for _, dep := range mixLockDep.FindAllStringSubmatch(line, -1) {
+			g.deps[name] = append(g.deps[name], dep[1])
+		}
+	}
+	return g
+}
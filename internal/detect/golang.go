@@ -0,0 +1,298 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type goDetector struct{}
+
+func init() {
+	Register(goDetector{})
+}
+
+func (goDetector) Name() string { return "go" }
+
+func (goDetector) Files() []string { return []string{"go.mod", "go.sum"} }
+
+// goFrameworks lists well-known modules recorded as frameworks when a
+// project requires them directly. Major version suffixes such as /v4 are
+// ignored.
+var goFrameworks = map[string]bool{
+	"github.com/gin-gonic/gin":               true,
+	"github.com/labstack/echo":               true,
+	"github.com/gofiber/fiber":               true,
+	"github.com/go-chi/chi":                  true,
+	"github.com/gorilla/mux":                 true,
+	"github.com/spf13/cobra":                 true,
+	"gorm.io/gorm":                           true,
+	"github.com/jinzhu/gorm":                 true,
+	"entgo.io/ent":                           true,
+	"google.golang.org/grpc":                 true,
+	"github.com/grpc-ecosystem/grpc-gateway": true,
+}
+
+var goMajorSuffix = regexp.MustCompile(`/v\d+$`)
+
+func (goDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "go.mod"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mod := parseGoMod(string(data))
+
+	techs := []models.Technology{{
+		Type:         "runtime",
+		Name:         "Go",
+		Version:      mod.goVersion,
+		DetectedFrom: "go.mod",
+	}}
+	if mod.toolchain != "" {
+		techs = append(techs, models.Technology{
+			Type:         "build",
+			Name:         "Go toolchain",
+			Version:      strings.TrimPrefix(mod.toolchain, "go"),
+			DetectedFrom: "go.mod",
+		})
+	}
+
+	required := make(map[string]bool)
+	for _, req := range mod.requires {
+		if required[req.path] {
+			continue
+		}
+		required[req.path] = true
+
+		techType := "dependency"
+		if !req.indirect && goFrameworks[goMajorSuffix.ReplaceAllString(req.path, "")] {
+			techType = "framework"
+		}
+		techs = append(techs, models.Technology{
+			Type:            techType,
+			Name:            req.path,
+			Version:         mod.resolve(req),
+			DeclaredVersion: req.version,
+			Transitive:      req.indirect,
+			DetectedFrom:    "go.mod",
+		})
+	}
+
+	return append(techs, goSumModules(projectPath, required)...), nil
+}
+
+type goRequire struct {
+	path     string
+	version  string
+	indirect bool
+}
+
+// goReplace is a replace directive. oldVersion is empty when every version
+// of the module is replaced; newVersion is empty when the replacement is a
+// local directory.
+type goReplace struct {
+	oldPath    string
+	oldVersion string
+	newPath    string
+	newVersion string
+}
+
+type goModFile struct {
+	goVersion string
+	toolchain string
+	requires  []goRequire
+	replaces  []goReplace
+}
+
+// resolve returns the version of a required module after replace
+// directives: the replacement's version, or the directory it is replaced
+// with.
+func (m *goModFile) resolve(req goRequire) string {
+	version := req.version
+	for _, r := range m.replaces {
+		if r.oldPath != req.path || (r.oldVersion != "" && r.oldVersion != req.version) {
+			continue
+		}
+		if r.newVersion == "" {
+			return r.newPath
+		}
+		version = r.newVersion
+		if r.oldVersion != "" {
+			// A replacement of this exact version wins over one for all versions.
+			break
+		}
+	}
+	return version
+}
+
+// parseGoMod reads the go, toolchain, require and replace directives of a
+// go.mod file, in their single-line and block forms.
+func parseGoMod(content string) *goModFile {
+	mod := &goModFile{}
+	block := ""
+	for _, line := range strings.Split(content, "\n") {
+		spec, comment, _ := strings.Cut(line, "//")
+		fields := strings.Fields(spec)
+		if len(fields) == 0 {
+			continue
+		}
+
+		verb := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case block == "" && strings.HasSuffix(fields[0], "("):
+			block = strings.TrimSuffix(fields[0], "(")
+			continue
+		case block == "":
+			verb, fields = fields[0], fields[1:]
+		}
+
+		switch verb {
+		case "go":
+			if len(fields) == 1 {
+				mod.goVersion = fields[0]
+			}
+		case "toolchain":
+			if len(fields) == 1 {
+				mod.toolchain = fields[0]
+			}
+		case "require":
+			if len(fields) == 2 {
+				mod.requires = append(mod.requires, goRequire{
+					path:     unquoteGoPath(fields[0]),
+					version:  fields[1],
+					indirect: strings.TrimSpace(comment) == "indirect" || strings.HasPrefix(strings.TrimSpace(comment), "indirect;"),
+				})
+			}
+		case "replace":
+			if r, ok := parseGoReplace(fields); ok {
+				mod.replaces = append(mod.replaces, r)
+			}
+		}
+	}
+	return mod
+}
+
+// parseGoReplace parses "old [version] => new [version]".
+func parseGoReplace(fields []string) (goReplace, bool) {
+	arrow := -1
+	for i, f := range fields {
+		if f == "=>" {
+			arrow = i
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(fields)-arrow-1 < 1 || len(fields)-arrow-1 > 2 {
+		return goReplace{}, false
+	}
+
+	r := goReplace{oldPath: unquoteGoPath(fields[0]), newPath: unquoteGoPath(fields[arrow+1])}
+	if arrow == 2 {
+		r.oldVersion = fields[1]
+	}
+	if len(fields) == arrow+3 {
+		r.newVersion = fields[arrow+2]
+	}
+	return r, true
+}
+
+func unquoteGoPath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// goSumModules returns the modules go.sum records that go.mod does not
+// require, as go.mod files before Go 1.17 omit most indirect dependencies.
+// Only modules whose content is checksummed, not just their go.mod, are part
+// of the build; for each, the highest version is reported.
+func goSumModules(projectPath string, required map[string]bool) []models.Technology {
+	f, err := os.Open(filepath.Join(projectPath, "go.sum"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	versions := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") || required[fields[0]] {
+			continue
+		}
+		if current, ok := versions[fields[0]]; !ok || compareGoVersions(fields[1], current) > 0 {
+			versions[fields[0]] = fields[1]
+		}
+	}
+
+	modules := make([]string, 0, len(versions))
+	for module := range versions {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	techs := make([]models.Technology, 0, len(modules))
+	for _, module := range modules {
+		techs = append(techs, models.Technology{
+			Type:         "dependency",
+			Name:         module,
+			Version:      versions[module],
+			Transitive:   true,
+			DetectedFrom: "go.sum",
+		})
+	}
+	return techs
+}
+
+// compareGoVersions compares two module versions such as v1.2.3,
+// v2.0.0+incompatible and v0.0.0-20230101000000-abcdef: release numbers
+// first, then a release over any pre-release, then pre-releases as text.
+func compareGoVersions(a, b string) int {
+	relA, preA := splitGoVersion(a)
+	relB, preB := splitGoVersion(b)
+	for i := 0; i < 3; i++ {
+		if relA[i] != relB[i] {
+			if relA[i] < relB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA < preB:
+		return -1
+	}
+	return 1
+}
+
+func splitGoVersion(v string) ([3]int, string) {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "+")
+	release, pre, _ := strings.Cut(v, "-")
+
+	var nums [3]int
+	for i, part := range strings.SplitN(release, ".", 3) {
+		nums[i], _ = strconv.Atoi(part)
+	}
+	return nums, pre
+}
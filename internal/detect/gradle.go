@@ -0,0 +1,386 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/toml"
+)
+
+const gradleCatalog = "gradle/libs.versions.toml"
+
+// gradleConfigurations lists the dependency configurations recognized in
+// build scripts.
+const gradleConfigurations = `implementation|api|compileOnly|runtimeOnly|testImplementation|testCompileOnly|testRuntimeOnly|` +
+	`androidTestImplementation|debugImplementation|releaseImplementation|kapt|ksp|annotationProcessor|classpath|compile|testCompile`
+
+var (
+	gradlePluginID      = regexp.MustCompile(`\bid\s*\(?\s*["']([^"']+)["']\s*\)?(?:\s*version\s*\(?\s*["']([^"']+)["']\s*\)?)?`)
+	gradleKotlinPlugin  = regexp.MustCompile(`\bkotlin\s*\(\s*["']([^"']+)["']\s*\)(?:\s*version\s*["']([^"']+)["'])?`)
+	gradleApplyPlugin   = regexp.MustCompile(`\bapply\s*\(?\s*plugin\s*[:=]\s*["']([^"']+)["']`)
+	gradleAliasPlugin   = regexp.MustCompile(`\balias\s*\(\s*libs\.plugins\.([\w.]+)\s*\)`)
+	gradleStringDep     = regexp.MustCompile(`\b(?:` + gradleConfigurations + `)\s*\(?\s*(?:(?:enforcedP|p)latform\s*\(\s*)?["']([^"':$]+):([^"':]+)(?::([^"'@:]+))?[^"']*["']`)
+	gradleMapDep        = regexp.MustCompile(`\bgroup\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["'](?:\s*,\s*version\s*[:=]\s*["']([^"']+)["'])?`)
+	gradleCatalogDep    = regexp.MustCompile(`\b(?:` + gradleConfigurations + `)\s*\(?\s*(?:(?:enforcedP|p)latform\s*\(\s*)?libs\.([\w.]+)`)
+	gradleJavaVersion   = regexp.MustCompile(`JavaVersion\.VERSION_(\d+(?:_\d+)?)`)
+	gradleCompatibility = regexp.MustCompile(`\b(?:source|target)Compatibility\s*=?\s*["']?(\d+(?:\.\d+)?)`)
+	gradleToolchain     = regexp.MustCompile(`(?:jvmToolchain\s*\(?\s*|JavaLanguageVersion\.of\s*\(\s*)(\d+)`)
+	gradleAndroidBlock  = regexp.MustCompile(`(?m)^\s*android\s*\{`)
+	gradleCompileSdk    = regexp.MustCompile(`\bcompileSdk(?:Version)?\s*[=(]?\s*(\d+)`)
+	gradleVariable      = regexp.MustCompile(`(?m)^\s*(?:ext\.|def\s+|val\s+|var\s+)?(\w+)\s*=\s*["']([^"'$]+)["']`)
+	gradleVariableRef   = regexp.MustCompile(`\$\{?(\w+)\}?`)
+	gradleWrapperDist   = regexp.MustCompile(`gradle-([\d.]+(?:-rc-\d+)?)-(?:bin|all)\.zip`)
+)
+
+type gradleDetector struct{}
+
+func init() {
+	Register(gradleDetector{})
+}
+
+func (gradleDetector) Name() string { return "gradle" }
+
+func (gradleDetector) Files() []string {
+	return []string{
+		"build.gradle",
+		"build.gradle.kts",
+		"settings.gradle",
+		"settings.gradle.kts",
+		gradleCatalog,
+		"*/build.gradle",
+		"*/build.gradle.kts",
+	}
+}
+
+func (gradleDetector) Includes(projectPath string, files []string) []string {
+	return []string{"gradle.properties", "gradle/wrapper/gradle-wrapper.properties"}
+}
+
+func (gradleDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	catalog := loadGradleCatalog(projectPath)
+	vars := readGradleProperties(filepath.Join(projectPath, "gradle.properties"))
+
+	var scripts []gradleScript
+	for _, f := range files {
+		if f == gradleCatalog {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(f)))
+		if err != nil {
+			continue
+		}
+		content := stripGradleComments(string(data))
+		for _, m := range gradleVariable.FindAllStringSubmatch(content, -1) {
+			if _, ok := vars[m[1]]; !ok {
+				vars[m[1]] = m[2]
+			}
+		}
+		scripts = append(scripts, gradleScript{path: f, content: content})
+	}
+
+	// The first script, in the order of Files, describes the root build.
+	source := gradleCatalog
+	if len(scripts) > 0 {
+		source = scripts[0].path
+	}
+
+	expand := func(s string) string {
+		return gradleVariableRef.ReplaceAllStringFunc(s, func(ref string) string {
+			name := strings.Trim(ref, "${}")
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+
+	c := newJVMCollector()
+	java := models.Technology{Type: "runtime", Name: "Java"}
+	usesJava := false
+
+	for _, script := range scripts {
+		content := script.content
+		source := script.path
+
+		if java.Version == "" {
+			java.Version = gradleJavaVersionOf(content)
+			if java.Version != "" {
+				java.DetectedFrom = source
+			}
+		}
+
+		for _, m := range gradlePluginID.FindAllStringSubmatch(content, -1) {
+			id := m[1]
+			if id == "java" || id == "java-library" || id == "application" {
+				usesJava = true
+			}
+			c.addPlugin(id, expand(m[2]), source)
+		}
+		for _, m := range gradleKotlinPlugin.FindAllStringSubmatch(content, -1) {
+			c.addPlugin("org.jetbrains.kotlin."+m[1], expand(m[2]), source)
+		}
+		for _, m := range gradleApplyPlugin.FindAllStringSubmatch(content, -1) {
+			if m[1] == "java" || m[1] == "java-library" || m[1] == "application" {
+				usesJava = true
+			}
+			c.addPlugin(m[1], "", source)
+		}
+		for _, m := range gradleAliasPlugin.FindAllStringSubmatch(content, -1) {
+			if p, ok := catalog.plugins[m[1]]; ok {
+				c.addPlugin(p.id, p.version, source)
+			}
+		}
+
+		if gradleAndroidBlock.MatchString(content) {
+			sdk := ""
+			if m := gradleCompileSdk.FindStringSubmatch(content); m != nil {
+				sdk = m[1]
+			}
+			c.add(models.Technology{Type: "framework", Name: "Android", Version: sdk, DetectedFrom: source})
+		}
+
+		for _, m := range gradleStringDep.FindAllStringSubmatch(content, -1) {
+			c.addDependency(m[1], m[2], expand(m[3]), source)
+		}
+		for _, m := range gradleMapDep.FindAllStringSubmatch(content, -1) {
+			c.addDependency(m[1], m[2], expand(m[3]), source)
+		}
+		for _, m := range gradleCatalogDep.FindAllStringSubmatch(content, -1) {
+			for _, lib := range catalog.resolve(m[1]) {
+				c.addDependency(lib.group, lib.name, lib.version, gradleCatalog)
+			}
+		}
+	}
+
+	techs := []models.Technology{{
+		Type:         "build",
+		Name:         "Gradle",
+		Version:      gradleWrapperVersion(projectPath),
+		DetectedFrom: source,
+	}}
+	if java.Version != "" || usesJava {
+		if java.DetectedFrom == "" {
+			java.DetectedFrom = source
+		}
+		techs = append([]models.Technology{java}, techs...)
+	}
+
+	return append(techs, c.techs...), nil
+}
+
+type gradleScript struct {
+	path    string
+	content string
+}
+
+type gradleLibrary struct {
+	group   string
+	name    string
+	version string
+}
+
+type gradlePlugin struct {
+	id      string
+	version string
+}
+
+// gradleVersionCatalog holds gradle/libs.versions.toml, keyed by accessor
+// name: an alias "spring-boot-starter" is reached as libs.spring.boot.starter.
+type gradleVersionCatalog struct {
+	libraries map[string]gradleLibrary
+	plugins   map[string]gradlePlugin
+	bundles   map[string][]string
+}
+
+// resolve returns the libraries behind a libs.<accessor> reference, which
+// may name a library or a bundle.
+func (c *gradleVersionCatalog) resolve(accessor string) []gradleLibrary {
+	accessor = strings.TrimSuffix(accessor, ".get")
+	if strings.HasPrefix(accessor, "bundles.") {
+		var libs []gradleLibrary
+		for _, alias := range c.bundles[strings.TrimPrefix(accessor, "bundles.")] {
+			if lib, ok := c.libraries[catalogAccessor(alias)]; ok {
+				libs = append(libs, lib)
+			}
+		}
+		return libs
+	}
+	if lib, ok := c.libraries[accessor]; ok {
+		return []gradleLibrary{lib}
+	}
+	return nil
+}
+
+func catalogAccessor(alias string) string {
+	return strings.NewReplacer("-", ".", "_", ".").Replace(alias)
+}
+
+func loadGradleCatalog(projectPath string) *gradleVersionCatalog {
+	catalog := &gradleVersionCatalog{
+		libraries: make(map[string]gradleLibrary),
+		plugins:   make(map[string]gradlePlugin),
+		bundles:   make(map[string][]string),
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(gradleCatalog)))
+	if err != nil {
+		return catalog
+	}
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return catalog
+	}
+
+	versions := make(map[string]string)
+	for name, v := range toml.Table(doc, "versions") {
+		versions[name] = catalogVersion(v, nil)
+	}
+
+	for alias, v := range toml.Table(doc, "libraries") {
+		var lib gradleLibrary
+		switch entry := v.(type) {
+		case string:
+			parts := strings.Split(entry, ":")
+			if len(parts) < 2 {
+				continue
+			}
+			lib = gradleLibrary{group: parts[0], name: parts[1]}
+			if len(parts) > 2 {
+				lib.version = parts[2]
+			}
+		case map[string]interface{}:
+			if module := toml.String(entry, "module"); module != "" {
+				lib.group, lib.name, _ = strings.Cut(module, ":")
+			} else {
+				lib.group, lib.name = toml.String(entry, "group"), toml.String(entry, "name")
+			}
+			lib.version = catalogVersion(entry["version"], versions)
+		}
+		catalog.libraries[catalogAccessor(alias)] = lib
+	}
+
+	for alias, v := range toml.Table(doc, "plugins") {
+		var plugin gradlePlugin
+		switch entry := v.(type) {
+		case string:
+			plugin.id, plugin.version, _ = strings.Cut(entry, ":")
+		case map[string]interface{}:
+			plugin.id = toml.String(entry, "id")
+			plugin.version = catalogVersion(entry["version"], versions)
+		}
+		catalog.plugins[catalogAccessor(alias)] = plugin
+	}
+
+	for name := range toml.Table(doc, "bundles") {
+		catalog.bundles[catalogAccessor(name)] = toml.Strings(toml.Table(doc, "bundles"), name)
+	}
+
+	return catalog
+}
+
+// catalogVersion reads a version given as a string, as { ref = "name" } or
+// as rich version constraints. Dotted keys make version.ref = "x" a table.
+func catalogVersion(v interface{}, versions map[string]string) string {
+	switch version := v.(type) {
+	case string:
+		return version
+	case map[string]interface{}:
+		if ref := toml.String(version, "ref"); ref != "" {
+			return versions[ref]
+		}
+		for _, key := range []string{"strictly", "require", "prefer"} {
+			if s := toml.String(version, key); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func readGradleProperties(path string) map[string]string {
+	props := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return props
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return props
+}
+
+func gradleJavaVersionOf(content string) string {
+	if m := gradleToolchain.FindStringSubmatch(content); m != nil {
+		return m[1]
+	}
+	if m := gradleJavaVersion.FindStringSubmatch(content); m != nil {
+		return strings.ReplaceAll(m[1], "_", ".")
+	}
+	if m := gradleCompatibility.FindStringSubmatch(content); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func gradleWrapperVersion(projectPath string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, "gradle", "wrapper", "gradle-wrapper.properties"))
+	if err != nil {
+		return ""
+	}
+	if m := gradleWrapperDist.FindStringSubmatch(string(data)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// stripGradleComments removes // and /* */ comments from a build script so
+// commented-out dependencies are not reported. Quoted strings are kept.
+func stripGradleComments(src string) string {
+	var b strings.Builder
+	quote := byte(0)
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(src) {
+				b.WriteByte(c)
+				i++
+				c = src[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				b.WriteByte('\n')
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/yaml"
+)
+
+type helmDetector struct{}
+
+func init() {
+	Register(helmDetector{})
+}
+
+func (helmDetector) Name() string { return "helm" }
+
+func (helmDetector) IndicatesProject() bool { return false }
+
+func (helmDetector) Files() []string {
+	return []string{
+		"Chart.yaml", "chart/Chart.yaml", "charts/*/Chart.yaml",
+		"helm/Chart.yaml", "helm/*/Chart.yaml", "deploy/helm/Chart.yaml", "deploy/helm/*/Chart.yaml",
+	}
+}
+
+func (helmDetector) Includes(projectPath string, files []string) []string {
+	var includes []string
+	for _, f := range files {
+		dir := path.Dir(f)
+		includes = append(includes, path.Join(dir, "Chart.lock"), path.Join(dir, "values.yaml"))
+	}
+	return includes
+}
+
+// Detect records Helm with the charts found as features, the charts they
+// depend on as dependencies, and the images their values.yaml sets as
+// containers.
+func (helmDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	helm := models.Technology{Type: "deploy", Name: "Helm", DetectedFrom: files[0]}
+	var deps []models.Technology
+	var images imageSet
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(projectPath, file))
+		if err != nil {
+			return nil, err
+		}
+		chart, err := yaml.Parse(data)
+		if err != nil {
+			continue
+		}
+		if name := yaml.String(yaml.Map(chart), "name"); name != "" {
+			helm.Features = append(helm.Features, name)
+		}
+
+		dir := path.Dir(file)
+		locked := readChartLock(filepath.Join(projectPath, dir, "Chart.lock"))
+		list, _ := yaml.Map(chart)["dependencies"].([]interface{})
+		for _, item := range list {
+			dep := yaml.Map(item)
+			name := yaml.String(dep, "name")
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			constraint := yaml.String(dep, "version")
+			version := pinnedVersion(constraint)
+			if v := locked[name]; v != "" {
+				version = v
+			}
+			source := yaml.String(dep, "repository")
+			if local := strings.TrimPrefix(source, "file://"); local != source {
+				source = "path+" + path.Join(dir, local)
+			}
+			deps = append(deps, models.Technology{
+				Type:            "dependency",
+				Name:            name,
+				Version:         version,
+				DeclaredVersion: constraint,
+				Scope:           models.ScopeProd,
+				Source:          source,
+				DetectedFrom:    file,
+			})
+		}
+
+		valuesFile := path.Join(dir, "values.yaml")
+		if data, err := os.ReadFile(filepath.Join(projectPath, valuesFile)); err == nil {
+			if values, err := yaml.Parse(data); err == nil {
+				for _, image := range valuesImages(values) {
+					images.add(image, models.ScopeProd, valuesFile)
+				}
+			}
+		}
+	}
+
+	techs := append([]models.Technology{helm}, deps...)
+	return append(techs, images.techs...), nil
+}
+
+// readChartLock returns the versions Chart.lock pins, keyed by chart name.
+func readChartLock(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lock, err := yaml.Parse(data)
+	if err != nil {
+		return nil
+	}
+	versions := make(map[string]string)
+	list, _ := yaml.Map(lock)["dependencies"].([]interface{})
+	for _, item := range list {
+		dep := yaml.Map(item)
+		versions[yaml.String(dep, "name")] = yaml.String(dep, "version")
+	}
+	return versions
+}
+
+// valuesImages returns the images set in chart values, written either as a
+// reference, image: nginx:1.25, or by convention as a map of registry,
+// repository and tag. Images without a tag default to the chart's
+// appVersion, which is not known here.
+func valuesImages(v interface{}) []string {
+	var images []string
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(node) {
+			if key != "image" {
+				images = append(images, valuesImages(node[key])...)
+				continue
+			}
+			switch image := node[key].(type) {
+			case string:
+				images = append(images, image)
+			case map[string]interface{}:
+				ref := yaml.String(image, "repository")
+				if ref == "" {
+					continue
+				}
+				if registry := yaml.String(image, "registry"); registry != "" {
+					ref = registry + "/" + ref
+				}
+				if tag := yaml.String(image, "tag"); tag != "" {
+					ref += ":" + tag
+				}
+				images = append(images, ref)
+			}
+		}
+	case []interface{}:
+		for _, item := range node {
+			images = append(images, valuesImages(item)...)
+		}
+	}
+	return images
+}
@@ -0,0 +1,159 @@
+package detect
+
+import (
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// jvmFramework describes a well-known JVM technology recognized from the
+// coordinates of a dependency or the ID of a build plugin. A group ending in
+// "." matches every group below it; when artifacts is set the artifact ID
+// must also start with one of its entries.
+type jvmFramework struct {
+	name      string
+	techType  string
+	groups    []string
+	artifacts []string
+	plugins   []string
+}
+
+var jvmFrameworks = []jvmFramework{
+	{
+		name:     "Spring Boot",
+		techType: "framework",
+		groups:   []string{"org.springframework.boot"},
+		plugins:  []string{"org.springframework.boot"},
+	},
+	{
+		// Other artifacts of the group, such as coroutines, are plain
+		// dependencies.
+		name:      "Kotlin",
+		techType:  "runtime",
+		groups:    []string{"org.jetbrains.kotlin"},
+		artifacts: []string{"kotlin-stdlib", "kotlin-gradle-plugin", "kotlin-maven-plugin"},
+		plugins:   []string{"org.jetbrains.kotlin.", "kotlin-android", "kotlin"},
+	},
+	{
+		name:     "Android",
+		techType: "framework",
+		groups:   []string{"com.android.tools.build", "androidx."},
+		plugins:  []string{"com.android.application", "com.android.library"},
+	},
+	{
+		// JUnit 4 is junit:junit, JUnit 5 is org.junit.jupiter:junit-jupiter.
+		name:      "JUnit",
+		techType:  "framework",
+		groups:    []string{"junit", "org.junit."},
+		artifacts: []string{"junit"},
+	},
+}
+
+// jvmFrameworkFor returns the framework a dependency belongs to, if any.
+func jvmFrameworkFor(group, artifact string) *jvmFramework {
+	for i, fw := range jvmFrameworks {
+		if !matchesAny(group, fw.groups) {
+			continue
+		}
+		if len(fw.artifacts) > 0 && !hasAnyPrefix(artifact, fw.artifacts) {
+			continue
+		}
+		return &jvmFrameworks[i]
+	}
+	return nil
+}
+
+// jvmFrameworkForPlugin returns the framework a build plugin belongs to.
+func jvmFrameworkForPlugin(id string) *jvmFramework {
+	for i, fw := range jvmFrameworks {
+		if matchesAny(id, fw.plugins) {
+			return &jvmFrameworks[i]
+		}
+	}
+	return nil
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if name == p || (strings.HasSuffix(p, ".") && strings.HasPrefix(name, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// jvmCollector gathers JVM technologies, keeping one record per framework
+// and dependency and preferring records that carry a version.
+type jvmCollector struct {
+	techs []models.Technology
+	index map[string]int
+}
+
+func newJVMCollector() *jvmCollector {
+	return &jvmCollector{index: make(map[string]int)}
+}
+
+func (c *jvmCollector) add(tech models.Technology) {
+	key := tech.Type + "\x00" + tech.Name
+	if i, ok := c.index[key]; ok {
+		if c.techs[i].Version == "" && tech.Version != "" {
+			c.techs[i].Version = tech.Version
+		}
+		return
+	}
+	c.index[key] = len(c.techs)
+	c.techs = append(c.techs, tech)
+}
+
+// addDependency records a dependency and, when it belongs to one, the
+// framework it implies.
+func (c *jvmCollector) addDependency(group, artifact, version, source string) {
+	if group == "" || artifact == "" {
+		return
+	}
+	if fw := jvmFrameworkFor(group, artifact); fw != nil {
+		c.add(models.Technology{
+			Type:         fw.techType,
+			Name:         fw.name,
+			Version:      frameworkVersion(fw, version),
+			DetectedFrom: source,
+		})
+	}
+	c.add(models.Technology{
+		Type:         "dependency",
+		Name:         group + ":" + artifact,
+		Version:      version,
+		DetectedFrom: source,
+	})
+}
+
+func (c *jvmCollector) addPlugin(id, version, source string) {
+	if fw := jvmFrameworkForPlugin(id); fw != nil {
+		c.add(models.Technology{
+			Type:         fw.techType,
+			Name:         fw.name,
+			Version:      frameworkVersion(fw, version),
+			DetectedFrom: source,
+		})
+	}
+}
+
+// frameworkVersion returns the version to report for a framework found
+// through one of its artifacts or plugins. Android artifacts and plugins
+// are versioned independently of the platform, whose version comes from
+// compileSdk instead.
+func frameworkVersion(fw *jvmFramework, version string) string {
+	if fw.name == "Android" {
+		return ""
+	}
+	return version
+}
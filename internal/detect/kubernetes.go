@@ -0,0 +1,114 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/yaml"
+)
+
+// kubernetesDirs are the directories searched for manifests.
+var kubernetesDirs = []string{"k8s", "kubernetes", "kube", "manifests", "deploy", "deployment", "deployments"}
+
+type kubernetesDetector struct{}
+
+func init() {
+	Register(kubernetesDetector{})
+}
+
+func (kubernetesDetector) Name() string { return "kubernetes" }
+
+func (kubernetesDetector) IndicatesProject() bool { return false }
+
+func (kubernetesDetector) Files() []string {
+	patterns := []string{"kustomization.yaml", "kustomization.yml"}
+	for _, dir := range kubernetesDirs {
+		patterns = append(patterns, dir+"/*.yaml", dir+"/*.yml")
+	}
+	return patterns
+}
+
+// Detect records Kubernetes when a file holds a manifest, with the kinds of
+// the resources it declares as features, and Kustomize when a
+// kustomization is found. Images of the containers the manifests run are
+// recorded as containers.
+func (kubernetesDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	var techs []models.Technology
+	var images imageSet
+	kinds := make(map[string]bool)
+	manifest := ""
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(projectPath, file))
+		if err != nil {
+			return nil, err
+		}
+		docs, err := yaml.ParseAll(data)
+		if err != nil {
+			continue
+		}
+
+		if base := path.Base(file); base == "kustomization.yaml" || base == "kustomization.yml" {
+			if len(techs) == 0 {
+				techs = append(techs, models.Technology{Type: "deploy", Name: "Kustomize", DetectedFrom: file})
+			}
+			continue
+		}
+		for _, doc := range docs {
+			m, _ := doc.(map[string]interface{})
+			kind := yaml.String(m, "kind")
+			if kind == "" || yaml.String(m, "apiVersion") == "" {
+				continue
+			}
+			if kind == "Kustomization" {
+				continue
+			}
+			if manifest == "" {
+				manifest = file
+			}
+			kinds[kind] = true
+			for _, image := range containerImages(m) {
+				images.add(image, models.ScopeProd, file)
+			}
+		}
+	}
+
+	if manifest != "" {
+		k8s := models.Technology{Type: "deploy", Name: "Kubernetes", DetectedFrom: manifest}
+		for kind := range kinds {
+			k8s.Features = append(k8s.Features, kind)
+		}
+		sort.Strings(k8s.Features)
+		techs = append([]models.Technology{k8s}, techs...)
+	}
+	return append(techs, images.techs...), nil
+}
+
+// containerImages returns the images of the containers and init containers
+// of the pod specs found anywhere below v, which covers pods, workload
+// templates and cron job templates alike.
+func containerImages(v interface{}) []string {
+	var images []string
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(node) {
+			if key == "containers" || key == "initContainers" {
+				list, _ := node[key].([]interface{})
+				for _, c := range list {
+					if image := yaml.String(yaml.Map(c), "image"); image != "" {
+						images = append(images, image)
+					}
+				}
+				continue
+			}
+			images = append(images, containerImages(node[key])...)
+		}
+	case []interface{}:
+		for _, item := range node {
+			images = append(images, containerImages(item)...)
+		}
+	}
+	return images
+}
@@ -0,0 +1,139 @@
+package detect
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var exactVersion = regexp.MustCompile(`^v?\d+(\.\d+)*(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// declaredDependency is a dependency as a manifest declares it. source is
+// set for dependencies fetched from elsewhere than the default registry,
+// in the format of models.Technology.Source.
+type declaredDependency struct {
+	name       string
+	constraint string
+	scope      models.DependencyScope
+	source     string
+}
+
+// lockGraph is the resolved dependency graph read from a lock file, keyed by
+// package name. When a lock file holds several versions of a package,
+// versions keeps the one installed at the top level or listed first.
+type lockGraph struct {
+	source   string
+	versions map[string]string
+	// specs maps "name@constraint" to the version the constraint resolved
+	// to, for lock files keyed by the requested constraint.
+	specs map[string]string
+	deps  map[string][]string
+}
+
+func newLockGraph(source string) *lockGraph {
+	return &lockGraph{
+		source:   source,
+		versions: make(map[string]string),
+		specs:    make(map[string]string),
+		deps:     make(map[string][]string),
+	}
+}
+
+// addVersion records the version of a package unless one is known already.
+func (g *lockGraph) addVersion(name, version string) {
+	if _, ok := g.versions[name]; !ok {
+		g.versions[name] = version
+	}
+}
+
+// resolve returns the exact version a direct dependency resolved to.
+func (g *lockGraph) resolve(name, constraint string) string {
+	if v, ok := g.specs[name+"@"+constraint]; ok {
+		return v
+	}
+	return g.versions[name]
+}
+
+// transitive returns the packages reachable from the direct dependencies,
+// which are left out. A package needed by any production dependency is
+// prod; otherwise one needed by a build dependency is build, and one
+// reached only from development dependencies is dev. Packages no
+// direct dependency reaches, such as those of other workspace members, are
+// not reported.
+func (g *lockGraph) transitive(direct map[string]models.DependencyScope) []models.Technology {
+	scopes := make(map[string]models.DependencyScope)
+	for _, scope := range []models.DependencyScope{models.ScopeProd, models.ScopeBuild, models.ScopeDev, ""} {
+		var queue []string
+		for name, s := range direct {
+			if s == scope {
+				queue = append(queue, name)
+			}
+		}
+		sort.Strings(queue)
+
+		visited := make(map[string]bool)
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for _, dep := range g.deps[name] {
+				if visited[dep] {
+					continue
+				}
+				visited[dep] = true
+				queue = append(queue, dep)
+				if _, ok := direct[dep]; ok {
+					continue
+				}
+				if _, ok := scopes[dep]; !ok {
+					scopes[dep] = scope
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	techs := make([]models.Technology, 0, len(names))
+	for _, name := range names {
+		techs = append(techs, models.Technology{
+			Type:         "dependency",
+			Name:         name,
+			Version:      g.versions[name],
+			Scope:        scopes[name],
+			Transitive:   true,
+			DetectedFrom: g.source,
+		})
+	}
+	return techs
+}
+
+// pinnedVersion returns the version a constraint allows when it allows only
+// one, stripped of a leading = or ==; otherwise "".
+func pinnedVersion(constraint string) string {
+	v := strings.TrimLeft(strings.TrimSpace(constraint), "=")
+	if exactVersion.MatchString(v) {
+		return v
+	}
+	return ""
+}
+
+// lowerBound returns the lowest version a constraint such as ">=3.10",
+// "^8.1", "~> 1.15" or ">=3.0.0 <4.0.0" allows, or "" when it has none.
+func lowerBound(constraint string) string {
+	clause := strings.TrimSpace(strings.Split(constraint, ",")[0])
+	fields := strings.Fields(strings.TrimLeft(clause, "<>=!~^ "))
+	if len(fields) == 0 {
+		return ""
+	}
+	version := strings.TrimSuffix(fields[0], ".*")
+	if version == "*" {
+		return ""
+	}
+	return version
+}
@@ -0,0 +1,173 @@
+package detect
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type mavenCoordinates struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type mavenPOM struct {
+	mavenCoordinates
+	Parent     mavenCoordinates `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []mavenCoordinates `xml:"dependencies>dependency"`
+	Managed      []mavenCoordinates `xml:"dependencyManagement>dependencies>dependency"`
+	Plugins      []struct {
+		mavenCoordinates
+		Configuration struct {
+			Source  string `xml:"source"`
+			Release string `xml:"release"`
+		} `xml:"configuration"`
+	} `xml:"build>plugins>plugin"`
+}
+
+// javaVersionProperties are the POM properties that set the Java version,
+// in order of precedence.
+var javaVersionProperties = []string{
+	"maven.compiler.release",
+	"java.version",
+	"maven.compiler.source",
+	"maven.compiler.target",
+}
+
+var mavenPropertyRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+type mavenDetector struct{}
+
+func init() {
+	Register(mavenDetector{})
+}
+
+func (mavenDetector) Name() string { return "maven" }
+
+func (mavenDetector) Files() []string { return []string{"pom.xml"} }
+
+func (mavenDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "pom.xml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pom mavenPOM
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, nil
+	}
+
+	props := map[string]string{
+		"project.version":        pom.Version,
+		"project.groupId":        pom.GroupID,
+		"project.parent.version": pom.Parent.Version,
+	}
+	for _, e := range pom.Properties.Entries {
+		props[e.XMLName.Local] = strings.TrimSpace(e.Value)
+	}
+	resolve := func(s string) string {
+		// Properties may refer to each other; a few passes cover real POMs.
+		for i := 0; i < 5 && strings.Contains(s, "${"); i++ {
+			s = mavenPropertyRef.ReplaceAllStringFunc(s, func(ref string) string {
+				if v, ok := props[ref[2:len(ref)-1]]; ok {
+					return v
+				}
+				return ref
+			})
+		}
+		return strings.TrimSpace(s)
+	}
+
+	java := models.Technology{Type: "runtime", Name: "Java", DetectedFrom: "pom.xml"}
+	for _, prop := range javaVersionProperties {
+		if v := resolve(props[prop]); v != "" && !strings.Contains(v, "${") {
+			java.Version = v
+			break
+		}
+	}
+	for _, plugin := range pom.Plugins {
+		if java.Version == "" && resolve(plugin.ArtifactID) == "maven-compiler-plugin" {
+			java.Version = resolve(plugin.Configuration.Release)
+			if java.Version == "" {
+				java.Version = resolve(plugin.Configuration.Source)
+			}
+		}
+	}
+
+	c := newJVMCollector()
+	c.add(java)
+	c.add(models.Technology{
+		Type:         "build",
+		Name:         "Maven",
+		DetectedFrom: "pom.xml",
+	})
+
+	groupID := pom.GroupID
+	if groupID == "" {
+		groupID = pom.Parent.GroupID
+	}
+	version := pom.Version
+	if version == "" {
+		version = pom.Parent.Version
+	}
+	if pom.ArtifactID != "" {
+		c.add(models.Technology{
+			Type:         "package",
+			Name:         resolve(groupID) + ":" + resolve(pom.ArtifactID),
+			Version:      resolve(version),
+			DetectedFrom: "pom.xml",
+		})
+	}
+
+	if pom.Parent.ArtifactID == "spring-boot-starter-parent" {
+		c.add(models.Technology{
+			Type:         "framework",
+			Name:         "Spring Boot",
+			Version:      resolve(pom.Parent.Version),
+			DetectedFrom: "pom.xml",
+		})
+	}
+
+	for _, plugin := range pom.Plugins {
+		version := resolve(plugin.Version)
+		switch resolve(plugin.ArtifactID) {
+		case "spring-boot-maven-plugin":
+			c.add(models.Technology{Type: "framework", Name: "Spring Boot", Version: version, DetectedFrom: "pom.xml"})
+		case "kotlin-maven-plugin":
+			c.add(models.Technology{Type: "runtime", Name: "Kotlin", Version: version, DetectedFrom: "pom.xml"})
+		case "android-maven-plugin":
+			c.add(models.Technology{Type: "framework", Name: "Android", DetectedFrom: "pom.xml"})
+		}
+	}
+
+	managed := make(map[string]string)
+	for _, dep := range pom.Managed {
+		managed[resolve(dep.GroupID)+":"+resolve(dep.ArtifactID)] = resolve(dep.Version)
+	}
+	for _, dep := range pom.Dependencies {
+		group, artifact := resolve(dep.GroupID), resolve(dep.ArtifactID)
+		depVersion := resolve(dep.Version)
+		if depVersion == "" {
+			depVersion = managed[group+":"+artifact]
+		}
+		// Versions inherited from a parent or BOM are unknown here; Spring
+		// Boot starters take the parent's version.
+		if depVersion == "" && group == "org.springframework.boot" && pom.Parent.ArtifactID == "spring-boot-starter-parent" {
+			depVersion = resolve(pom.Parent.Version)
+		}
+		c.addDependency(group, artifact, depVersion, "pom.xml")
+	}
+
+	return c.techs, nil
+}
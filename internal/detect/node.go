@@ -0,0 +1,113 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var nodeFrameworks = map[string]bool{
+	"next":          true,
+	"react":         true,
+	"vue":           true,
+	"@angular/core": true,
+	"express":       true,
+	"nestjs":        true,
+	"svelte":        true,
+}
+
+type nodeDetector struct{}
+
+func init() {
+	Register(nodeDetector{})
+}
+
+func (nodeDetector) Name() string { return "node" }
+
+func (nodeDetector) Files() []string {
+	return []string{"package.json", "package-lock.json", "npm-shrinkwrap.json", "pnpm-lock.yaml", "yarn.lock"}
+}
+
+func (nodeDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if os.IsNotExist(err) {
+		// A lock file without a manifest, left over from an old install.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, nil
+	}
+
+	techs := []models.Technology{{
+		Type:         "runtime",
+		Name:         "Node.js",
+		DetectedFrom: "package.json",
+	}}
+
+	scopes := make(map[string]models.DependencyScope)
+	var deps []declaredDependency
+	add := func(m map[string]string, scope models.DependencyScope) {
+		for name, constraint := range m {
+			if _, ok := scopes[name]; ok {
+				continue
+			}
+			scopes[name] = scope
+			deps = append(deps, declaredDependency{name: name, constraint: constraint, scope: scope})
+		}
+	}
+	add(pkg.Dependencies, models.ScopeProd)
+	add(pkg.OptionalDependencies, models.ScopeProd)
+	add(pkg.DevDependencies, models.ScopeDev)
+	sort.Slice(deps, func(i, j int) bool { return deps[i].name < deps[j].name })
+
+	lock := readNodeLock(projectPath, files)
+
+	for _, dep := range deps {
+		if nodeFrameworks[dep.name] {
+			techs = append(techs, nodeTechnology("framework", dep, lock))
+		}
+	}
+	for _, dep := range deps {
+		if !nodeFrameworks[dep.name] {
+			techs = append(techs, nodeTechnology("dependency", dep, lock))
+		}
+	}
+
+	if lock != nil {
+		techs = append(techs, lock.transitive(scopes)...)
+	}
+	return techs, nil
+}
+
+// nodeTechnology records a dependency from package.json with the version
+// the lock file resolved it to. Without a lock file, only a constraint
+// naming a single version, ignoring ^ and ~, gives one.
+func nodeTechnology(techType string, dep declaredDependency, lock *lockGraph) models.Technology {
+	version := pinnedVersion(strings.Trim(dep.constraint, "^~"))
+	if lock != nil {
+		if v := lock.resolve(dep.name, dep.constraint); v != "" {
+			version = v
+		}
+	}
+	return models.Technology{
+		Type:            techType,
+		Name:            dep.name,
+		Version:         version,
+		DeclaredVersion: dep.constraint,
+		Scope:           dep.scope,
+		DetectedFrom:    "package.json",
+	}
+}
@@ -0,0 +1,286 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/yaml"
+)
+
+// nodeLockFiles lists the lock files read for a Node.js project, in order
+// of preference when several are present.
+var nodeLockFiles = []struct {
+	name string
+	read func(path, source string) *lockGraph
+}{
+	{"package-lock.json", readPackageLock},
+	{"npm-shrinkwrap.json", readPackageLock},
+	{"pnpm-lock.yaml", readPnpmLock},
+	{"yarn.lock", readYarnLock},
+}
+
+// readNodeLock returns the graph of the first lock file among files that
+// can be read, or nil.
+func readNodeLock(projectPath string, files []string) *lockGraph {
+	present := make(map[string]bool, len(files))
+	for _, f := range files {
+		present[f] = true
+	}
+	for _, lf := range nodeLockFiles {
+		if !present[lf.name] {
+			continue
+		}
+		if g := lf.read(filepath.Join(projectPath, lf.name), lf.name); g != nil {
+			return g
+		}
+	}
+	return nil
+}
+
+type npmLockV1Dependency struct {
+	Version      string                         `json:"version"`
+	Requires     map[string]string              `json:"requires"`
+	Dependencies map[string]npmLockV1Dependency `json:"dependencies"`
+}
+
+// readPackageLock reads package-lock.json and npm-shrinkwrap.json. Version 2
+// and 3 lock files list every installed package under "packages", keyed by
+// its node_modules path; version 1 nests them under "dependencies".
+func readPackageLock(path, source string) *lockGraph {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var lock struct {
+		Packages map[string]struct {
+			Version              string            `json:"version"`
+			Link                 bool              `json:"link"`
+			Dependencies         map[string]string `json:"dependencies"`
+			OptionalDependencies map[string]string `json:"optionalDependencies"`
+		} `json:"packages"`
+		Dependencies map[string]npmLockV1Dependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+
+	g := newLockGraph(source)
+	if len(lock.Packages) > 0 {
+		for key, pkg := range lock.Packages {
+			i := strings.LastIndex(key, "node_modules/")
+			if i < 0 || pkg.Link {
+				// The root package and workspace members.
+				continue
+			}
+			name := key[i+len("node_modules/"):]
+			if key == "node_modules/"+name {
+				g.versions[name] = pkg.Version
+			} else {
+				g.addVersion(name, pkg.Version)
+			}
+			for dep := range pkg.Dependencies {
+				g.deps[name] = append(g.deps[name], dep)
+			}
+			for dep := range pkg.OptionalDependencies {
+				g.deps[name] = append(g.deps[name], dep)
+			}
+		}
+		return g
+	}
+
+	// Top-level entries are installed at the root; the first pass records
+	// their versions before nested copies are seen.
+	for name, dep := range lock.Dependencies {
+		g.versions[name] = dep.Version
+	}
+	var walk func(deps map[string]npmLockV1Dependency)
+	walk = func(deps map[string]npmLockV1Dependency) {
+		for name, dep := range deps {
+			g.addVersion(name, dep.Version)
+			for req := range dep.Requires {
+				g.deps[name] = append(g.deps[name], req)
+			}
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return g
+}
+
+// readYarnLock reads yarn.lock. Yarn 1 uses its own format; later versions
+// write YAML with a __metadata entry. Both key each entry by the
+// comma-separated "name@constraint" specs it satisfies.
+func readYarnLock(path, source string) *lockGraph {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	if strings.HasPrefix(string(data), "__metadata:") || strings.Contains(string(data), "\n__metadata:") {
+		return readYarnBerryLock(data, source)
+	}
+
+	g := newLockGraph(source)
+	var specs []string
+	name := ""
+	inDeps := false
+
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch indent := len(line) - len(strings.TrimLeft(line, " ")); {
+		case indent == 0:
+			specs = nil
+			for _, spec := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				specs = append(specs, strings.Trim(strings.TrimSpace(spec), `"`))
+			}
+			name = yarnSpecName(specs[0])
+			inDeps = false
+		case indent == 2:
+			inDeps = trimmed == "dependencies:" || trimmed == "optionalDependencies:"
+			if strings.HasPrefix(trimmed, "version ") {
+				version := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "version ")), `"`)
+				for _, spec := range specs {
+					g.specs[spec] = version
+				}
+				g.addVersion(name, version)
+			}
+		case inDeps:
+			dep := strings.Trim(strings.Fields(trimmed)[0], `"`)
+			g.deps[name] = append(g.deps[name], dep)
+		}
+	}
+	return g
+}
+
+func readYarnBerryLock(data []byte, source string) *lockGraph {
+	doc, err := yaml.Parse(data)
+	if err != nil {
+		return nil
+	}
+
+	g := newLockGraph(source)
+	for key, v := range yaml.Map(doc) {
+		entry, ok := v.(map[string]interface{})
+		if key == "__metadata" || !ok || strings.Contains(key, "@workspace:") {
+			continue
+		}
+		specs := strings.Split(key, ",")
+		name := yarnSpecName(strings.TrimSpace(specs[0]))
+		version := yaml.String(entry, "version")
+		for _, spec := range specs {
+			spec = strings.TrimSpace(spec)
+			g.specs[spec] = version
+			// package.json constraints carry no protocol; "npm:" is the default.
+			g.specs[strings.Replace(spec, "@npm:", "@", 1)] = version
+		}
+		g.addVersion(name, version)
+		for dep := range yaml.Map(entry, "dependencies") {
+			g.deps[name] = append(g.deps[name], dep)
+		}
+	}
+	return g
+}
+
+// yarnSpecName returns the package name of a "name@constraint" spec; scoped
+// names start with @ themselves.
+func yarnSpecName(spec string) string {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		spec = spec[:i]
+	}
+	// Yarn 2+ specs may carry a protocol, as in "name@npm:^1.0.0".
+	if i := strings.Index(spec[1:], "@"); i >= 0 {
+		spec = spec[:i+1]
+	}
+	return spec
+}
+
+// readPnpmLock reads pnpm-lock.yaml. Version 9 lists the root project's
+// dependencies under importers["."], with dependency edges in "snapshots";
+// older versions list them at the top level and under "packages".
+func readPnpmLock(path, source string) *lockGraph {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	doc, err := yaml.Parse(data)
+	if err != nil {
+		return nil
+	}
+
+	g := newLockGraph(source)
+	root := yaml.Map(doc, "importers", ".")
+	if root == nil {
+		root = yaml.Map(doc)
+	}
+	for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies"} {
+		for name, v := range yaml.Map(root, section) {
+			var version, specifier string
+			switch entry := v.(type) {
+			case string:
+				version = entry
+				specifier = yaml.String(yaml.Map(root, "specifiers"), name)
+			case map[string]interface{}:
+				version = yaml.String(entry, "version")
+				specifier = yaml.String(entry, "specifier")
+			}
+			version = pnpmVersion(version)
+			g.specs[name+"@"+specifier] = version
+			g.versions[name] = version
+		}
+	}
+
+	v5 := strings.HasPrefix(yaml.String(yaml.Map(doc), "lockfileVersion"), "5")
+	for _, section := range []string{"packages", "snapshots"} {
+		for key, v := range yaml.Map(doc, section) {
+			name, version := pnpmPackageKey(key, v5)
+			g.addVersion(name, version)
+			entry, _ := v.(map[string]interface{})
+			for _, deps := range []string{"dependencies", "optionalDependencies"} {
+				for dep := range yaml.Map(entry, deps) {
+					g.deps[name] = append(g.deps[name], dep)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// pnpmVersion strips the peer dependency suffix pnpm appends to versions,
+// "(react@18.2.0)" or, in version 5, "_react@18.2.0". Linked workspace
+// packages have no version.
+func pnpmVersion(version string) string {
+	if strings.HasPrefix(version, "link:") {
+		return ""
+	}
+	if i := strings.IndexAny(version, "(_"); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+// pnpmPackageKey splits a package key: "/name/1.0.0" in version 5,
+// "/name@1.0.0" in version 6 and "name@1.0.0" in version 9.
+func pnpmPackageKey(key string, v5 bool) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if v5 {
+		if i := strings.LastIndex(key, "/"); i > 0 {
+			return key[:i], pnpmVersion(key[i+1:])
+		}
+		return key, ""
+	}
+	if i := strings.Index(key, "("); i >= 0 {
+		key = key[:i]
+	}
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
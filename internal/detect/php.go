@@ -0,0 +1,137 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var composerFrameworks = map[string]bool{
+	"laravel/framework":        true,
+	"laravel/lumen-framework":  true,
+	"symfony/framework-bundle": true,
+	"slim/slim":                true,
+	"cakephp/cakephp":          true,
+	"yiisoft/yii2":             true,
+	"laminas/laminas-mvc":      true,
+	"codeigniter4/framework":   true,
+	"drupal/core":              true,
+	"phpunit/phpunit":          true,
+}
+
+type phpDetector struct{}
+
+func init() {
+	Register(phpDetector{})
+}
+
+func (phpDetector) Name() string { return "php" }
+
+func (phpDetector) Files() []string { return []string{"composer.json", "composer.lock"} }
+
+func (phpDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "composer.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil
+	}
+
+	php := models.Technology{Type: "runtime", Name: "PHP", DetectedFrom: "composer.json"}
+	if constraint, ok := manifest.Require["php"]; ok {
+		php.Version = lowerBound(constraint)
+		php.DeclaredVersion = constraint
+	}
+	techs := []models.Technology{php}
+
+	scopes := make(map[string]models.DependencyScope)
+	var deps []declaredDependency
+	add := func(m map[string]string, scope models.DependencyScope) {
+		for name, constraint := range m {
+			// Platform packages such as php and ext-json have no vendor.
+			if !strings.Contains(name, "/") {
+				continue
+			}
+			if _, ok := scopes[name]; ok {
+				continue
+			}
+			scopes[name] = scope
+			deps = append(deps, declaredDependency{name: name, constraint: constraint, scope: scope})
+		}
+	}
+	add(manifest.Require, models.ScopeProd)
+	add(manifest.RequireDev, models.ScopeDev)
+	sort.Slice(deps, func(i, j int) bool { return deps[i].name < deps[j].name })
+
+	lock := readComposerLock(filepath.Join(projectPath, "composer.lock"))
+	for _, dep := range deps {
+		techType := "dependency"
+		if composerFrameworks[dep.name] {
+			techType = "framework"
+		}
+		version := pinnedVersion(dep.constraint)
+		if lock != nil {
+			if v := lock.versions[dep.name]; v != "" {
+				version = v
+			}
+		}
+		techs = append(techs, models.Technology{
+			Type:            techType,
+			Name:            dep.name,
+			Version:         version,
+			DeclaredVersion: dep.constraint,
+			Scope:           dep.scope,
+			DetectedFrom:    "composer.json",
+		})
+	}
+
+	if lock != nil {
+		techs = append(techs, lock.transitive(scopes)...)
+	}
+	return techs, nil
+}
+
+// readComposerLock reads composer.lock, which lists the installed packages
+// and their requirements under "packages" and "packages-dev".
+func readComposerLock(path string) *lockGraph {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	type composerPackage struct {
+		Name    string            `json:"name"`
+		Version string            `json:"version"`
+		Require map[string]string `json:"require"`
+	}
+	var lock struct {
+		Packages    []composerPackage `json:"packages"`
+		PackagesDev []composerPackage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+
+	g := newLockGraph("composer.lock")
+	for _, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		g.addVersion(pkg.Name, pkg.Version)
+		for dep := range pkg.Require {
+			if strings.Contains(dep, "/") {
+				g.deps[pkg.Name] = append(g.deps[pkg.Name], dep)
+			}
+		}
+	}
+	return g
+}
@@ -0,0 +1,556 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/toml"
+)
+
+// pythonFrameworks maps normalized distribution names to the framework they
+// are reported as.
+var pythonFrameworks = map[string]string{
+	"django":     "Django",
+	"fastapi":    "FastAPI",
+	"flask":      "Flask",
+	"torch":      "PyTorch",
+	"tensorflow": "TensorFlow",
+	"streamlit":  "Streamlit",
+}
+
+// pythonBuildBackends maps build-backend module prefixes from pyproject.toml
+// to the tool they belong to.
+var pythonBuildBackends = []struct {
+	prefix string
+	tool   string
+}{
+	{"poetry.core", "Poetry"},
+	{"hatchling", "Hatch"},
+	{"setuptools", "setuptools"},
+	{"flit_core", "Flit"},
+	{"pdm.", "PDM"},
+	{"maturin", "maturin"},
+	{"uv_build", "uv"},
+}
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+type pythonDetector struct{}
+
+func init() {
+	Register(pythonDetector{})
+}
+
+func (pythonDetector) Name() string { return "python" }
+
+func (pythonDetector) Files() []string {
+	return []string{
+		"pyproject.toml",
+		"setup.cfg",
+		"setup.py",
+		"Pipfile",
+		"requirements.txt",
+		"requirements-*.txt",
+		"requirements/*.txt",
+		"poetry.lock",
+		"uv.lock",
+		"Pipfile.lock",
+	}
+}
+
+// Includes returns .python-version and the files the requirements files
+// include with -r.
+func (pythonDetector) Includes(projectPath string, files []string) []string {
+	includes := []string{".python-version"}
+	visited := make(map[string]bool)
+	for _, f := range files {
+		if strings.HasSuffix(f, ".txt") {
+			includes = append(includes, requirementIncludes(projectPath, f, visited)...)
+		}
+	}
+	return includes
+}
+
+func (pythonDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	c := newPythonCollector()
+
+	for _, f := range files {
+		path := filepath.Join(projectPath, filepath.FromSlash(f))
+		switch {
+		case f == "pyproject.toml":
+			c.readPyproject(path)
+		case f == "setup.cfg":
+			c.readSetupCfg(path)
+		case f == "setup.py":
+			c.addTool("setuptools", f)
+		case f == "Pipfile":
+			c.readPipfile(path)
+		case strings.HasSuffix(f, ".txt"):
+			scope := models.ScopeProd
+			if pythonDevGroup(filepath.Base(f)) {
+				scope = models.ScopeDev
+			}
+			for _, req := range readRequirements(path, make(map[string]bool)) {
+				c.addRequirement(req, scope, f)
+			}
+		}
+	}
+
+	// Lock files only pin the versions of declared dependencies.
+	for _, f := range files {
+		path := filepath.Join(projectPath, f)
+		switch f {
+		case "poetry.lock":
+			c.addTool("Poetry", f)
+			c.pin(readTOMLLock(path))
+		case "uv.lock":
+			c.addTool("uv", f)
+			c.pin(readTOMLLock(path))
+		case "Pipfile.lock":
+			c.pin(readPipfileLock(path))
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(projectPath, ".python-version")); err == nil {
+		if v := strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0]); v != "" {
+			c.python.Version = v
+			c.python.DetectedFrom = ".python-version"
+		}
+	}
+	if c.python.DetectedFrom == "" && len(files) > 0 {
+		c.python.DetectedFrom = files[0]
+	}
+
+	return c.technologies(), nil
+}
+
+// pythonCollector gathers the technologies of a Python project, keeping one
+// record per distribution under its normalized name.
+type pythonCollector struct {
+	python  models.Technology
+	tools   []models.Technology
+	project *models.Technology
+	deps    []models.Technology
+	index   map[string]int
+}
+
+func newPythonCollector() *pythonCollector {
+	return &pythonCollector{
+		python: models.Technology{Type: "runtime", Name: "Python"},
+		index:  make(map[string]int),
+	}
+}
+
+// normalizePythonName applies the PEP 503 normalization, so Django,
+// django and DJANGO refer to the same distribution.
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparators.ReplaceAllString(name, "-"))
+}
+
+// addRequirement records a dependency. A dependency declared both for
+// development and for production is prod.
+func (c *pythonCollector) addRequirement(req pythonRequirement, scope models.DependencyScope, source string) {
+	key := normalizePythonName(req.name)
+	if i, ok := c.index[key]; ok {
+		dep := &c.deps[i]
+		if dep.DeclaredVersion == "" {
+			dep.DeclaredVersion = req.constraint
+			dep.Version = pinnedVersion(req.constraint)
+		}
+		if scope == models.ScopeProd {
+			dep.Scope = scope
+		}
+		return
+	}
+
+	tech := models.Technology{
+		Type:            "dependency",
+		Name:            req.name,
+		Version:         pinnedVersion(req.constraint),
+		DeclaredVersion: req.constraint,
+		Scope:           scope,
+		DetectedFrom:    source,
+	}
+	if fw, ok := pythonFrameworks[key]; ok {
+		tech.Type = "framework"
+		tech.Name = fw
+	}
+	c.index[key] = len(c.deps)
+	c.deps = append(c.deps, tech)
+}
+
+func (c *pythonCollector) addTool(name, source string) {
+	for _, tool := range c.tools {
+		if tool.Name == name {
+			return
+		}
+	}
+	c.tools = append(c.tools, models.Technology{Type: "build", Name: name, DetectedFrom: source})
+}
+
+func (c *pythonCollector) setProject(name, version, source string) {
+	if c.project != nil || name == "" {
+		return
+	}
+	if strings.Contains(version, ":") {
+		// setup.cfg can read the version from code, as in "attr: pkg.__version__".
+		version = ""
+	}
+	c.project = &models.Technology{Type: "package", Name: name, Version: version, DetectedFrom: source}
+}
+
+// setPythonVersion records the Python version from a constraint such as
+// ">=3.10" or "^3.11", keeping the first one found.
+func (c *pythonCollector) setPythonVersion(constraint, source string) {
+	if c.python.Version != "" {
+		return
+	}
+	version := lowerBound(constraint)
+	if version == "" {
+		return
+	}
+	c.python.Version = version
+	c.python.DetectedFrom = source
+}
+
+// pin replaces declared constraints with the exact versions of a lock file.
+func (c *pythonCollector) pin(locked map[string]string) {
+	for key, i := range c.index {
+		if v, ok := locked[key]; ok && v != "" {
+			c.deps[i].Version = v
+		}
+	}
+}
+
+func (c *pythonCollector) technologies() []models.Technology {
+	techs := append([]models.Technology{c.python}, c.tools...)
+	if c.project != nil {
+		techs = append(techs, *c.project)
+	}
+	for _, tech := range c.deps {
+		if tech.Type == "framework" {
+			techs = append(techs, tech)
+		}
+	}
+	for _, tech := range c.deps {
+		if tech.Type != "framework" {
+			techs = append(techs, tech)
+		}
+	}
+	return techs
+}
+
+func (c *pythonCollector) addRequirements(specs []string, scope models.DependencyScope, source string) {
+	for _, spec := range specs {
+		if req, ok := parseRequirement(spec); ok {
+			c.addRequirement(req, scope, source)
+		}
+	}
+}
+
+// pythonDevGroup reports whether an extra, dependency group or requirements
+// file is meant for development, such as "dev", "test" or "docs".
+func pythonDevGroup(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range []string{"dev", "test", "lint", "doc", "typing"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func pythonGroupScope(name string) models.DependencyScope {
+	if pythonDevGroup(name) {
+		return models.ScopeDev
+	}
+	return models.ScopeProd
+}
+
+func (c *pythonCollector) readPyproject(path string) {
+	const source = "pyproject.toml"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return
+	}
+
+	if backend := toml.String(toml.Table(doc, "build-system"), "build-backend"); backend != "" {
+		for _, b := range pythonBuildBackends {
+			if strings.HasPrefix(backend, b.prefix) {
+				c.addTool(b.tool, source)
+				break
+			}
+		}
+	}
+
+	// PEP 621 metadata, optional dependencies and PEP 735 dependency groups.
+	if project := toml.Table(doc, "project"); project != nil {
+		c.setProject(toml.String(project, "name"), toml.String(project, "version"), source)
+		c.setPythonVersion(toml.String(project, "requires-python"), source)
+		c.addRequirements(toml.Strings(project, "dependencies"), models.ScopeProd, source)
+		optional := toml.Table(project, "optional-dependencies")
+		for _, extra := range sortedKeys(optional) {
+			c.addRequirements(toml.Strings(optional, extra), pythonGroupScope(extra), source)
+		}
+	}
+	// Dependency groups are not installed with the package.
+	groups := toml.Table(doc, "dependency-groups")
+	for _, group := range sortedKeys(groups) {
+		c.addRequirements(toml.Strings(groups, group), models.ScopeDev, source)
+	}
+
+	if poetry := toml.Table(doc, "tool", "poetry"); poetry != nil {
+		c.addTool("Poetry", source)
+		c.setProject(toml.String(poetry, "name"), toml.String(poetry, "version"), source)
+		c.addPoetryDependencies(toml.Table(poetry, "dependencies"), models.ScopeProd, source)
+		c.addPoetryDependencies(toml.Table(poetry, "dev-dependencies"), models.ScopeDev, source)
+		poetryGroups := toml.Table(poetry, "group")
+		for _, group := range sortedKeys(poetryGroups) {
+			scope := models.ScopeDev
+			if group == "main" {
+				scope = models.ScopeProd
+			}
+			c.addPoetryDependencies(toml.Table(poetryGroups, group, "dependencies"), scope, source)
+		}
+	}
+
+	if hatch := toml.Table(doc, "tool", "hatch"); hatch != nil {
+		c.addTool("Hatch", source)
+		envs := toml.Table(hatch, "envs")
+		for _, env := range sortedKeys(envs) {
+			c.addRequirements(toml.Strings(toml.Table(envs, env), "dependencies"), models.ScopeDev, source)
+			c.addRequirements(toml.Strings(toml.Table(envs, env), "extra-dependencies"), models.ScopeDev, source)
+		}
+	}
+
+	if uv := toml.Table(doc, "tool", "uv"); uv != nil {
+		c.addTool("uv", source)
+		c.addRequirements(toml.Strings(uv, "dev-dependencies"), models.ScopeDev, source)
+	}
+
+	if pdm := toml.Table(doc, "tool", "pdm"); pdm != nil {
+		c.addTool("PDM", source)
+		dev := toml.Table(pdm, "dev-dependencies")
+		for _, group := range sortedKeys(dev) {
+			c.addRequirements(toml.Strings(dev, group), models.ScopeDev, source)
+		}
+	}
+}
+
+// addPoetryDependencies reads a Poetry dependency table, whose values are a
+// version constraint or a table with a version, git or path source.
+func (c *pythonCollector) addPoetryDependencies(deps map[string]interface{}, scope models.DependencyScope, source string) {
+	for _, name := range sortedKeys(deps) {
+		var constraint string
+		switch v := deps[name].(type) {
+		case string:
+			constraint = v
+		case map[string]interface{}:
+			constraint = toml.String(v, "version")
+		case []interface{}:
+			// Multiple constraints apply to different markers; use the first.
+			if len(v) > 0 {
+				if t, ok := v[0].(map[string]interface{}); ok {
+					constraint = toml.String(t, "version")
+				}
+			}
+		}
+		if strings.EqualFold(name, "python") {
+			c.setPythonVersion(constraint, source)
+			continue
+		}
+		c.addRequirement(pythonRequirement{name: name, constraint: poetryVersion(constraint)}, scope, source)
+	}
+}
+
+// poetryVersion normalizes a Poetry constraint, in which a bare version
+// means an exact match.
+func poetryVersion(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "*" {
+		return ""
+	}
+	return constraint
+}
+
+func (c *pythonCollector) readPipfile(path string) {
+	const source = "Pipfile"
+	c.addTool("Pipenv", source)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return
+	}
+
+	requires := toml.Table(doc, "requires")
+	if v := toml.String(requires, "python_full_version"); v != "" {
+		c.setPythonVersion(v, source)
+	}
+	c.setPythonVersion(toml.String(requires, "python_version"), source)
+
+	sections := []struct {
+		name  string
+		scope models.DependencyScope
+	}{
+		{"packages", models.ScopeProd},
+		{"dev-packages", models.ScopeDev},
+	}
+	for _, section := range sections {
+		deps := toml.Table(doc, section.name)
+		for _, name := range sortedKeys(deps) {
+			var constraint string
+			switch v := deps[name].(type) {
+			case string:
+				constraint = v
+			case map[string]interface{}:
+				constraint = toml.String(v, "version")
+			}
+			if constraint == "*" {
+				constraint = ""
+			}
+			c.addRequirement(pythonRequirement{name: name, constraint: requirementVersion(constraint)}, section.scope, source)
+		}
+	}
+}
+
+func (c *pythonCollector) readSetupCfg(path string) {
+	const source = "setup.cfg"
+	sections := readINI(path)
+	if len(sections) == 0 {
+		return
+	}
+	c.addTool("setuptools", source)
+
+	metadata := sections["metadata"]
+	c.setProject(metadata["name"], metadata["version"], source)
+
+	options := sections["options"]
+	c.setPythonVersion(options["python_requires"], source)
+	c.addRequirements(strings.Split(options["install_requires"], "\n"), models.ScopeProd, source)
+
+	extras := sections["options.extras_require"]
+	names := make([]string, 0, len(extras))
+	for name := range extras {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.addRequirements(strings.Split(extras[name], "\n"), pythonGroupScope(name), source)
+	}
+}
+
+// readINI reads a setup.cfg style file into sections of keys. Indented lines
+// continue the previous value, joined with newlines.
+func readINI(path string) map[string]map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	key := ""
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		raw := sc.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			section = make(map[string]string)
+			sections[name] = section
+			key = ""
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		if raw[0] == ' ' || raw[0] == '\t' {
+			if key != "" {
+				section[key] = strings.TrimLeft(section[key]+"\n"+line, "\n")
+			}
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep < 0 {
+			continue
+		}
+		key = strings.TrimSpace(line[:sep])
+		section[key] = strings.TrimSpace(line[sep+1:])
+	}
+	return sections
+}
+
+// readTOMLLock reads the [[package]] entries of poetry.lock and uv.lock.
+func readTOMLLock(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil
+	}
+
+	locked := make(map[string]string)
+	packages, _ := doc["package"].([]interface{})
+	for _, p := range packages {
+		if pkg, ok := p.(map[string]interface{}); ok {
+			locked[normalizePythonName(toml.String(pkg, "name"))] = toml.String(pkg, "version")
+		}
+	}
+	return locked
+}
+
+func readPipfileLock(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	// _meta holds non-package values, so sections are decoded one by one.
+	var lock map[string]json.RawMessage
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+
+	locked := make(map[string]string)
+	for _, section := range []string{"default", "develop"} {
+		var packages map[string]struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(lock[section], &packages); err != nil {
+			continue
+		}
+		for name, pkg := range packages {
+			locked[normalizePythonName(name)] = strings.TrimPrefix(pkg.Version, "==")
+		}
+	}
+	return locked
+}
+
+func sortedKeys(t map[string]interface{}) []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
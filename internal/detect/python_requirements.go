@@ -0,0 +1,160 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pythonRequirement is a dependency parsed from a PEP 508 specifier such as
+// `django[argon2]>=4.2,<5; python_version >= "3.10"`.
+type pythonRequirement struct {
+	name       string
+	constraint string
+}
+
+var (
+	pythonRequirementName = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+	pythonEggFragment     = regexp.MustCompile(`#egg=([A-Za-z0-9._-]+)`)
+)
+
+// parseRequirement parses a PEP 508 specifier. Extras and environment
+// markers are dropped; direct references ("name @ url") have no version.
+func parseRequirement(spec string) (pythonRequirement, bool) {
+	spec, _, _ = strings.Cut(spec, ";")
+	m := pythonRequirementName.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return pythonRequirement{}, false
+	}
+	req := pythonRequirement{name: m[1]}
+	if !strings.HasPrefix(m[2], "@") {
+		req.constraint = requirementVersion(m[2])
+	}
+	return req, true
+}
+
+// requirementVersion normalizes a version specifier, dropping spaces and
+// the parentheses of the older "name (>=1.0)" form.
+func requirementVersion(spec string) string {
+	return strings.Join(strings.Fields(strings.Trim(strings.TrimSpace(spec), "()")), "")
+}
+
+// readRequirements parses a pip requirements file, following -r includes
+// relative to the including file. Constraint files (-c) add no
+// dependencies and are skipped, as are local paths and URLs without an
+// #egg= name.
+func readRequirements(path string, visited map[string]bool) []pythonRequirement {
+	path = filepath.Clean(path)
+	if visited[path] {
+		return nil
+	}
+	visited[path] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	content := strings.ReplaceAll(string(data), "\\\r\n", "")
+	content = strings.ReplaceAll(content, "\\\n", "")
+
+	var reqs []pythonRequirement
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(stripRequirementComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "-") {
+			option, value := splitRequirementOption(line)
+			switch option {
+			case "-r", "--requirement":
+				if value != "" {
+					reqs = append(reqs, readRequirements(filepath.Join(filepath.Dir(path), value), visited)...)
+				}
+			case "-e", "--editable":
+				if m := pythonEggFragment.FindStringSubmatch(value); m != nil {
+					reqs = append(reqs, pythonRequirement{name: m[1]})
+				}
+			}
+			continue
+		}
+
+		if strings.Contains(line, "://") || strings.HasPrefix(line, ".") || strings.HasPrefix(line, "/") {
+			if m := pythonEggFragment.FindStringSubmatch(line); m != nil {
+				reqs = append(reqs, pythonRequirement{name: m[1]})
+			}
+			continue
+		}
+
+		// Per-requirement options such as --hash follow the specifier.
+		if i := strings.Index(line, " --"); i >= 0 {
+			line = line[:i]
+		}
+		if req, ok := parseRequirement(line); ok {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// stripRequirementComment removes a comment, which starts with a # at the
+// beginning of the line or after whitespace, so URL fragments are kept.
+func stripRequirementComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitRequirementOption splits "-r file", "-rfile" and "--requirement=file"
+// into the option and its value.
+func splitRequirementOption(line string) (string, string) {
+	if strings.HasPrefix(line, "--") {
+		if option, value, ok := strings.Cut(line, "="); ok && !strings.ContainsAny(option, " \t") {
+			return option, strings.TrimSpace(value)
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 1 {
+			return fields[0], fields[1]
+		}
+		return fields[0], ""
+	}
+	option, value := line[:2], strings.TrimSpace(line[2:])
+	return option, value
+}
+
+// requirementIncludes returns the files a requirements file includes with
+// -r, directly or through other includes, relative to projectPath.
+func requirementIncludes(projectPath, file string, visited map[string]bool) []string {
+	file = path.Clean(file)
+	if visited[file] {
+		return nil
+	}
+	visited[file] = true
+
+	data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(file)))
+	if err != nil {
+		return nil
+	}
+	content := strings.ReplaceAll(string(data), "\\\r\n", "")
+	content = strings.ReplaceAll(content, "\\\n", "")
+
+	var includes []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(stripRequirementComment(line))
+		if !strings.HasPrefix(line, "-") {
+			continue
+		}
+		option, value := splitRequirementOption(line)
+		if (option == "-r" || option == "--requirement") && value != "" {
+			include := path.Join(path.Dir(file), filepath.ToSlash(value))
+			includes = append(includes, include)
+			includes = append(includes, requirementIncludes(projectPath, include, visited)...)
+		}
+	}
+	return includes
+}
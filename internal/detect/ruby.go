@@ -0,0 +1,416 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var rubyFrameworks = map[string]bool{
+	"rails":   true,
+	"sinatra": true,
+	"hanami":  true,
+	"roda":    true,
+	"grape":   true,
+	"jekyll":  true,
+}
+
+var (
+	rubyStatement    = regexp.MustCompile(`^(\w+)\b\s*\(?(.*?)\)?$`)
+	rubyBlockStart   = regexp.MustCompile(`\s*\bdo(\s*\|[^|]*\|)?$`)
+	rubyOption       = regexp.MustCompile(`^:?["']?(\w+)["']?\s*(?::|=>)\s*(.+)$`)
+	gemspecStatement = regexp.MustCompile(`\.(add_runtime_dependency|add_development_dependency|add_dependency|required_ruby_version\s*=)\s*\(?(.*?)\)?$`)
+	gemLockSpec      = regexp.MustCompile(`^(\S+) \(([^)]+)\)$`)
+)
+
+type rubyDetector struct{}
+
+func init() {
+	Register(rubyDetector{})
+}
+
+func (rubyDetector) Name() string { return "ruby" }
+
+func (rubyDetector) Files() []string { return []string{"Gemfile", "Gemfile.lock", "*.gemspec"} }
+
+func (rubyDetector) Includes(projectPath string, files []string) []string {
+	return []string{".ruby-version"}
+}
+
+// rubyGem is a gem declared in a Gemfile or gemspec.
+type rubyGem struct {
+	declaredDependency
+	file string
+}
+
+func (rubyDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	ruby := models.Technology{Type: "runtime", Name: "Ruby"}
+
+	var gems []rubyGem
+	for _, f := range files {
+		switch {
+		case f == "Gemfile":
+			gems = append(gems, readGemfile(projectPath, f, &ruby)...)
+		case strings.HasSuffix(f, ".gemspec"):
+			gems = append(gems, readGemspec(projectPath, f, &ruby)...)
+		}
+	}
+	if ruby.DetectedFrom == "" {
+		// Gemfile.lock alone is left over from an old install.
+		return nil, nil
+	}
+
+	if data, err := os.ReadFile(filepath.Join(projectPath, ".ruby-version")); err == nil {
+		if v := strings.TrimPrefix(strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0]), "ruby-"); v != "" {
+			ruby.Version = v
+			ruby.DetectedFrom = ".ruby-version"
+		}
+	}
+	lock, lockedRuby, bundler := readGemfileLock(filepath.Join(projectPath, "Gemfile.lock"))
+	if lockedRuby != "" {
+		ruby.Version = lockedRuby
+		ruby.DetectedFrom = "Gemfile.lock"
+	}
+
+	techs := []models.Technology{ruby}
+	if bundler != "" {
+		techs = append(techs, models.Technology{Type: "build", Name: "Bundler", Version: bundler, DetectedFrom: "Gemfile.lock"})
+	}
+
+	// The Gemfile comes first, so it decides the scope of a gem the gemspec
+	// declares too.
+	scopes := make(map[string]models.DependencyScope)
+	var deps []rubyGem
+	for _, gem := range gems {
+		if _, ok := scopes[gem.name]; ok {
+			continue
+		}
+		scopes[gem.name] = gem.scope
+		deps = append(deps, gem)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].name < deps[j].name })
+
+	for _, gem := range deps {
+		techType := "dependency"
+		if rubyFrameworks[gem.name] {
+			techType = "framework"
+		}
+		version := pinnedVersion(gem.constraint)
+		if lock != nil {
+			if v := lock.versions[gem.name]; v != "" {
+				version = v
+			}
+		}
+		techs = append(techs, models.Technology{
+			Type:            techType,
+			Name:            gem.name,
+			Version:         version,
+			DeclaredVersion: gem.constraint,
+			Scope:           gem.scope,
+			Source:          gem.source,
+			DetectedFrom:    gem.file,
+		})
+	}
+
+	if lock != nil {
+		techs = append(techs, lock.transitive(scopes)...)
+	}
+	return techs, nil
+}
+
+// gemfileBlock is a do ... end block of a Gemfile, whose gems take its
+// scope and source.
+type gemfileBlock struct {
+	scope  models.DependencyScope
+	source string
+}
+
+// readGemfile reads the gems a Gemfile declares and the Ruby version it
+// requires. Gems inside group blocks, or with a group option, are dev when
+// all their groups are development or test groups.
+func readGemfile(projectPath, name string, ruby *models.Technology) []rubyGem {
+	f, err := os.Open(filepath.Join(projectPath, name))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	ruby.DetectedFrom = name
+
+	stack := []gemfileBlock{{scope: models.ScopeProd}}
+	var gems []rubyGem
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(stripRubyComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+		if line == "end" {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		top := stack[len(stack)-1]
+		block := top
+		opens := rubyBlockStart.MatchString(line)
+		m := rubyStatement.FindStringSubmatch(rubyBlockStart.ReplaceAllString(line, ""))
+		if m == nil {
+			m = []string{line, "", ""}
+		}
+		args := rubyArgs(m[2])
+
+		switch m[1] {
+		case "ruby":
+			if len(args) > 0 {
+				if v, ok := rubyString(args[0]); ok {
+					ruby.Version = lowerBound(v)
+					ruby.DeclaredVersion = v
+				}
+			}
+		case "gem":
+			if gem, ok := parseGem(args, top); ok {
+				gem.file = name
+				gems = append(gems, gem)
+			}
+		case "group":
+			block.scope = rubyGroupScope(args)
+		case "git", "github", "path":
+			if len(args) > 0 {
+				if v, ok := rubyString(args[0]); ok {
+					block.source = rubySource(m[1], v)
+				}
+			}
+		}
+
+		// Any other block, such as platforms or an if statement, keeps the
+		// enclosing scope until its end.
+		if opens || m[1] == "if" || m[1] == "unless" || m[1] == "case" || m[1] == "begin" {
+			stack = append(stack, block)
+		}
+	}
+	return gems
+}
+
+// parseGem reads the arguments of a gem statement: the name, any version
+// requirements, then options.
+func parseGem(args []string, block gemfileBlock) (rubyGem, bool) {
+	gem := rubyGem{}
+	gem.scope = block.scope
+	gem.source = block.source
+
+	var constraints []string
+	var ref string
+	for i, arg := range args {
+		if opt := rubyOption.FindStringSubmatch(arg); opt != nil && i > 0 {
+			value, _ := rubyString(opt[2])
+			switch opt[1] {
+			case "group", "groups":
+				gem.scope = rubyGroupScope(rubyArgs(strings.Trim(opt[2], "[]")))
+			case "git", "github", "path":
+				gem.source = rubySource(opt[1], value)
+			case "branch", "tag", "ref":
+				ref = "?" + opt[1] + "=" + value
+			}
+			continue
+		}
+		s, ok := rubyString(arg)
+		if !ok {
+			continue
+		}
+		if i == 0 {
+			gem.name = s
+		} else {
+			constraints = append(constraints, s)
+		}
+	}
+	if gem.name == "" {
+		return rubyGem{}, false
+	}
+	if strings.HasPrefix(gem.source, "git+") {
+		gem.source += ref
+	}
+	gem.constraint = strings.Join(constraints, ", ")
+	return gem, true
+}
+
+// readGemspec reads the dependencies of a gem's specification.
+func readGemspec(projectPath, name string, ruby *models.Technology) []rubyGem {
+	data, err := os.ReadFile(filepath.Join(projectPath, name))
+	if err != nil {
+		return nil
+	}
+	if ruby.DetectedFrom == "" {
+		ruby.DetectedFrom = name
+	}
+
+	var gems []rubyGem
+	for _, line := range strings.Split(string(data), "\n") {
+		m := gemspecStatement.FindStringSubmatch(strings.TrimSpace(stripRubyComment(line)))
+		if m == nil {
+			continue
+		}
+		args := rubyArgs(m[2])
+		if strings.HasPrefix(m[1], "required_ruby_version") {
+			var constraints []string
+			for _, arg := range args {
+				if s, ok := rubyString(strings.Trim(arg, "[]")); ok {
+					constraints = append(constraints, s)
+				}
+			}
+			if ruby.DeclaredVersion == "" && len(constraints) > 0 {
+				ruby.DeclaredVersion = strings.Join(constraints, ", ")
+				ruby.Version = lowerBound(constraints[0])
+			}
+			continue
+		}
+
+		scope := models.ScopeProd
+		if m[1] == "add_development_dependency" {
+			scope = models.ScopeDev
+		}
+		if gem, ok := parseGem(args, gemfileBlock{scope: scope}); ok {
+			gem.file = name
+			gems = append(gems, gem)
+		}
+	}
+	return gems
+}
+
+// readGemfileLock reads the resolved gems of Gemfile.lock along with the
+// Ruby and Bundler versions it records.
+func readGemfileLock(path string) (*lockGraph, string, string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", ""
+	}
+	defer f.Close()
+
+	g := newLockGraph("Gemfile.lock")
+	var ruby, bundler string
+	section, current := "", ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case indent == 0:
+			section = trimmed
+		case section == "RUBY VERSION":
+			// "ruby 3.2.2p53"
+			if v := strings.TrimPrefix(trimmed, "ruby "); v != trimmed {
+				ruby, _, _ = strings.Cut(v, "p")
+			}
+		case section == "BUNDLED WITH":
+			bundler = trimmed
+		case section != "GEM" && section != "GIT" && section != "PATH":
+		case indent == 4:
+			if m := gemLockSpec.FindStringSubmatch(trimmed); m != nil {
+				current = m[1]
+				// Platform-specific gems carry the platform: 1.15.5-x86_64-linux.
+				version, _, _ := strings.Cut(m[2], "-")
+				g.addVersion(current, version)
+			}
+		case indent == 6 && current != "":
+			dep := strings.Fields(trimmed)[0]
+			g.deps[current] = append(g.deps[current], dep)
+		}
+	}
+	return g, ruby, bundler
+}
+
+// rubyGroupScope returns the scope of gems in the given groups.
+func rubyGroupScope(groups []string) models.DependencyScope {
+	for _, group := range groups {
+		switch strings.TrimPrefix(strings.TrimSpace(group), ":") {
+		case "development", "test":
+		default:
+			return models.ScopeProd
+		}
+	}
+	if len(groups) == 0 {
+		return models.ScopeProd
+	}
+	return models.ScopeDev
+}
+
+func rubySource(kind, value string) string {
+	switch kind {
+	case "github":
+		return "git+https://github.com/" + value + ".git"
+	case "path":
+		return "path+" + value
+	}
+	return "git+" + value
+}
+
+// rubyArgs splits the arguments of a statement on the commas that are not
+// inside quotes or brackets.
+func rubyArgs(s string) []string {
+	var args []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{' || c == '(':
+			depth++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		args = append(args, rest)
+	}
+	return args
+}
+
+// rubyString returns the value of a string literal or symbol.
+func rubyString(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	if strings.HasPrefix(s, ":") && len(s) > 1 {
+		return s[1:], true
+	}
+	return "", false
+}
+
+// stripRubyComment removes a comment that starts outside a string literal.
+func stripRubyComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
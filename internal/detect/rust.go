@@ -0,0 +1,396 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/toml"
+)
+
+var rustFrameworks = map[string]bool{
+	"tokio":     true,
+	"axum":      true,
+	"actix-web": true,
+	"rocket":    true,
+	"warp":      true,
+	"bevy":      true,
+	"tauri":     true,
+	"leptos":    true,
+}
+
+// cargoDependencySections lists the dependency tables of Cargo.toml in the
+// order they are read; a crate listed in several keeps the first scope.
+var cargoDependencySections = []struct {
+	key   string
+	scope models.DependencyScope
+}{
+	{"dependencies", models.ScopeProd},
+	{"build-dependencies", models.ScopeBuild},
+	{"dev-dependencies", models.ScopeDev},
+}
+
+type rustDetector struct{}
+
+func init() {
+	Register(rustDetector{})
+}
+
+func (rustDetector) Name() string { return "rust" }
+
+func (rustDetector) Files() []string { return []string{"Cargo.toml", "Cargo.lock"} }
+
+// Includes returns the manifest and lock file of the workspace the crate
+// belongs to, which may live in a parent directory.
+func (rustDetector) Includes(projectPath string, files []string) []string {
+	manifest, err := readCargoManifest(projectPath)
+	if err != nil {
+		return nil
+	}
+	wsDir, _ := findCargoWorkspace(projectPath, manifest)
+	if wsDir == "" || wsDir == projectPath {
+		return nil
+	}
+	rel, err := filepath.Rel(projectPath, wsDir)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	return []string{path.Join(rel, "Cargo.toml"), path.Join(rel, "Cargo.lock")}
+}
+
+type cargoDependency struct {
+	name        string
+	requirement string
+	source      string
+	features    []string
+	scope       models.DependencyScope
+}
+
+func (rustDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "Cargo.toml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := models.Technology{
+		Type:         "runtime",
+		Name:         "Rust",
+		DetectedFrom: "Cargo.toml",
+	}
+	manifest, err := toml.Parse(data)
+	if err != nil {
+		// A manifest that does not parse still marks a Rust project.
+		return []models.Technology{runtime}, nil
+	}
+
+	wsDir, ws := findCargoWorkspace(projectPath, manifest)
+	pkg := toml.Table(manifest, "package")
+	edition := pkg["edition"]
+	if inheritsFromWorkspace(edition) {
+		edition = toml.Table(ws, "workspace", "package")["edition"]
+	}
+	runtime.Version, _ = edition.(string)
+	techs := []models.Technology{runtime}
+
+	lock := readCargoLock(filepath.Join(projectPath, "Cargo.lock"))
+	if lock == nil && wsDir != "" {
+		lock = readCargoLock(filepath.Join(wsDir, "Cargo.lock"))
+	}
+
+	name := toml.String(pkg, "name")
+	if name == "" {
+		// A virtual manifest only declares a workspace; its members are
+		// scanned as projects of their own.
+		if lock != nil {
+			techs = append(techs, lock.workspaceDependencies()...)
+		}
+		return techs, nil
+	}
+
+	deps := cargoDependencies(projectPath, manifest, wsDir, ws)
+	direct := make(map[string]models.DependencyScope, len(deps))
+	for _, dep := range deps {
+		direct[dep.name] = dep.scope
+	}
+	for _, dep := range deps {
+		if rustFrameworks[dep.name] {
+			techs = append(techs, cargoTechnology("framework", name, dep, lock))
+		}
+	}
+	for _, dep := range deps {
+		if !rustFrameworks[dep.name] {
+			techs = append(techs, cargoTechnology("dependency", name, dep, lock))
+		}
+	}
+
+	if lock != nil {
+		techs = append(techs, lock.transitive(direct)...)
+	}
+	return techs, nil
+}
+
+// cargoTechnology records a dependency from Cargo.toml with the version
+// Cargo.lock resolved it to. Without a lock file, only a requirement such
+// as "=1.2.3" gives one; a bare "1.2.3" means ^1.2.3 to Cargo.
+func cargoTechnology(techType, crate string, dep cargoDependency, lock *cargoLock) models.Technology {
+	version := ""
+	if strings.HasPrefix(dep.requirement, "=") {
+		version = pinnedVersion(dep.requirement)
+	}
+	if lock != nil {
+		if v := lock.version(crate, dep.name); v != "" {
+			version = v
+		}
+	}
+	return models.Technology{
+		Type:            techType,
+		Name:            dep.name,
+		Version:         version,
+		DeclaredVersion: dep.requirement,
+		Scope:           dep.scope,
+		Source:          dep.source,
+		Features:        dep.features,
+		DetectedFrom:    "Cargo.toml",
+	}
+}
+
+func readCargoManifest(dir string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil, err
+	}
+	return toml.Parse(data)
+}
+
+// findCargoWorkspace returns the directory and manifest of the workspace a
+// crate belongs to: the crate itself when its manifest has a [workspace]
+// table, the directory package.workspace names, or else the nearest parent
+// directory declaring a workspace, which is where Cargo looks too.
+func findCargoWorkspace(projectPath string, manifest map[string]interface{}) (string, map[string]interface{}) {
+	if toml.Table(manifest, "workspace") != nil {
+		return projectPath, manifest
+	}
+	if dir := toml.String(toml.Table(manifest, "package"), "workspace"); dir != "" {
+		dir = filepath.Join(projectPath, dir)
+		if ws, err := readCargoManifest(dir); err == nil && toml.Table(ws, "workspace") != nil {
+			return dir, ws
+		}
+		return "", nil
+	}
+
+	dir := projectPath
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+		if ws, err := readCargoManifest(dir); err == nil && toml.Table(ws, "workspace") != nil {
+			return dir, ws
+		}
+	}
+}
+
+// inheritsFromWorkspace reports whether a manifest value is written as
+// "key.workspace = true", taking its value from the workspace root.
+func inheritsFromWorkspace(v interface{}) bool {
+	t, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	inherit, _ := t["workspace"].(bool)
+	return inherit
+}
+
+// cargoDependencies reads the dependency tables of a manifest, including
+// platform-specific ones under [target.'cfg(...)'], resolving dependencies
+// inherited from the workspace.
+func cargoDependencies(projectPath string, manifest map[string]interface{}, wsDir string, ws map[string]interface{}) []cargoDependency {
+	tables := []map[string]interface{}{manifest}
+	targets := toml.Table(manifest, "target")
+	for _, target := range sortedKeys(targets) {
+		if t, ok := targets[target].(map[string]interface{}); ok {
+			tables = append(tables, t)
+		}
+	}
+
+	wsDeps := toml.Table(ws, "workspace", "dependencies")
+
+	seen := make(map[string]bool)
+	var deps []cargoDependency
+	for _, section := range cargoDependencySections {
+		for _, t := range tables {
+			entries := toml.Table(t, section.key)
+			for _, key := range sortedKeys(entries) {
+				dep := parseCargoDependency(key, entries[key], projectPath, projectPath)
+				if inheritsFromWorkspace(entries[key]) {
+					inherited := parseCargoDependency(key, wsDeps[key], projectPath, wsDir)
+					inherited.features = mergeFeatures(inherited.features, dep.features)
+					dep = inherited
+				}
+				if seen[dep.name] {
+					continue
+				}
+				seen[dep.name] = true
+				dep.scope = section.scope
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// parseCargoDependency reads a dependency written as a version requirement
+// or as a table. A "package" key renames the crate, whose real name is
+// recorded. Local paths are relative to dir, the directory of the manifest
+// the dependency is written in, and are recorded relative to projectPath.
+func parseCargoDependency(key string, v interface{}, projectPath, dir string) cargoDependency {
+	dep := cargoDependency{name: key}
+	switch spec := v.(type) {
+	case string:
+		dep.requirement = spec
+	case map[string]interface{}:
+		if pkg := toml.String(spec, "package"); pkg != "" {
+			dep.name = pkg
+		}
+		dep.requirement = toml.String(spec, "version")
+		dep.features = toml.Strings(spec, "features")
+
+		switch {
+		case toml.String(spec, "git") != "":
+			dep.source = "git+" + toml.String(spec, "git")
+			for _, ref := range []string{"branch", "tag", "rev"} {
+				if value := toml.String(spec, ref); value != "" {
+					dep.source += "?" + ref + "=" + value
+					break
+				}
+			}
+		case toml.String(spec, "path") != "":
+			path := filepath.Join(dir, toml.String(spec, "path"))
+			if rel, err := filepath.Rel(projectPath, path); err == nil {
+				path = rel
+			}
+			dep.source = "path+" + filepath.ToSlash(path)
+		case toml.String(spec, "registry") != "":
+			dep.source = "registry+" + toml.String(spec, "registry")
+		}
+	}
+	return dep
+}
+
+// mergeFeatures adds the features a member enables on an inherited
+// dependency to those the workspace enables.
+func mergeFeatures(base, extra []string) []string {
+	merged := append([]string(nil), base...)
+	for _, f := range extra {
+		found := false
+		for _, existing := range merged {
+			if existing == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
+
+// cargoLock is the dependency graph of a Cargo.lock. Packages without a
+// source are the crates of the workspace itself.
+type cargoLock struct {
+	*lockGraph
+	local map[string]bool
+	// pinned holds "crate>dependency" versions for dependencies locked at
+	// several versions, which name the one they use.
+	pinned map[string]string
+}
+
+func readCargoLock(path string) *cargoLock {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil
+	}
+
+	lock := &cargoLock{
+		lockGraph: newLockGraph("Cargo.lock"),
+		local:     make(map[string]bool),
+		pinned:    make(map[string]string),
+	}
+	packages, _ := doc["package"].([]interface{})
+	for _, p := range packages {
+		pkg, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		crate := toml.String(pkg, "name")
+		if toml.String(pkg, "source") == "" {
+			lock.local[crate] = true
+		}
+		lock.addVersion(crate, toml.String(pkg, "version"))
+		for _, dep := range toml.Strings(pkg, "dependencies") {
+			// Entries are "name", or "name version" when several versions
+			// of the crate are locked.
+			fields := strings.Fields(dep)
+			lock.deps[crate] = append(lock.deps[crate], fields[0])
+			if len(fields) > 1 {
+				lock.pinned[crate+">"+fields[0]] = fields[1]
+			}
+		}
+	}
+	return lock
+}
+
+// version returns the version of dep that crate uses.
+func (l *cargoLock) version(crate, dep string) string {
+	if v, ok := l.pinned[crate+">"+dep]; ok {
+		return v
+	}
+	return l.versions[dep]
+}
+
+// workspaceDependencies returns the dependencies of every workspace crate,
+// for a virtual manifest that declares none itself. Cargo.lock does not
+// tell their scopes apart.
+func (l *cargoLock) workspaceDependencies() []models.Technology {
+	roots := make([]string, 0, len(l.local))
+	for crate := range l.local {
+		roots = append(roots, crate)
+	}
+	sort.Strings(roots)
+
+	direct := make(map[string]models.DependencyScope)
+	for _, root := range roots {
+		direct[root] = ""
+	}
+	var techs []models.Technology
+	for _, root := range roots {
+		for _, dep := range l.deps[root] {
+			if _, seen := direct[dep]; seen {
+				continue
+			}
+			direct[dep] = ""
+			techs = append(techs, models.Technology{
+				Type:         "dependency",
+				Name:         dep,
+				Version:      l.version(root, dep),
+				DetectedFrom: "Cargo.lock",
+			})
+		}
+	}
+	sort.Slice(techs, func(i, j int) bool { return techs[i].Name < techs[j].Name })
+
+	return append(techs, l.transitive(direct)...)
+}
@@ -0,0 +1,203 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var swiftFrameworks = map[string]bool{
+	"vapor":                         true,
+	"hummingbird":                   true,
+	"kitura":                        true,
+	"swift-composable-architecture": true,
+}
+
+var (
+	swiftToolsVersion = regexp.MustCompile(`^//\s*swift-tools-version\s*:\s*([0-9.]+)`)
+	swiftPackageArg   = regexp.MustCompile(`\b(url|path|id|name)\s*:\s*"([^"]*)"\s*,?\s*`)
+	swiftRefArg       = regexp.MustCompile(`^(branch|revision)\s*:\s*"([^"]*)"$`)
+	swiftExactArg     = regexp.MustCompile(`^(?:exact\s*:\s*|\.exact\(\s*)"([^"]*)"\)?$`)
+)
+
+type swiftDetector struct{}
+
+func init() {
+	Register(swiftDetector{})
+}
+
+func (swiftDetector) Name() string { return "swift" }
+
+func (swiftDetector) Files() []string { return []string{"Package.swift", "Package.resolved"} }
+
+func (swiftDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, "Package.swift"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+
+	swift := models.Technology{Type: "runtime", Name: "Swift", DetectedFrom: "Package.swift"}
+	if m := swiftToolsVersion.FindStringSubmatch(strings.TrimSpace(strings.SplitN(content, "\n", 2)[0])); m != nil {
+		swift.Version = m[1]
+	}
+	techs := []models.Technology{swift}
+
+	resolved := readPackageResolved(filepath.Join(projectPath, "Package.resolved"))
+	direct := make(map[string]bool)
+	var deps []models.Technology
+	for _, args := range swiftPackageCalls(content) {
+		dep, ok := parseSwiftPackage(args)
+		if !ok || direct[dep.Name] {
+			continue
+		}
+		direct[dep.Name] = true
+		if v := resolved[dep.Name]; v != "" {
+			dep.Version = v
+		}
+		if swiftFrameworks[dep.Name] {
+			dep.Type = "framework"
+		}
+		deps = append(deps, dep)
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	techs = append(techs, deps...)
+
+	// Package.resolved pins every package of the graph without recording
+	// which package needs it.
+	names := make([]string, 0, len(resolved))
+	for name := range resolved {
+		if !direct[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		techs = append(techs, models.Technology{
+			Type:         "dependency",
+			Name:         name,
+			Version:      resolved[name],
+			Transitive:   true,
+			DetectedFrom: "Package.resolved",
+		})
+	}
+	return techs, nil
+}
+
+// swiftPackageCalls returns the arguments of each .package(...) call in a
+// manifest.
+func swiftPackageCalls(content string) []string {
+	var calls []string
+	for {
+		i := strings.Index(content, ".package(")
+		if i < 0 {
+			return calls
+		}
+		content = content[i+len(".package("):]
+		depth := 1
+		end := 0
+		for end < len(content) && depth > 0 {
+			switch content[end] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			case '"':
+				if j := strings.IndexByte(content[end+1:], '"'); j >= 0 {
+					end += j + 1
+				}
+			}
+			end++
+		}
+		calls = append(calls, content[:end-1])
+		content = content[end:]
+	}
+}
+
+// parseSwiftPackage reads a package dependency: its location, followed by
+// a requirement such as from: "1.0.0", exact: "1.2.3", "1.0.0"..<"2.0.0",
+// .upToNextMinor(from: "1.2.0") or branch: "main". The package is named by
+// its identity, the last component of its location in lower case.
+func parseSwiftPackage(args string) (models.Technology, bool) {
+	dep := models.Technology{Type: "dependency", Scope: models.ScopeProd, DetectedFrom: "Package.swift"}
+	location := ""
+	for _, m := range swiftPackageArg.FindAllStringSubmatch(args, -1) {
+		switch m[1] {
+		case "url":
+			location = m[2]
+			dep.Source = "git+" + m[2]
+		case "path":
+			location = m[2]
+			dep.Source = "path+" + m[2]
+		case "id":
+			// Registry identifiers are "scope.name".
+			location = m[2][strings.LastIndex(m[2], ".")+1:]
+		}
+	}
+	if location == "" {
+		return dep, false
+	}
+	dep.Name = swiftIdentity(location)
+
+	requirement := strings.TrimSpace(swiftPackageArg.ReplaceAllString(args, ""))
+	if m := swiftRefArg.FindStringSubmatch(requirement); m != nil {
+		dep.Source += "?" + m[1] + "=" + m[2]
+		return dep, true
+	}
+	if m := swiftExactArg.FindStringSubmatch(requirement); m != nil {
+		dep.Version = m[1]
+	}
+	dep.DeclaredVersion = strings.ReplaceAll(requirement, `"`, "")
+	return dep, true
+}
+
+func swiftIdentity(location string) string {
+	location = strings.TrimSuffix(strings.TrimRight(location, "/"), ".git")
+	return strings.ToLower(path.Base(location))
+}
+
+// readPackageResolved returns the pinned version of each package in
+// Package.resolved, keyed by identity. Version 1 files nest the pins under
+// "object" and name packages by repository URL.
+func readPackageResolved(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	type pin struct {
+		Identity      string `json:"identity"`
+		Location      string `json:"location"`
+		RepositoryURL string `json:"repositoryURL"`
+		State         struct {
+			Version string `json:"version"`
+		} `json:"state"`
+	}
+	var resolved struct {
+		Pins   []pin `json:"pins"`
+		Object struct {
+			Pins []pin `json:"pins"`
+		} `json:"object"`
+	}
+	if err := json.Unmarshal(data, &resolved); err != nil {
+		return nil
+	}
+
+	versions := make(map[string]string)
+	for _, p := range append(resolved.Pins, resolved.Object.Pins...) {
+		identity := p.Identity
+		if identity == "" {
+			identity = swiftIdentity(p.Location + p.RepositoryURL)
+		}
+		versions[identity] = p.State.Version
+	}
+	return versions
+}
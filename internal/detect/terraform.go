@@ -0,0 +1,400 @@
+package detect
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// terraformRegistryModule matches registry module addresses,
+// [host/]namespace/name/provider.
+var terraformRegistryModule = regexp.MustCompile(`^([\w.-]+\.[a-z]+/)?[\w-]+/[\w-]+/[\w-]+$`)
+
+// terraformRegistries are the hosts of the public registries, left out of
+// provider names.
+var terraformRegistries = []string{"registry.terraform.io/", "registry.opentofu.org/"}
+
+type terraformDetector struct{}
+
+func init() {
+	Register(terraformDetector{})
+}
+
+func (terraformDetector) Name() string { return "terraform" }
+
+func (terraformDetector) IndicatesProject() bool { return false }
+
+func (terraformDetector) Files() []string {
+	return []string{"*.tf", "terraform/*.tf", "infra/*.tf", "infrastructure/*.tf"}
+}
+
+func (terraformDetector) Includes(projectPath string, files []string) []string {
+	var includes []string
+	for _, f := range files {
+		includes = append(includes, path.Join(path.Dir(f), ".terraform.lock.hcl"))
+	}
+	return includes
+}
+
+// Detect records Terraform with the version its configuration requires,
+// the providers it requires as providers and the modules it calls as
+// modules. Configurations in several of the searched directories are
+// merged; the .terraform.lock.hcl next to them pins provider versions.
+func (terraformDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	terraform := models.Technology{Type: "deploy", Name: "Terraform", DetectedFrom: files[0]}
+	providers := make(map[string]*models.Technology)
+	var modules []models.Technology
+	seenModules := make(map[string]bool)
+	locked := make(map[string]string)
+	lockRead := make(map[string]bool)
+
+	provider := func(name, file string) *models.Technology {
+		if !strings.Contains(name, "/") {
+			name = "hashicorp/" + name
+		}
+		name = trimTerraformRegistry(name)
+		if p, ok := providers[name]; ok {
+			return p
+		}
+		p := &models.Technology{Type: "provider", Name: name, Scope: models.ScopeProd, DetectedFrom: file}
+		providers[name] = p
+		return p
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(projectPath, file))
+		if err != nil {
+			return nil, err
+		}
+		dir := path.Dir(file)
+		if !lockRead[dir] {
+			lockRead[dir] = true
+			for name, version := range readTerraformLock(filepath.Join(projectPath, dir, ".terraform.lock.hcl")) {
+				locked[name] = version
+			}
+		}
+
+		config := parseHCL(string(data))
+		for _, block := range config.blocks {
+			switch block.typ {
+			case "terraform":
+				if v := block.attrs["required_version"]; v != "" && terraform.DeclaredVersion == "" {
+					terraform.Version = lowerBound(v)
+					terraform.DeclaredVersion = v
+					terraform.DetectedFrom = file
+				}
+				for _, required := range block.blocks {
+					if required.typ != "required_providers" {
+						continue
+					}
+					for _, req := range required.blocks {
+						source := req.attrs["source"]
+						if source == "" {
+							source = req.typ
+						}
+						p := provider(source, file)
+						if v := req.attrs["version"]; v != "" {
+							p.DeclaredVersion = v
+							p.DetectedFrom = file
+						}
+					}
+					// The short form: aws = "~> 5.0".
+					for name, constraint := range required.attrs {
+						provider(name, file).DeclaredVersion = constraint
+					}
+				}
+			case "provider":
+				if len(block.labels) > 0 {
+					provider(block.labels[0], file)
+				}
+			case "module":
+				if len(block.labels) == 0 || seenModules[block.labels[0]] {
+					continue
+				}
+				seenModules[block.labels[0]] = true
+				modules = append(modules, terraformModule(block.labels[0], block.attrs, file))
+			}
+		}
+	}
+
+	// The lock file also pins providers only needed by resources or modules.
+	for name := range locked {
+		provider(name, ".terraform.lock.hcl")
+	}
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	techs := []models.Technology{terraform}
+	for _, name := range names {
+		p := providers[name]
+		p.Version = pinnedVersion(p.DeclaredVersion)
+		if v := locked[name]; v != "" {
+			p.Version = v
+		}
+		techs = append(techs, *p)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+	return append(techs, modules...), nil
+}
+
+// terraformModule records a module call. Registry modules are named by
+// their address; others are named by their label and record where they are
+// fetched from.
+func terraformModule(label string, attrs map[string]string, file string) models.Technology {
+	source := attrs["source"]
+	module := models.Technology{
+		Type:            "module",
+		Name:            label,
+		DeclaredVersion: attrs["version"],
+		Version:         pinnedVersion(attrs["version"]),
+		Scope:           models.ScopeProd,
+		DetectedFrom:    file,
+	}
+	switch {
+	case strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../"):
+		module.Source = "path+" + path.Join(path.Dir(file), source)
+	case strings.HasPrefix(source, "git::"):
+		module.Source = "git+" + strings.TrimPrefix(source, "git::")
+	case strings.HasPrefix(source, "github.com/"), strings.HasPrefix(source, "bitbucket.org/"):
+		module.Source = "git+https://" + source
+	case terraformRegistryModule.MatchString(source):
+		module.Name = trimTerraformRegistry(source)
+	default:
+		module.Source = source
+	}
+	return module
+}
+
+func trimTerraformRegistry(name string) string {
+	for _, host := range terraformRegistries {
+		name = strings.TrimPrefix(name, host)
+	}
+	return name
+}
+
+// readTerraformLock returns the provider versions .terraform.lock.hcl
+// pins, keyed by provider address.
+func readTerraformLock(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	versions := make(map[string]string)
+	for _, block := range parseHCL(string(data)).blocks {
+		if block.typ == "provider" && len(block.labels) > 0 {
+			versions[trimTerraformRegistry(block.labels[0])] = block.attrs["version"]
+		}
+	}
+	return versions
+}
+
+// hclBlock is a block of an HCL file, such as resource "aws_instance" "web"
+// { ... }. Attributes keep the source text of their expressions, with
+// string literals unquoted; attributes set to an object, as in
+// required_providers, become nested blocks named after the attribute.
+type hclBlock struct {
+	typ    string
+	labels []string
+	attrs  map[string]string
+	blocks []hclBlock
+}
+
+// hclParser reads the structure of HCL, the configuration language of
+// Terraform, without evaluating expressions.
+type hclParser struct {
+	src string
+	pos int
+}
+
+// parseHCL returns the body of an HCL file as a block without a type.
+func parseHCL(src string) hclBlock {
+	p := &hclParser{src: src}
+	return p.body()
+}
+
+func (p *hclParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// skipSpace skips blanks and comments, and line breaks when newlines is
+// set.
+func (p *hclParser) skipSpace(newlines bool) {
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || newlines && rest[0] == '\n':
+			p.pos++
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			if i := strings.IndexByte(rest, '\n'); i >= 0 {
+				p.pos += i
+			} else {
+				p.pos = len(p.src)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if i := strings.Index(rest[2:], "*/"); i >= 0 {
+				p.pos += i + 4
+			} else {
+				p.pos = len(p.src)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// body reads attributes and blocks up to the closing brace or the end of
+// the file.
+func (p *hclParser) body() hclBlock {
+	b := hclBlock{attrs: make(map[string]string)}
+	for {
+		p.skipSpace(true)
+		switch p.peek() {
+		case 0:
+			return b
+		case '}':
+			p.pos++
+			return b
+		case ',':
+			p.pos++
+			continue
+		}
+
+		name := p.identifier()
+		if name == "" {
+			// Not an attribute or a block; skip it.
+			if start := p.pos; p.expression() == "" && p.pos == start {
+				p.pos++
+			}
+			continue
+		}
+		p.skipSpace(false)
+		if p.peek() == '=' || p.peek() == ':' {
+			p.pos++
+			p.skipSpace(false)
+			if p.peek() == '{' {
+				p.pos++
+				nested := p.body()
+				nested.typ = name
+				b.blocks = append(b.blocks, nested)
+			} else {
+				b.attrs[name] = p.expression()
+			}
+			continue
+		}
+
+		var labels []string
+		for p.pos < len(p.src) && strings.IndexByte("{}\n", p.peek()) < 0 {
+			if label := p.identifier(); label != "" {
+				labels = append(labels, label)
+			} else {
+				p.pos++
+			}
+			p.skipSpace(false)
+		}
+		if p.peek() != '{' {
+			continue
+		}
+		p.pos++
+		nested := p.body()
+		nested.typ = name
+		nested.labels = labels
+		b.blocks = append(b.blocks, nested)
+	}
+}
+
+// identifier reads a name or a quoted string.
+func (p *hclParser) identifier() string {
+	if p.peek() == '"' {
+		return p.str()
+	}
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c != '_' && c != '-' && c != '.' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *hclParser) str() string {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) && p.src[p.pos] != '"' && p.src[p.pos] != '\n' {
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	p.pos = min(p.pos+1, len(p.src))
+	s, err := strconv.Unquote(p.src[start:p.pos])
+	if err != nil {
+		return strings.Trim(p.src[start:p.pos], `"`)
+	}
+	return s
+}
+
+// expression reads an expression up to the end of its line, a comma or the
+// brace closing the enclosing object. Brackets and heredocs may span lines.
+// A lone string literal is returned unquoted.
+func (p *hclParser) expression() string {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		c := rest[0]
+		switch {
+		case c == '"':
+			p.str()
+			continue
+		case strings.HasPrefix(rest, "<<"):
+			marker := strings.TrimSpace(strings.TrimLeft(strings.SplitN(rest[2:], "\n", 2)[0], "-"))
+			p.pos += strings.IndexByte(rest+"\n", '\n') + 1
+			for p.pos < len(p.src) {
+				line := p.src[p.pos:]
+				end := strings.IndexByte(line+"\n", '\n')
+				p.pos += end + 1
+				if strings.TrimSpace(line[:min(end, len(line))]) == marker {
+					break
+				}
+			}
+			p.pos = min(p.pos, len(p.src))
+			continue
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return p.literal(start)
+			}
+			depth--
+		case depth == 0 && (c == '\n' || c == ',' || c == '#' || strings.HasPrefix(rest, "//")):
+			return p.literal(start)
+		}
+		p.pos++
+	}
+	return p.literal(start)
+}
+
+func (p *hclParser) literal(start int) string {
+	expr := strings.TrimSpace(p.src[start:p.pos])
+	if len(expr) >= 2 && expr[0] == '"' && strings.IndexByte(expr[1:], '"') == len(expr)-2 {
+		if s, err := strconv.Unquote(expr); err == nil {
+			return s
+		}
+	}
+	return expr
+}
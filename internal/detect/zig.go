@@ -0,0 +1,213 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+var zigFrameworks = map[string]bool{
+	"zap":    true,
+	"jetzig": true,
+	"httpz":  true,
+	"mach":   true,
+	"capy":   true,
+}
+
+type zigDetector struct{}
+
+func init() {
+	Register(zigDetector{})
+}
+
+func (zigDetector) Name() string { return "zig" }
+
+func (zigDetector) Files() []string { return []string{"build.zig", "build.zig.zon"} }
+
+func (zigDetector) Detect(projectPath string, files []string) ([]models.Technology, error) {
+	zig := models.Technology{Type: "runtime", Name: "Zig", DetectedFrom: files[0]}
+	techs := []models.Technology{zig}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "build.zig.zon"))
+	if err != nil {
+		return techs, nil
+	}
+	manifest, _ := parseZON(string(data)).(map[string]interface{})
+	if v, ok := manifest["minimum_zig_version"].(string); ok {
+		techs[0].Version = v
+		techs[0].DetectedFrom = "build.zig.zon"
+	}
+
+	deps, _ := manifest["dependencies"].(map[string]interface{})
+	for _, name := range sortedKeys(deps) {
+		dep, _ := deps[name].(map[string]interface{})
+		tech := models.Technology{
+			Type:         "dependency",
+			Name:         name,
+			Scope:        models.ScopeProd,
+			DetectedFrom: "build.zig.zon",
+		}
+		if zigFrameworks[name] {
+			tech.Type = "framework"
+		}
+		if url, ok := dep["url"].(string); ok {
+			tech.Source = url
+		} else if p, ok := dep["path"].(string); ok {
+			tech.Source = "path+" + p
+		}
+		techs = append(techs, tech)
+	}
+	return techs, nil
+}
+
+// zonParser reads ZON, the Zig object notation of build.zig.zon. Structs
+// become maps, tuples slices, and strings, enum literals and other
+// literals strings.
+type zonParser struct {
+	src string
+	pos int
+}
+
+func parseZON(src string) interface{} {
+	p := &zonParser{src: src}
+	return p.value()
+}
+
+func (p *zonParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], "//"):
+			if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+				p.pos += i
+			} else {
+				p.pos = len(p.src)
+			}
+		case strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0:
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *zonParser) value() interface{} {
+	p.skipSpace()
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, ".{"):
+		p.pos += 2
+		return p.container()
+	case strings.HasPrefix(rest, `"`):
+		return p.str()
+	case strings.HasPrefix(rest, `\\`):
+		// A multiline string; each line starts with \\.
+		var lines []string
+		for strings.HasPrefix(p.src[p.pos:], `\\`) {
+			end := strings.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				end = len(p.src) - p.pos
+			}
+			lines = append(lines, p.src[p.pos+2:p.pos+end])
+			p.pos += end
+			p.skipSpace()
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte(",}\n", p.src[p.pos]) < 0 {
+		p.pos++
+	}
+	return strings.TrimPrefix(strings.TrimSpace(p.src[start:p.pos]), ".")
+}
+
+// container reads the fields of a struct, .{ .name = value, ... }, or the
+// elements of a tuple, .{ value, ... }, after the opening .{.
+func (p *zonParser) container() interface{} {
+	fields := make(map[string]interface{})
+	var elems []interface{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		if p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+
+		if name, ok := p.fieldName(); ok {
+			fields[name] = p.value()
+		} else {
+			before := p.pos
+			elems = append(elems, p.value())
+			if p.pos == before {
+				p.pos++
+			}
+		}
+	}
+	if len(elems) > 0 && len(fields) == 0 {
+		return elems
+	}
+	return fields
+}
+
+// fieldName reads ".name =" or `.@"name" =`.
+func (p *zonParser) fieldName() (string, bool) {
+	start := p.pos
+	if p.pos >= len(p.src) || p.src[p.pos] != '.' {
+		return "", false
+	}
+	p.pos++
+
+	var name string
+	if strings.HasPrefix(p.src[p.pos:], `@"`) {
+		p.pos++
+		name = p.str()
+	} else {
+		begin := p.pos
+		for p.pos < len(p.src) && (isBareZONChar(p.src[p.pos])) {
+			p.pos++
+		}
+		name = p.src[begin:p.pos]
+	}
+
+	p.skipSpace()
+	if name == "" || p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		p.pos = start
+		return "", false
+	}
+	p.pos++
+	return name, true
+}
+
+func isBareZONChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (p *zonParser) str() string {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) && p.src[p.pos] != '"' {
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	p.pos++
+	if p.pos > len(p.src) {
+		p.pos = len(p.src)
+	}
+	s, err := strconv.Unquote(p.src[start:p.pos])
+	if err != nil {
+		return strings.Trim(p.src[start:p.pos], `"`)
+	}
+	return s
+}
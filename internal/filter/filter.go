@@ -0,0 +1,285 @@
+// Package filter parses the expressions used to select projects, such as
+// `status:active tech:go progress<50 updated>30d has:git`.
+//
+// An expression is a list of terms that must all match. A term is a field,
+// an operator and a value; values may be quoted and separated by commas to
+// match any of them, and a leading "-" negates a term. A word without a
+// field matches the name, description or path of a project.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Op compares a field with the value of a term.
+type Op string
+
+const (
+	OpMatch  Op = ":"
+	OpLess   Op = "<"
+	OpLessEq Op = "<="
+	OpMore   Op = ">"
+	OpMoreEq Op = ">="
+)
+
+// Kind tells how the values of a field are read.
+type Kind int
+
+const (
+	// KindText fields take any text.
+	KindText Kind = iota
+	// KindEnum fields take one of a fixed set of values.
+	KindEnum
+	// KindNumber fields take integers and can be compared.
+	KindNumber
+	// KindTime fields take a duration before now (30d, 2w, 12h) or a date
+	// (2006-01-02) and can be compared; updated>30d means updated in the
+	// last 30 days, as does updated:30d.
+	KindTime
+)
+
+// Field describes a field that terms may use.
+type Field struct {
+	Kind   Kind
+	Values []string
+}
+
+// Fields lists the fields of project filters.
+var Fields = map[string]Field{
+	"status":   {Kind: KindEnum, Values: []string{"active", "paused", "archived", "completed"}},
+	"tech":     {Kind: KindText},
+	"name":     {Kind: KindText},
+	"path":     {Kind: KindText},
+	"remote":   {Kind: KindText},
+	"branch":   {Kind: KindText},
+	"progress": {Kind: KindNumber},
+	"updated":  {Kind: KindTime},
+	"created":  {Kind: KindTime},
+	"scanned":  {Kind: KindTime},
+	"has":      {Kind: KindEnum, Values: []string{"git", "remote", "description", "notes", "readme", "todos", "tasks", "analysis", "parent", "missing"}},
+}
+
+// Text is the field of words written without one.
+const Text = "text"
+
+// Term is one condition of a filter. Numbers and Times hold the parsed
+// Values of number and time fields.
+type Term struct {
+	Field   string
+	Op      Op
+	Values  []string
+	Numbers []int
+	Times   []time.Time
+	Negate  bool
+}
+
+// Filter is a parsed expression. The zero value matches everything.
+type Filter struct {
+	Terms []Term
+}
+
+// Empty reports whether the filter has no term.
+func (f *Filter) Empty() bool {
+	return f == nil || len(f.Terms) == 0
+}
+
+// Parse reads an expression. Times are relative to now.
+func Parse(expr string, now time.Time) (*Filter, error) {
+	f := &Filter{}
+	for _, word := range split(expr) {
+		term, err := parseTerm(word, now)
+		if err != nil {
+			return nil, err
+		}
+		f.Terms = append(f.Terms, term)
+	}
+	return f, nil
+}
+
+// Add appends the terms of expr to the filter, as Parse reads them.
+func (f *Filter) Add(expr string, now time.Time) error {
+	more, err := Parse(expr, now)
+	if err != nil {
+		return err
+	}
+	f.Terms = append(f.Terms, more.Terms...)
+	return nil
+}
+
+func parseTerm(word string, now time.Time) (Term, error) {
+	term := Term{Field: Text, Op: OpMatch}
+	text := word
+	if strings.HasPrefix(text, "-") && len(text) > 1 {
+		term.Negate = true
+		text = text[1:]
+	}
+
+	name, op, value := splitTerm(text)
+	if op == "" {
+		term.Values = []string{unquote(text)}
+		return term, nil
+	}
+
+	field, ok := Fields[strings.ToLower(name)]
+	if !ok {
+		return term, fmt.Errorf("unknown filter field %q in %q (fields: %s)", name, word, fieldNames())
+	}
+	term.Field = strings.ToLower(name)
+	term.Op = op
+	if op != OpMatch && field.Kind != KindNumber && field.Kind != KindTime {
+		return term, fmt.Errorf("%s: field %s can only be matched with ':'", word, term.Field)
+	}
+
+	for _, v := range splitValues(value) {
+		if v == "" {
+			return term, fmt.Errorf("%s: missing value", word)
+		}
+		switch field.Kind {
+		case KindEnum:
+			v = strings.ToLower(v)
+			if !contains(field.Values, v) {
+				return term, fmt.Errorf("%s: %s must be one of %s", word, term.Field, strings.Join(field.Values, ", "))
+			}
+		case KindNumber:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return term, fmt.Errorf("%s: %s must be a number", word, term.Field)
+			}
+			term.Numbers = append(term.Numbers, n)
+		case KindTime:
+			t, err := ParseTime(v, now)
+			if err != nil {
+				return term, fmt.Errorf("%s: %w", word, err)
+			}
+			term.Times = append(term.Times, t)
+		}
+		term.Values = append(term.Values, v)
+	}
+	return term, nil
+}
+
+// splitTerm splits field, operator and value at the first operator outside
+// quotes. op is empty for a word without a field.
+func splitTerm(word string) (name string, op Op, value string) {
+	for i, r := range word {
+		if r == '"' {
+			return "", "", ""
+		}
+		switch r {
+		case ':':
+			return word[:i], OpMatch, word[i+1:]
+		case '<', '>':
+			op = Op(r)
+			rest := word[i+1:]
+			if strings.HasPrefix(rest, "=") {
+				op += "="
+				rest = rest[1:]
+			}
+			return word[:i], op, rest
+		}
+		if !unicode.IsLetter(r) {
+			return "", "", ""
+		}
+	}
+	return "", "", ""
+}
+
+// split splits an expression on spaces outside double quotes.
+func split(expr string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			word.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// splitValues splits a value on commas outside double quotes and unquotes
+// each part.
+func splitValues(value string) []string {
+	var values []string
+	var part strings.Builder
+	quoted := false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			part.WriteRune(r)
+		case r == ',' && !quoted:
+			values = append(values, unquote(part.String()))
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+	return append(values, unquote(part.String()))
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldNames() string {
+	var names []string
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ParseTime reads a point in time: a number of days (7d), weeks (2w) or
+// any Go duration (12h, 90m) before now, or a date (2006-01-02). An empty
+// value is the zero time.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s (use a duration such as 7d, 2w or 12h, or a date such as 2006-01-02)", value)
+}
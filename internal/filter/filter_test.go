@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		expr string
+		want []Term
+	}{
+		{"", nil},
+		{
+			"status:active,Paused tech:go",
+			[]Term{
+				{Field: "status", Op: OpMatch, Values: []string{"active", "paused"}},
+				{Field: "tech", Op: OpMatch, Values: []string{"go"}},
+			},
+		},
+		{
+			"progress<50 progress>=10",
+			[]Term{
+				{Field: "progress", Op: OpLess, Values: []string{"50"}, Numbers: []int{50}},
+				{Field: "progress", Op: OpMoreEq, Values: []string{"10"}, Numbers: []int{10}},
+			},
+		},
+		{
+			"updated>30d created<2024-01-15",
+			[]Term{
+				{Field: "updated", Op: OpMore, Values: []string{"30d"}, Times: []time.Time{now.Add(-30 * 24 * time.Hour)}},
+				{Field: "created", Op: OpLess, Values: []string{"2024-01-15"}, Times: []time.Time{time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)}},
+			},
+		},
+		{
+			`-has:git remote:github.com/acme name:"my app" "key:value"`,
+			[]Term{
+				{Field: "has", Op: OpMatch, Values: []string{"git"}, Negate: true},
+				{Field: "remote", Op: OpMatch, Values: []string{"github.com/acme"}},
+				{Field: "name", Op: OpMatch, Values: []string{"my app"}},
+				{Field: Text, Op: OpMatch, Values: []string{"key:value"}},
+			},
+		},
+		{"dotfiles -", []Term{{Field: Text, Op: OpMatch, Values: []string{"dotfiles"}}, {Field: Text, Op: OpMatch, Values: []string{"-"}}}},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.expr, now)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(f.Terms, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.expr, f.Terms, tt.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"owner:me",
+		"status:done",
+		"has:website",
+		"progress:half",
+		"tech>go",
+		"updated>soon",
+		"status:",
+		"status:active,",
+	} {
+		if _, err := Parse(expr, time.Now()); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"yesterday", "-3d", "2024-13-01"} {
+		if _, err := ParseTime(value, now); err == nil {
+			t.Errorf("ParseTime(%q) succeeded, want an error", value)
+		}
+	}
+}
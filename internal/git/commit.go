@@ -0,0 +1,116 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is a parsed commit object.
+type Commit struct {
+	Hash      string
+	Tree      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Message   string
+}
+
+// Signature records who authored or committed a commit, and when.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// Subject returns the first line of the commit message.
+func (c *Commit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimLeft(c.Message, "\n"), "\n")
+	return strings.TrimSpace(subject)
+}
+
+// Commit reads the commit with the given hash.
+func (r *Repository) Commit(hash string) (*Commit, error) {
+	s, err := r.store()
+	if err != nil {
+		return nil, err
+	}
+	typ, data, err := s.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objCommit {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", hash, typ)
+	}
+
+	c := parseCommit(data)
+	c.Hash = hash
+	return c, nil
+}
+
+// parseCommit decodes the headers and message of a commit object. Headers
+// other than the tree, parents, author and committer, such as signatures,
+// are skipped along with their continuation lines.
+func parseCommit(data []byte) *Commit {
+	c := &Commit{}
+	header, message, _ := strings.Cut(string(data), "\n\n")
+	for _, line := range strings.Split(header, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			c.Tree = value
+		case "parent":
+			c.Parents = append(c.Parents, value)
+		case "author":
+			c.Author = parseSignature(value)
+		case "committer":
+			c.Committer = parseSignature(value)
+		}
+	}
+	c.Message = message
+	return c
+}
+
+// parseSignature decodes "Name <email> <unix time> <+hhmm offset>". Like
+// git, it accepts signatures with a missing or malformed date.
+func parseSignature(s string) Signature {
+	var sig Signature
+	open, end := strings.IndexByte(s, '<'), strings.LastIndexByte(s, '>')
+	if open < 0 || end < open {
+		sig.Name = strings.TrimSpace(s)
+		return sig
+	}
+	sig.Name = strings.TrimSpace(s[:open])
+	sig.Email = s[open+1 : end]
+	sig.When = parseGitTime(s[end+1:])
+	return sig
+}
+
+// parseGitTime decodes a raw git date, "1700000000 +0100", keeping its
+// time zone offset.
+func parseGitTime(s string) time.Time {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	when := time.Unix(seconds, 0)
+
+	if len(fields) > 1 && len(fields[1]) == 5 {
+		zone := fields[1]
+		hours, errH := strconv.Atoi(zone[1:3])
+		minutes, errM := strconv.Atoi(zone[3:])
+		if errH == nil && errM == nil && (zone[0] == '+' || zone[0] == '-') {
+			offset := hours*3600 + minutes*60
+			if zone[0] == '-' {
+				offset = -offset
+			}
+			return when.In(time.FixedZone("", offset))
+		}
+	}
+	return when.UTC()
+}
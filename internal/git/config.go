@@ -0,0 +1,281 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Config is a parsed git configuration file.
+type Config struct {
+	entries []configEntry
+}
+
+type configEntry struct {
+	section    string
+	subsection string
+	key        string
+	value      string
+	// implicit marks a key written without "=", which git reads as true.
+	implicit bool
+}
+
+// ParseConfig decodes a git configuration file. Section and key names are
+// case insensitive; subsection names, as in [remote "origin"], are not.
+// Values may be quoted, contain escapes and continue on the next line
+// after a backslash.
+func ParseConfig(data []byte) (*Config, error) {
+	c := &Config{}
+	src := string(data)
+	section, subsection := "", ""
+	line := 1
+
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '\n':
+			line++
+			i++
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+		case ch == '#' || ch == ';':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case ch == '[':
+			var err error
+			section, subsection, i, err = parseSectionHeader(src, i+1)
+			if err != nil {
+				return nil, fmt.Errorf("config line %d: %w", line, err)
+			}
+		case isConfigAlpha(ch):
+			if section == "" {
+				return nil, fmt.Errorf("config line %d: key outside of a section", line)
+			}
+			start := i
+			for i < len(src) && (isConfigAlpha(src[i]) || isConfigDigit(src[i]) || src[i] == '-') {
+				i++
+			}
+			entry := configEntry{
+				section:    section,
+				subsection: subsection,
+				key:        strings.ToLower(src[start:i]),
+				implicit:   true,
+			}
+			for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+				i++
+			}
+			if i < len(src) && src[i] == '=' {
+				value, next, lines, err := parseConfigValue(src, i+1)
+				if err != nil {
+					return nil, fmt.Errorf("config line %d: %w", line, err)
+				}
+				entry.value, entry.implicit = value, false
+				i = next
+				line += lines
+			} else if i < len(src) && !strings.ContainsRune("\r\n#;", rune(src[i])) {
+				return nil, fmt.Errorf("config line %d: invalid key %q", line, src[start:i+1])
+			}
+			c.entries = append(c.entries, entry)
+		default:
+			return nil, fmt.Errorf("config line %d: unexpected %q", line, ch)
+		}
+	}
+	return c, nil
+}
+
+// parseSectionHeader reads a section header after its opening bracket and
+// returns the index following the closing one. The deprecated [section.sub]
+// form lower cases the subsection, as git does.
+func parseSectionHeader(src string, i int) (section, subsection string, next int, err error) {
+	start := i
+	for i < len(src) && (isConfigAlpha(src[i]) || isConfigDigit(src[i]) || src[i] == '-' || src[i] == '.') {
+		i++
+	}
+	name := strings.ToLower(src[start:i])
+	if name == "" {
+		return "", "", i, fmt.Errorf("empty section name")
+	}
+
+	if i < len(src) && src[i] == ']' {
+		if dot := strings.IndexByte(name, '.'); dot >= 0 {
+			return name[:dot], name[dot+1:], i + 1, nil
+		}
+		return name, "", i + 1, nil
+	}
+
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+		i++
+	}
+	if i >= len(src) || src[i] != '"' {
+		return "", "", i, fmt.Errorf("invalid section header [%s", name)
+	}
+	i++
+	var sub strings.Builder
+	for ; i < len(src) && src[i] != '"'; i++ {
+		if src[i] == '\n' {
+			return "", "", i, fmt.Errorf("unterminated subsection in [%s", name)
+		}
+		if src[i] == '\\' && i+1 < len(src) && src[i+1] != '\n' {
+			i++
+		}
+		sub.WriteByte(src[i])
+	}
+	if i+1 >= len(src) || src[i+1] != ']' {
+		return "", "", i, fmt.Errorf("unterminated section header [%s", name)
+	}
+	return name, sub.String(), i + 2, nil
+}
+
+// parseConfigValue reads a value after its "=" and returns the index of the
+// end of its line and the number of line continuations it spans.
+func parseConfigValue(src string, i int) (value string, next, lines int, err error) {
+	var b strings.Builder
+	quoted, started := false, false
+	// Whitespace outside quotes is kept only when more of the value follows.
+	pending := ""
+
+	for i < len(src) {
+		ch := src[i]
+		switch {
+		case ch == '\n':
+			if quoted {
+				return "", i, lines, fmt.Errorf("unterminated quote")
+			}
+			return b.String(), i, lines, nil
+		case ch == '\r' && i+1 < len(src) && src[i+1] == '\n':
+			i++
+		case !quoted && (ch == '#' || ch == ';'):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case !quoted && (ch == ' ' || ch == '\t'):
+			if started {
+				pending += string(ch)
+			}
+			i++
+		case ch == '\\':
+			if i+1 >= len(src) {
+				return "", i, lines, fmt.Errorf("trailing backslash")
+			}
+			escaped := src[i+1]
+			i += 2
+			switch escaped {
+			case '\n':
+				lines++
+				continue
+			case '\r':
+				if i < len(src) && src[i] == '\n' {
+					i++
+					lines++
+					continue
+				}
+				return "", i, lines, fmt.Errorf("invalid escape")
+			case 'n':
+				escaped = '\n'
+			case 't':
+				escaped = '\t'
+			case 'b':
+				escaped = '\b'
+			case '"', '\\':
+			default:
+				return "", i, lines, fmt.Errorf("invalid escape \\%c", escaped)
+			}
+			b.WriteString(pending)
+			pending = ""
+			b.WriteByte(escaped)
+			started = true
+		case ch == '"':
+			b.WriteString(pending)
+			pending = ""
+			quoted = !quoted
+			started = true
+			i++
+		default:
+			b.WriteString(pending)
+			pending = ""
+			b.WriteByte(ch)
+			started = true
+			i++
+		}
+	}
+	if quoted {
+		return "", i, lines, fmt.Errorf("unterminated quote")
+	}
+	return b.String(), i, lines, nil
+}
+
+func isConfigAlpha(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func isConfigDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// Get returns the last value of key, or "". An empty subsection selects
+// keys outside of any subsection.
+func (c *Config) Get(section, subsection, key string) string {
+	values := c.GetAll(section, subsection, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// GetAll returns every value of a multivalued key, in file order.
+func (c *Config) GetAll(section, subsection, key string) []string {
+	var values []string
+	for _, e := range c.lookup(section, subsection, key) {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// Bool reports whether the last value of key is true. A key without a
+// value is true.
+func (c *Config) Bool(section, subsection, key string) bool {
+	entries := c.lookup(section, subsection, key)
+	if len(entries) == 0 {
+		return false
+	}
+	last := entries[len(entries)-1]
+	if last.implicit {
+		return true
+	}
+	switch strings.ToLower(last.value) {
+	case "true", "yes", "on":
+		return true
+	}
+	n, err := strconv.Atoi(last.value)
+	return err == nil && n != 0
+}
+
+// Subsections returns the subsections of section, such as the remote
+// names of "remote", in the order they first appear.
+func (c *Config) Subsections(section string) []string {
+	section = strings.ToLower(section)
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range c.entries {
+		if e.section == section && e.subsection != "" && !seen[e.subsection] {
+			seen[e.subsection] = true
+			names = append(names, e.subsection)
+		}
+	}
+	return names
+}
+
+func (c *Config) lookup(section, subsection, key string) []configEntry {
+	if c == nil {
+		return nil
+	}
+	section, key = strings.ToLower(section), strings.ToLower(key)
+	var found []configEntry
+	for _, e := range c.entries {
+		if e.section == section && e.subsection == subsection && e.key == key {
+			found = append(found, e)
+		}
+	}
+	return found
+}
@@ -0,0 +1,200 @@
+// Package git reads git repositories directly from their files: the
+// configuration and remotes, HEAD and refs, and the commit history with its
+// authors and activity over time.
+//
+// Loose objects and pack files are read natively, for both SHA-1 and
+// SHA-256 repositories. Worktrees and submodules, whose .git is a file
+// pointing at the real git directory, are followed. The git command is only
+// run when the files cannot be read directly, such as for repositories
+// storing refs in a reftable, and for the working tree status.
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository is returned by Open for a directory that is not the top
+// of a git working tree.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Repository is a git repository opened from its working tree.
+type Repository struct {
+	// WorkTree is the top directory of the working tree.
+	WorkTree string
+	// GitDir holds the files of the working tree itself, such as HEAD. It
+	// differs from CommonDir for linked worktrees.
+	GitDir string
+	// CommonDir holds the files shared by all worktrees: objects, refs and
+	// the configuration.
+	CommonDir string
+
+	config  *Config
+	objects *objectStore
+}
+
+// Open opens the repository whose working tree is rooted at dir. The .git
+// entry of dir is either the git directory or, for worktrees and
+// submodules, a file naming it with a "gitdir:" line.
+func Open(dir string) (*Repository, error) {
+	workTree, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dotGit := filepath.Join(workTree, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return nil, ErrNotRepository
+	}
+
+	gitDir := dotGit
+	if !info.IsDir() {
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return nil, err
+		}
+		line := strings.TrimSpace(string(data))
+		if !strings.HasPrefix(line, "gitdir:") {
+			return nil, ErrNotRepository
+		}
+		gitDir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(workTree, gitDir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, ErrNotRepository
+	}
+
+	// Linked worktrees name the main git directory in a commondir file.
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	return &Repository{
+		WorkTree:  workTree,
+		GitDir:    filepath.Clean(gitDir),
+		CommonDir: filepath.Clean(commonDir),
+	}, nil
+}
+
+// Close releases the pack files opened while reading objects.
+func (r *Repository) Close() error {
+	if r.objects == nil {
+		return nil
+	}
+	return r.objects.close()
+}
+
+// Config returns the repository configuration, including the per-worktree
+// configuration when the repository enables it. User and system wide
+// configuration files are not read.
+func (r *Repository) Config() (*Config, error) {
+	if r.config != nil {
+		return r.config, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.CommonDir, "config"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if config.Bool("extensions", "", "worktreeConfig") {
+		if data, err := os.ReadFile(filepath.Join(r.GitDir, "config.worktree")); err == nil {
+			worktree, err := ParseConfig(data)
+			if err != nil {
+				return nil, err
+			}
+			config.entries = append(config.entries, worktree.entries...)
+		}
+	}
+
+	r.config = config
+	return config, nil
+}
+
+// Remote is a configured remote repository.
+type Remote struct {
+	Name string
+	URL  string
+}
+
+// Remotes returns the remotes with a URL, in the order they are configured.
+func (r *Repository) Remotes() ([]Remote, error) {
+	config, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	var remotes []Remote
+	for _, name := range config.Subsections("remote") {
+		// Fetches use the first URL of a remote.
+		if urls := config.GetAll("remote", name, "url"); len(urls) > 0 && urls[0] != "" {
+			remotes = append(remotes, Remote{Name: name, URL: urls[0]})
+		}
+	}
+	return remotes, nil
+}
+
+// Remote returns the URL of the remote the current branch tracks, else of
+// the remote named origin, else of the first remote. It returns "" when
+// the repository has no remote.
+func (r *Repository) Remote() string {
+	remotes, err := r.Remotes()
+	if err != nil || len(remotes) == 0 {
+		return ""
+	}
+
+	preferred := []string{"origin"}
+	if branch := r.Branch(); branch != "" {
+		config, _ := r.Config()
+		if name := config.Get("branch", branch, "remote"); name != "" {
+			preferred = append([]string{name}, preferred...)
+		}
+	}
+	for _, name := range preferred {
+		for _, remote := range remotes {
+			if remote.Name == name {
+				return remote.URL
+			}
+		}
+	}
+	return remotes[0].URL
+}
+
+// HasChanges reports whether the working tree has uncommitted changes or
+// untracked files. It runs git status, since comparing the working tree
+// with the index needs the ignore rules and filters of git itself.
+func (r *Repository) HasChanges() (bool, error) {
+	out, err := r.command("status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+// command runs git in the working tree and returns its standard output.
+func (r *Repository) command(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.WorkTree}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
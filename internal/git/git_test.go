@@ -0,0 +1,471 @@
+package git
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	doc := `
+# comment
+[core]
+	repositoryformatversion = 0
+	bare = false
+	logAllRefUpdates
+[remote "origin"]
+	url = git@github.com:me/app.git ; trailing comment
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[remote "Upstream"]
+	url = "https://example.com/up stream.git"
+	url = https://mirror.example.com/up.git
+[branch "main"]
+	remote = origin
+[branch.Legacy]
+	merge = refs/heads/legacy
+[alias]
+	lg = "log --graph \
+--oneline"
+	quoted = "  kept  "   # spaces inside quotes stay
+	inner = a  b	c
+	escapes = "tab\there \"q\" back\\slash"
+`
+	config, err := ParseConfig([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		section, subsection, key string
+		expected                 string
+	}{
+		{"core", "", "repositoryformatversion", "0"},
+		{"CORE", "", "Bare", "false"},
+		{"remote", "origin", "url", "git@github.com:me/app.git"},
+		{"remote", "Upstream", "url", "https://mirror.example.com/up.git"},
+		{"remote", "upstream", "url", ""},
+		{"branch", "main", "remote", "origin"},
+		{"branch", "legacy", "merge", "refs/heads/legacy"},
+		{"alias", "", "lg", "log --graph --oneline"},
+		{"alias", "", "quoted", "  kept  "},
+		{"alias", "", "inner", "a  b\tc"},
+		{"alias", "", "escapes", "tab\there \"q\" back\\slash"},
+	}
+	for _, tt := range tests {
+		if got := config.Get(tt.section, tt.subsection, tt.key); got != tt.expected {
+			t.Errorf("Get(%q, %q, %q) = %q, want %q", tt.section, tt.subsection, tt.key, got, tt.expected)
+		}
+	}
+
+	if !config.Bool("core", "", "logallrefupdates") || config.Bool("core", "", "bare") {
+		t.Error("Bool() did not read an implicit true and an explicit false")
+	}
+	urls := config.GetAll("remote", "Upstream", "url")
+	if !reflect.DeepEqual(urls, []string{"https://example.com/up stream.git", "https://mirror.example.com/up.git"}) {
+		t.Errorf("GetAll() = %q", urls)
+	}
+	if got := config.Subsections("remote"); !reflect.DeepEqual(got, []string{"origin", "Upstream"}) {
+		t.Errorf("Subsections() = %q", got)
+	}
+
+	for _, bad := range []string{"key = outside", "[core\n", "[core]\n\tname = \"open\n", "[core]\n\tname = bad\\q"} {
+		if _, err := ParseConfig([]byte(bad)); err == nil {
+			t.Errorf("ParseConfig(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestRemote(t *testing.T) {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	writeFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+	config := `[remote "fork"]
+	url = https://example.com/fork.git
+[remote "origin"]
+	url = https://example.com/origin.git
+`
+	writeFile(t, filepath.Join(gitDir, "config"), config)
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := repo.Remote(); got != "https://example.com/origin.git" {
+		t.Errorf("Remote() = %q, want origin", got)
+	}
+
+	writeFile(t, filepath.Join(gitDir, "config"), config+"[branch \"main\"]\n\tremote = fork\n")
+	repo, _ = Open(dir)
+	if got := repo.Remote(); got != "https://example.com/fork.git" {
+		t.Errorf("Remote() = %q, want the remote main tracks", got)
+	}
+
+	// An unborn branch resolves to its ref without a commit.
+	ref, hash, err := repo.Head()
+	if err != nil || ref != "refs/heads/main" || hash != "" {
+		t.Errorf("Head() = %q, %q, %v, want refs/heads/main without a commit", ref, hash, err)
+	}
+	if commits, err := repo.Log(LogOptions{}); err != nil || len(commits) != 0 {
+		t.Errorf("Log() = %d commits, %v, want none", len(commits), err)
+	}
+
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("Open() of a plain directory error = %v, want ErrNotRepository", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	dir := newTestRepo(t)
+	run(t, dir, "remote", "add", "origin", "https://example.com/app.git")
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer repo.Close()
+
+	if repo.Branch() != "main" || repo.Remote() != "https://example.com/app.git" {
+		t.Errorf("Branch(), Remote() = %q, %q", repo.Branch(), repo.Remote())
+	}
+	checkHistory(t, dir, repo)
+
+	// Packing moves refs to packed-refs and stores objects as deltas.
+	run(t, dir, "gc", "--aggressive", "--prune=now", "--quiet")
+	if _, err := os.Stat(filepath.Join(dir, ".git", "refs", "heads", "main")); err == nil {
+		t.Fatal("git gc left refs/heads/main loose")
+	}
+	packed, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer packed.Close()
+	checkHistory(t, dir, packed)
+
+	// Every object of the pack reads back to content matching its name.
+	store, err := packed.store()
+	if err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+	for _, line := range strings.Split(run(t, dir, "rev-list", "--objects", "--all"), "\n") {
+		hash, _, _ := strings.Cut(line, " ")
+		typ, data, err := store.read(hash)
+		if err != nil {
+			t.Fatalf("read(%s) error = %v", hash, err)
+		}
+		sum := sha1.Sum(append([]byte(fmt.Sprintf("%s %d\x00", typ, len(data))), data...))
+		if hex.EncodeToString(sum[:]) != hash {
+			t.Errorf("read(%s) returned a %s hashing to %x", hash, typ, sum)
+		}
+	}
+	if len(store.packs) == 0 {
+		t.Error("no pack was read")
+	}
+}
+
+// checkHistory compares what repo reads with what git reports for the test
+// repository built by newTestRepo.
+func checkHistory(t *testing.T, dir string, repo *Repository) {
+	t.Helper()
+
+	_, head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	commits, err := repo.walk(head, LogOptions{})
+	if err != nil {
+		t.Fatalf("walk() error = %v", err)
+	}
+	var hashes []string
+	for _, c := range commits {
+		hashes = append(hashes, c.Hash)
+	}
+	if expected := strings.Fields(run(t, dir, "rev-list", "HEAD")); !reflect.DeepEqual(hashes, expected) {
+		t.Errorf("walk() = %q, want git rev-list order %q", hashes, expected)
+	}
+
+	fromCLI, err := repo.logFromCLI(LogOptions{})
+	if err != nil {
+		t.Fatalf("logFromCLI() error = %v", err)
+	}
+	if len(fromCLI) != len(commits) {
+		t.Fatalf("logFromCLI() returned %d commits, want %d", len(fromCLI), len(commits))
+	}
+	for i, c := range commits {
+		if strings.Join(c.Parents, " ") != strings.Join(fromCLI[i].Parents, " ") || c.Author.Email != fromCLI[i].Author.Email ||
+			!c.Committer.When.Equal(fromCLI[i].Committer.When) || c.Message != fromCLI[i].Message {
+			t.Errorf("commit %s read natively as %+v, by git log as %+v", c.Hash, c, fromCLI[i])
+		}
+	}
+
+	if commits[0].Subject() != "Merge branch 'feature'" {
+		t.Errorf("Subject() = %q", commits[0].Subject())
+	}
+	if _, offset := commits[0].Author.When.Zone(); offset != 2*3600 {
+		t.Errorf("author time zone offset = %d, want +0200", offset)
+	}
+
+	if files, err := repo.ChangedFiles(commits[0]); err != nil || !reflect.DeepEqual(files, []string{"feature.txt"}) {
+		t.Errorf("ChangedFiles(merge) = %q, %v, want the files merged in", files, err)
+	}
+	if files, err := repo.ChangedFiles(commits[len(commits)-1]); err != nil || !reflect.DeepEqual(files, []string{"data.txt"}) {
+		t.Errorf("ChangedFiles(root) = %q, %v, want every file", files, err)
+	}
+
+	root, err := repo.RootCommit()
+	if expected := strings.TrimSpace(run(t, dir, "rev-list", "--first-parent", "--max-parents=0", "HEAD")); err != nil || root != expected {
+		t.Errorf("RootCommit() = %q, %v, want %q", root, err, expected)
+	}
+
+	feature := commits[0].Parents[1]
+	if ok, err := repo.IsAncestor(feature, head); err != nil || !ok {
+		t.Errorf("IsAncestor(merged branch, HEAD) = %v, %v, want true", ok, err)
+	}
+	if ok, err := repo.IsAncestor(head, root); err != nil || ok {
+		t.Errorf("IsAncestor(HEAD, root) = %v, %v, want false", ok, err)
+	}
+
+	last, err := repo.LastCommitTime()
+	if err != nil || !last.Equal(testDay.Add(5*24*time.Hour)) {
+		t.Errorf("LastCommitTime() = %v, %v", last, err)
+	}
+
+	recent, err := repo.Log(LogOptions{Since: testDay.Add(3 * 24 * time.Hour), Limit: 10})
+	if err != nil || len(recent) != 3 {
+		t.Errorf("Log(since day 3) = %d commits, %v, want 3", len(recent), err)
+	}
+
+	authors, err := repo.Authors(time.Time{})
+	if err != nil {
+		t.Fatalf("Authors() error = %v", err)
+	}
+	expectedAuthors := []Author{
+		{Name: "Ada", Email: "ada@example.com", Commits: 4, LastCommit: testDay.Add(5 * 24 * time.Hour)},
+		{Name: "Bob Baker", Email: "bob@example.com", Commits: 2, LastCommit: testDay.Add(4 * 24 * time.Hour)},
+	}
+	for i := range authors {
+		authors[i].LastCommit = authors[i].LastCommit.UTC()
+	}
+	if !reflect.DeepEqual(authors, expectedAuthors) {
+		t.Errorf("Authors() = %+v, want %+v", authors, expectedAuthors)
+	}
+
+	counts, err := repo.CommitCounts(testDay.Add(6*24*time.Hour), 2*24*time.Hour, 4)
+	if err != nil || !reflect.DeepEqual(counts, []int{1, 2, 2, 1}) {
+		t.Errorf("CommitCounts() = %v, %v, want [1 2 2 1]", counts, err)
+	}
+}
+
+func TestWorktreeAndSubmodule(t *testing.T) {
+	dir := newTestRepo(t)
+	run(t, dir, "remote", "add", "origin", "https://example.com/app.git")
+
+	worktree := filepath.Join(t.TempDir(), "wt")
+	run(t, dir, "worktree", "add", "--quiet", "-b", "topic", worktree)
+	commitFile(t, worktree, "topic.txt", "topic\n", "Start topic", "Ada <ada@example.com>", testDay.Add(7*24*time.Hour))
+
+	repo, err := Open(worktree)
+	if err != nil {
+		t.Fatalf("Open(worktree) error = %v", err)
+	}
+	defer repo.Close()
+	if repo.CommonDir != filepath.Join(dir, ".git") {
+		t.Errorf("CommonDir = %q, want the main git directory", repo.CommonDir)
+	}
+	if repo.Branch() != "topic" || repo.Remote() != "https://example.com/app.git" {
+		t.Errorf("Branch(), Remote() = %q, %q", repo.Branch(), repo.Remote())
+	}
+	commits, err := repo.Log(LogOptions{Limit: 2})
+	if err != nil || len(commits) != 2 || commits[0].Subject() != "Start topic" {
+		t.Fatalf("Log() in worktree = %v, %v", commits, err)
+	}
+
+	// The main working tree still sees its own branch.
+	main, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if main.Branch() != "main" {
+		t.Errorf("Branch() of the main worktree = %q", main.Branch())
+	}
+
+	run(t, dir, "-c", "protocol.file.allow=always", "submodule", "--quiet", "add", worktree, "lib")
+	sub, err := Open(filepath.Join(dir, "lib"))
+	if err != nil {
+		t.Fatalf("Open(submodule) error = %v", err)
+	}
+	defer sub.Close()
+	if sub.GitDir != filepath.Join(dir, ".git", "modules", "lib") {
+		t.Errorf("GitDir of the submodule = %q", sub.GitDir)
+	}
+	_, hash, err := sub.Head()
+	if err != nil || hash != commits[0].Hash {
+		t.Errorf("Head() of the submodule = %q, %v, want %q", hash, err, commits[0].Hash)
+	}
+	if commits, err := sub.Log(LogOptions{}); err != nil || len(commits) != 7 {
+		t.Errorf("Log() of the submodule = %d commits, %v, want 7", len(commits), err)
+	}
+}
+
+func TestBranchesAndUpstream(t *testing.T) {
+	origin := newTestRepo(t)
+	clone := filepath.Join(t.TempDir(), "clone")
+	run(t, origin, "clone", "--quiet", origin, clone)
+
+	// One commit on each side leaves the clone one ahead and one behind.
+	commitFile(t, clone, "src/app/main.go", "package main\n", "Add main", "Ada <ada@example.com>", testDay.Add(6*24*time.Hour))
+	commitFile(t, origin, "data.txt", "rewritten\n", "Rewrite data", "Bob Baker <bob@example.com>", testDay.Add(6*24*time.Hour))
+	run(t, clone, "fetch", "--quiet")
+	run(t, clone, "branch", "topic")
+	for _, content := range []string{"first\n", "second\n"} {
+		writeFile(t, filepath.Join(clone, "data.txt"), content)
+		run(t, clone, "stash", "--quiet")
+	}
+
+	repo, err := Open(clone)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer repo.Close()
+
+	upstream := repo.Upstream()
+	if upstream != "refs/remotes/origin/main" {
+		t.Fatalf("Upstream() = %q, want refs/remotes/origin/main", upstream)
+	}
+	if ahead, behind, err := repo.AheadBehind(upstream); err != nil || ahead != 1 || behind != 1 {
+		t.Errorf("AheadBehind() = %d, %d, %v, want 1, 1", ahead, behind, err)
+	}
+	if count, err := repo.StashCount(); err != nil || count != 2 {
+		t.Errorf("StashCount() = %d, %v, want 2", count, err)
+	}
+
+	commits, err := repo.Log(LogOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if files, err := repo.ChangedFiles(commits[0]); err != nil || !reflect.DeepEqual(files, []string{"src/app/main.go"}) {
+		t.Errorf("ChangedFiles() = %q, %v", files, err)
+	}
+
+	// Branches are listed whether their refs are loose or packed.
+	for _, packed := range []bool{false, true} {
+		if packed {
+			run(t, clone, "pack-refs", "--all")
+		}
+		branches, err := repo.Branches()
+		if err != nil || !reflect.DeepEqual(branches, []string{"main", "topic"}) {
+			t.Errorf("Branches() with packed refs %v = %q, %v", packed, branches, err)
+		}
+	}
+
+	if ref, ok := mapRefspec("+refs/heads/*:refs/remotes/up/*", "refs/heads/feature/x"); !ok || ref != "refs/remotes/up/feature/x" {
+		t.Errorf("mapRefspec() = %q, %v", ref, ok)
+	}
+	if _, ok := mapRefspec("refs/heads/main:refs/remotes/up/main", "refs/heads/dev"); ok {
+		t.Error("mapRefspec() mapped a ref the refspec does not name")
+	}
+}
+
+// testDay is when the first commit of the test repository was made.
+var testDay = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// newTestRepo builds a repository whose main branch merges a feature
+// branch, with one commit a day by two authors.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	run(t, dir, "init", "--quiet", "-b", "main")
+
+	// A file changed a little by each commit lets git gc store deltas.
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf("line %d of the shared file", i))
+	}
+	change := func(i int) string {
+		lines[i*10] = fmt.Sprintf("line %d changed by commit %d", i*10, i)
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	ada, bob := "Ada <ada@example.com>", "Bob Baker <bob@example.com>"
+	commitFile(t, dir, "data.txt", change(0), "Initial commit", ada, testDay)
+	commitFile(t, dir, "data.txt", change(1), "Second", ada, testDay.Add(24*time.Hour))
+	run(t, dir, "checkout", "--quiet", "-b", "feature")
+	commitFile(t, dir, "feature.txt", "feature\n", "Add feature", bob, testDay.Add(2*24*time.Hour))
+	commitFile(t, dir, "feature.txt", "feature v2\n", "Improve feature", bob, testDay.Add(4*24*time.Hour))
+	run(t, dir, "checkout", "--quiet", "main")
+	commitFile(t, dir, "data.txt", change(2), "Third", ada, testDay.Add(3*24*time.Hour))
+
+	date := gitDate(testDay.Add(5 * 24 * time.Hour))
+	cmd := exec.Command("git", "merge", "--quiet", "--no-ff", "--no-edit", "feature")
+	cmd.Dir = dir
+	cmd.Env = testEnv(ada, date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git merge: %v\n%s", err, out)
+	}
+	return dir
+}
+
+func commitFile(t *testing.T, dir, name, content, message, author string, when time.Time) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, name), content)
+	run(t, dir, "add", name)
+	cmd := exec.Command("git", "commit", "--quiet", "-m", message)
+	cmd.Dir = dir
+	cmd.Env = testEnv(author, gitDate(when))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+}
+
+// gitDate formats a time as a raw git date in the +0200 time zone.
+func gitDate(when time.Time) string {
+	return fmt.Sprintf("%d +0200", when.Unix())
+}
+
+func testEnv(author, date string) []string {
+	name, email, _ := strings.Cut(author, " <")
+	email = strings.TrimSuffix(email, ">")
+	return append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME="+name, "GIT_AUTHOR_EMAIL="+email, "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME="+name, "GIT_COMMITTER_EMAIL="+email, "GIT_COMMITTER_DATE="+date,
+	)
+}
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = testEnv("Test <test@example.com>", gitDate(testDay))
+	out, err := cmd.Output()
+	if err != nil {
+		var stderr []byte
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = exitErr.Stderr
+		}
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, stderr)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,370 @@
+package git
+
+import (
+	"container/heap"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogOptions selects the commits returned by Log.
+type LogOptions struct {
+	// Since excludes the commits committed before it.
+	Since time.Time
+	// Limit caps the number of commits; zero returns them all.
+	Limit int
+}
+
+// Log returns the commits reachable from HEAD, the most recently committed
+// first, as git log lists them. A branch without commits has an empty
+// history. When the history cannot be read from the repository files, git
+// log is run instead.
+func (r *Repository) Log(opts LogOptions) ([]*Commit, error) {
+	_, head, err := r.Head()
+	if err == nil {
+		if head == "" {
+			return nil, nil
+		}
+		var commits []*Commit
+		if commits, err = r.walk(head, opts); err == nil {
+			return commits, nil
+		}
+	}
+	if commits, cliErr := r.logFromCLI(opts); cliErr == nil {
+		return commits, nil
+	}
+	return nil, err
+}
+
+// walk visits the history from start in committer date order.
+func (r *Repository) walk(start string, opts LogOptions) ([]*Commit, error) {
+	shallow := r.shallowCommits()
+
+	queue := &commitQueue{}
+	seen := map[string]bool{start: true}
+	push := func(hash string) error {
+		c, err := r.Commit(hash)
+		if err != nil {
+			return err
+		}
+		// The parents of the commits at the edge of a shallow clone were
+		// not fetched; git treats those commits as roots.
+		if shallow[hash] {
+			c.Parents = nil
+		}
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := push(start); err != nil {
+		return nil, err
+	}
+
+	var commits []*Commit
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(*Commit)
+		if !opts.Since.IsZero() && c.Committer.When.Before(opts.Since) {
+			break
+		}
+		commits = append(commits, c)
+		if opts.Limit > 0 && len(commits) >= opts.Limit {
+			break
+		}
+		for _, parent := range c.Parents {
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			if err := push(parent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return commits, nil
+}
+
+// shallowCommits returns the commits at the edge of a shallow clone.
+func (r *Repository) shallowCommits() map[string]bool {
+	shallow := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(r.CommonDir, "shallow"))
+	if err != nil {
+		return shallow
+	}
+	for _, hash := range strings.Fields(string(data)) {
+		shallow[hash] = true
+	}
+	return shallow
+}
+
+// commitQueue orders commits newest first; commits committed at the same
+// time keep the order they were queued in.
+type commitQueue struct {
+	commits []*Commit
+	order   []int
+	next    int
+}
+
+func (q *commitQueue) Len() int { return len(q.commits) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	ti, tj := q.commits[i].Committer.When, q.commits[j].Committer.When
+	if !ti.Equal(tj) {
+		return ti.After(tj)
+	}
+	return q.order[i] < q.order[j]
+}
+
+func (q *commitQueue) Swap(i, j int) {
+	q.commits[i], q.commits[j] = q.commits[j], q.commits[i]
+	q.order[i], q.order[j] = q.order[j], q.order[i]
+}
+
+func (q *commitQueue) Push(x interface{}) {
+	q.commits = append(q.commits, x.(*Commit))
+	q.order = append(q.order, q.next)
+	q.next++
+}
+
+func (q *commitQueue) Pop() interface{} {
+	n := len(q.commits) - 1
+	c := q.commits[n]
+	q.commits, q.order = q.commits[:n], q.order[:n]
+	return c
+}
+
+// logFormat separates the fields of a commit with unit separators; the
+// message comes last since it may span lines.
+const logFormat = "%H%x1f%T%x1f%P%x1f%an%x1f%ae%x1f%ad%x1f%cn%x1f%ce%x1f%cd%x1f%B"
+
+func (r *Repository) logFromCLI(opts LogOptions) ([]*Commit, error) {
+	args := []string{"log", "-z", "--date=raw", "--format=" + logFormat}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since="+opts.Since.Format(time.RFC3339))
+	}
+	if opts.Limit > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(opts.Limit))
+	}
+	out, err := r.command(append(args, "HEAD", "--")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*Commit
+	for _, record := range strings.Split(string(out), "\x00") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 10)
+		if len(fields) != 10 {
+			return nil, fmt.Errorf("git log: unexpected output")
+		}
+		commits = append(commits, &Commit{
+			Hash:      fields[0],
+			Tree:      fields[1],
+			Parents:   strings.Fields(fields[2]),
+			Author:    Signature{Name: fields[3], Email: fields[4], When: parseGitTime(fields[5])},
+			Committer: Signature{Name: fields[6], Email: fields[7], When: parseGitTime(fields[8])},
+			Message:   fields[9],
+		})
+	}
+	return commits, nil
+}
+
+// LastCommitTime returns when the commit HEAD points to was committed, or
+// the zero time on a branch without commits.
+func (r *Repository) LastCommitTime() (time.Time, error) {
+	commits, err := r.Log(LogOptions{Limit: 1})
+	if err != nil || len(commits) == 0 {
+		return time.Time{}, err
+	}
+	return commits[0].Committer.When, nil
+}
+
+// RootCommit returns the first commit of the history of HEAD, or "" on a
+// branch without commits. Only first parents are followed, so merging an
+// unrelated history does not change it, and only the commits on that chain
+// are read. Forks and clones share it regardless of their remote.
+func (r *Repository) RootCommit() (string, error) {
+	_, head, err := r.Head()
+	if err == nil {
+		if head == "" {
+			return "", nil
+		}
+		var root string
+		if root, err = r.firstParentRoot(head); err == nil {
+			return root, nil
+		}
+	}
+	out, cliErr := r.command("rev-list", "--first-parent", "--max-parents=0", "HEAD", "--")
+	if cliErr == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+	return "", err
+}
+
+// firstParentRoot follows the first parents of start up to a commit without
+// parents, or one at the edge of a shallow clone.
+func (r *Repository) firstParentRoot(start string) (string, error) {
+	shallow := r.shallowCommits()
+	hash := start
+	for {
+		c, err := r.Commit(hash)
+		if err != nil {
+			return "", err
+		}
+		if len(c.Parents) == 0 || shallow[hash] {
+			return hash, nil
+		}
+		hash = c.Parents[0]
+	}
+}
+
+// IsAncestor reports whether ancestor is reachable from commit. Only the
+// commits committed since ancestor are read.
+func (r *Repository) IsAncestor(ancestor, commit string) (bool, error) {
+	if ancestor == commit {
+		return true, nil
+	}
+	a, err := r.Commit(ancestor)
+	if err != nil {
+		return false, err
+	}
+	commits, err := r.walk(commit, LogOptions{Since: a.Committer.When})
+	if err != nil {
+		return false, err
+	}
+	for _, c := range commits {
+		if c.Hash == ancestor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Author is someone who authored commits.
+type Author struct {
+	Name       string
+	Email      string
+	Commits    int
+	LastCommit time.Time
+}
+
+// Authors returns who authored the commits made since the given time, or
+// the whole history when since is zero, the most prolific first. Commits
+// are grouped by email address, under the name the author used last.
+func (r *Repository) Authors(since time.Time) ([]Author, error) {
+	commits, err := r.Log(LogOptions{Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	byEmail := make(map[string]*Author)
+	var authors []*Author
+	for _, c := range commits {
+		key := strings.ToLower(c.Author.Email)
+		if key == "" {
+			key = c.Author.Name
+		}
+		a := byEmail[key]
+		if a == nil {
+			a = &Author{Name: c.Author.Name, Email: c.Author.Email}
+			byEmail[key] = a
+			authors = append(authors, a)
+		}
+		a.Commits++
+		if c.Author.When.After(a.LastCommit) {
+			a.Name, a.LastCommit = c.Author.Name, c.Author.When
+		}
+	}
+
+	result := make([]Author, len(authors))
+	for i, a := range authors {
+		result[i] = *a
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Commits > result[j].Commits
+	})
+	return result, nil
+}
+
+// CommitCounts returns how many commits were committed in each of the n
+// periods of the given length ending at end, the most recent first.
+func (r *Repository) CommitCounts(end time.Time, period time.Duration, n int) ([]int, error) {
+	if n <= 0 || period <= 0 {
+		return nil, fmt.Errorf("invalid period")
+	}
+	commits, err := r.Log(LogOptions{Since: end.Add(-period * time.Duration(n))})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, n)
+	for _, c := range commits {
+		age := end.Sub(c.Committer.When)
+		if age < 0 {
+			continue
+		}
+		if i := int(age / period); i < n {
+			counts[i]++
+		}
+	}
+	return counts, nil
+}
+
+// AheadBehind counts the commits reachable from HEAD but not from ref, and
+// those reachable from ref but not from HEAD.
+func (r *Repository) AheadBehind(ref string) (ahead, behind int, err error) {
+	_, head, err := r.Head()
+	if err != nil {
+		return 0, 0, err
+	}
+	target, err := r.ResolveRef(ref)
+	if err != nil {
+		return 0, 0, err
+	}
+	if head == "" || head == target {
+		return 0, 0, nil
+	}
+
+	ours, err := r.walk(head, LogOptions{})
+	if err != nil {
+		return r.aheadBehindFromCLI(ref)
+	}
+	theirs, err := r.walk(target, LogOptions{})
+	if err != nil {
+		return r.aheadBehindFromCLI(ref)
+	}
+
+	inTheirs := make(map[string]bool, len(theirs))
+	for _, c := range theirs {
+		inTheirs[c.Hash] = true
+	}
+	inOurs := make(map[string]bool, len(ours))
+	for _, c := range ours {
+		inOurs[c.Hash] = true
+		if !inTheirs[c.Hash] {
+			ahead++
+		}
+	}
+	for _, c := range theirs {
+		if !inOurs[c.Hash] {
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+func (r *Repository) aheadBehindFromCLI(ref string) (ahead, behind int, err error) {
+	out, err := r.command("rev-list", "--left-right", "--count", "HEAD..."+ref, "--")
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscan(string(out), &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("git rev-list: unexpected output %q", out)
+	}
+	return ahead, behind, nil
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errObjectNotFound is returned for objects missing from the repository.
+var errObjectNotFound = errors.New("object not found")
+
+type objectType int
+
+const (
+	objCommit   objectType = 1
+	objTree     objectType = 2
+	objBlob     objectType = 3
+	objTag      objectType = 4
+	objOfsDelta objectType = 6
+	objRefDelta objectType = 7
+)
+
+var objectTypeNames = map[objectType]string{
+	objCommit: "commit",
+	objTree:   "tree",
+	objBlob:   "blob",
+	objTag:    "tag",
+}
+
+func (t objectType) String() string {
+	if name, ok := objectTypeNames[t]; ok {
+		return name
+	}
+	return "object type " + strconv.Itoa(int(t))
+}
+
+// maxAlternateDepth bounds chains of alternate object directories, as git
+// does.
+const maxAlternateDepth = 5
+
+// objectStore reads objects from the objects directory of a repository and
+// from its alternates.
+type objectStore struct {
+	dirs     []string
+	hashSize int
+
+	packs       []*packFile
+	packsLoaded bool
+}
+
+// store returns the object store of the repository, opening it on first use.
+func (r *Repository) store() (*objectStore, error) {
+	if r.objects != nil {
+		return r.objects, nil
+	}
+
+	config, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+	hashSize := sha1.Size
+	switch format := strings.ToLower(config.Get("extensions", "", "objectFormat")); format {
+	case "", "sha1":
+	case "sha256":
+		hashSize = sha256.Size
+	default:
+		return nil, fmt.Errorf("unsupported object format %q", format)
+	}
+
+	r.objects = &objectStore{
+		dirs:     objectDirs(filepath.Join(r.CommonDir, "objects"), 0),
+		hashSize: hashSize,
+	}
+	return r.objects, nil
+}
+
+// objectDirs returns dir followed by the alternate object directories it
+// borrows objects from, as clones made with --reference or --shared do.
+func objectDirs(dir string, depth int) []string {
+	dirs := []string{dir}
+	data, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if err != nil || depth >= maxAlternateDepth {
+		return dirs
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		dirs = append(dirs, objectDirs(filepath.Clean(line), depth+1)...)
+	}
+	return dirs
+}
+
+// read returns the type and content of an object.
+func (s *objectStore) read(hash string) (objectType, []byte, error) {
+	name, err := hex.DecodeString(hash)
+	if err != nil || len(name) != s.hashSize {
+		return 0, nil, fmt.Errorf("invalid object name %q", hash)
+	}
+
+	for _, dir := range s.dirs {
+		typ, data, err := readLooseObject(filepath.Join(dir, hash[:2], hash[2:]))
+		if err == nil {
+			return typ, data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return 0, nil, fmt.Errorf("object %s: %w", hash, err)
+		}
+	}
+
+	if err := s.loadPacks(); err != nil {
+		return 0, nil, err
+	}
+	for _, p := range s.packs {
+		if offset, ok := p.find(name); ok {
+			typ, data, err := p.readAt(s, offset, 0)
+			if err != nil {
+				return 0, nil, fmt.Errorf("object %s: %w", hash, err)
+			}
+			return typ, data, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: %s", errObjectNotFound, hash)
+}
+
+func (s *objectStore) close() error {
+	var first error
+	for _, p := range s.packs {
+		if err := p.close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// readLooseObject reads a zlib compressed object file, made of a
+// "<type> <size>" header, a NUL byte and the content.
+func readLooseObject(path string) (objectType, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	nul := bytes.IndexByte(data, 0)
+	if nul < 0 {
+		return 0, nil, fmt.Errorf("malformed object header")
+	}
+	typeName, sizeText, _ := strings.Cut(string(data[:nul]), " ")
+	size, err := strconv.Atoi(sizeText)
+	if err != nil || size != len(data)-nul-1 {
+		return 0, nil, fmt.Errorf("malformed object header %q", data[:nul])
+	}
+	for typ, name := range objectTypeNames {
+		if name == typeName {
+			return typ, data[nul+1:], nil
+		}
+	}
+	return 0, nil, fmt.Errorf("unknown object type %q", typeName)
+}
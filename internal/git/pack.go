@@ -0,0 +1,298 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxDeltaDepth bounds chains of deltas; git never writes chains longer
+// than 4095 objects.
+const maxDeltaDepth = 4096
+
+// maxObjectSize bounds the objects inflated into memory.
+const maxObjectSize = 1 << 30
+
+// packFile is a pack of objects with its version 2 index.
+type packFile struct {
+	path     string
+	index    []byte
+	count    int
+	hashSize int
+	file     *os.File
+}
+
+// loadPacks reads the index of every pack in the object directories.
+func (s *objectStore) loadPacks() error {
+	if s.packsLoaded {
+		return nil
+	}
+	s.packsLoaded = true
+
+	for _, dir := range s.dirs {
+		indexes, _ := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+		for _, index := range indexes {
+			p, err := openPackIndex(index, s.hashSize)
+			if err != nil {
+				return err
+			}
+			s.packs = append(s.packs, p)
+		}
+	}
+	return nil
+}
+
+// openPackIndex reads a pack index: a header, a fan-out table counting the
+// objects by first byte, then the sorted object names, their CRCs, their
+// offsets in the pack and the offsets too large for 31 bits.
+func openPackIndex(path string, hashSize int) (*packFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	const header = 8 + 256*4
+	if len(data) < header || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:]) != 2 {
+		return nil, fmt.Errorf("%s: unsupported pack index version", path)
+	}
+	count := int(binary.BigEndian.Uint32(data[header-4:]))
+	if len(data) < header+count*(hashSize+8) {
+		return nil, fmt.Errorf("%s: truncated pack index", path)
+	}
+
+	return &packFile{
+		path:     strings.TrimSuffix(path, ".idx") + ".pack",
+		index:    data,
+		count:    count,
+		hashSize: hashSize,
+	}, nil
+}
+
+// find returns the offset in the pack of the named object.
+func (p *packFile) find(name []byte) (int64, bool) {
+	fanout := p.index[8:]
+	lo := 0
+	if name[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(name[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(name[0])*4:]))
+	if hi > p.count || lo > hi {
+		return 0, false
+	}
+
+	names := p.index[8+256*4:]
+	nameAt := func(i int) []byte { return names[i*p.hashSize : (i+1)*p.hashSize] }
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(nameAt(lo+i), name) >= 0
+	})
+	if i >= hi || !bytes.Equal(nameAt(i), name) {
+		return 0, false
+	}
+
+	offsets := names[p.count*(p.hashSize+4):]
+	offset := binary.BigEndian.Uint32(offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+	large := offsets[p.count*4:]
+	j := int(offset &^ 0x80000000)
+	if len(large) < (j+1)*8 {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(large[j*8:])), true
+}
+
+// readAt reads the object stored at offset, resolving deltas against their
+// base objects.
+func (p *packFile) readAt(s *objectStore, offset int64, depth int) (objectType, []byte, error) {
+	if depth > maxDeltaDepth {
+		return 0, nil, fmt.Errorf("delta chain too long")
+	}
+	if p.file == nil {
+		f, err := os.Open(p.path)
+		if err != nil {
+			return 0, nil, err
+		}
+		p.file = f
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(p.file, offset, 1<<62))
+
+	// The header packs the type and a variable length size.
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	typ := objectType(c >> 4 & 7)
+	size := uint64(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= uint64(c&0x7f) << shift
+	}
+
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+		data, err := inflate(r, size)
+		return typ, data, err
+
+	case objOfsDelta:
+		// The base is an earlier object of the same pack, at a distance
+		// written big endian with an offset added to each continuation.
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			distance = (distance+1)<<7 | int64(c&0x7f)
+		}
+		if distance <= 0 || distance > offset {
+			return 0, nil, fmt.Errorf("invalid delta base offset at %d", offset)
+		}
+		delta, err := inflate(r, size)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err := p.readAt(s, offset-distance, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+
+	case objRefDelta:
+		// The base is named, and may live in another pack or loose.
+		baseName := make([]byte, p.hashSize)
+		if _, err := io.ReadFull(r, baseName); err != nil {
+			return 0, nil, err
+		}
+		delta, err := inflate(r, size)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err := s.read(hex.EncodeToString(baseName))
+		if err != nil {
+			return 0, nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+	}
+
+	return 0, nil, fmt.Errorf("unknown object type %d at offset %d", typ, offset)
+}
+
+func (p *packFile) close() error {
+	if p.file == nil {
+		return nil
+	}
+	err := p.file.Close()
+	p.file = nil
+	return err
+}
+
+// inflate decompresses an object of the given size.
+func inflate(r io.Reader, size uint64) ([]byte, error) {
+	if size > maxObjectSize {
+		return nil, fmt.Errorf("object too large (%d bytes)", size)
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta: the sizes of
+// the base and the result, then instructions copying ranges of the base or
+// inserting new bytes.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	baseSize, delta, ok := deltaSize(delta)
+	if !ok || baseSize != uint64(len(base)) {
+		return nil, fmt.Errorf("delta does not match its base")
+	}
+	resultSize, delta, ok := deltaSize(delta)
+	if !ok || resultSize > maxObjectSize {
+		return nil, fmt.Errorf("malformed delta")
+	}
+
+	result := make([]byte, 0, resultSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			// Copy: bits 0-3 select the offset bytes present, bits 4-6
+			// the size bytes.
+			var offset, size uint64
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("truncated delta")
+				}
+				if i < 4 {
+					offset |= uint64(delta[0]) << (8 * i)
+				} else {
+					size |= uint64(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > uint64(len(base)) {
+				return nil, fmt.Errorf("delta copies outside of its base")
+			}
+			result = append(result, base[offset:offset+size]...)
+		case op != 0:
+			// Insert the next op bytes.
+			n := int(op)
+			if n > len(delta) {
+				return nil, fmt.Errorf("truncated delta")
+			}
+			result = append(result, delta[:n]...)
+			delta = delta[n:]
+		default:
+			return nil, fmt.Errorf("invalid delta instruction")
+		}
+	}
+
+	if uint64(len(result)) != resultSize {
+		return nil, fmt.Errorf("delta result has the wrong size")
+	}
+	return result, nil
+}
+
+// deltaSize reads a little endian variable length size from the start of
+// a delta.
+func deltaSize(delta []byte) (uint64, []byte, bool) {
+	var size uint64
+	for shift := 0; len(delta) > 0 && shift < 64; shift += 7 {
+		c := delta[0]
+		delta = delta[1:]
+		size |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return size, delta, true
+		}
+	}
+	return 0, nil, false
+}
@@ -0,0 +1,299 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrRefNotFound is returned when a ref does not exist.
+var ErrRefNotFound = errors.New("ref not found")
+
+// maxSymrefDepth bounds chains of symbolic refs, as git does.
+const maxSymrefDepth = 5
+
+// Head returns the ref HEAD points to and the commit it resolves to. ref is
+// "" when HEAD is detached, and hash is "" on a branch without commits.
+func (r *Repository) Head() (ref, hash string, err error) {
+	if r.reftable() {
+		return r.headFromCLI()
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+	head := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(head, "ref:") {
+		return "", head, nil
+	}
+
+	ref = strings.TrimSpace(strings.TrimPrefix(head, "ref:"))
+	hash, err = r.ResolveRef(ref)
+	if errors.Is(err, ErrRefNotFound) {
+		return ref, "", nil
+	}
+	return ref, hash, err
+}
+
+// Branch returns the name of the branch checked out, or "" when HEAD is
+// detached.
+func (r *Repository) Branch() string {
+	ref, _, err := r.Head()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
+// Branches returns the names of the local branches, sorted.
+func (r *Repository) Branches() ([]string, error) {
+	refs, err := r.refs("refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	for i, ref := range refs {
+		refs[i] = strings.TrimPrefix(ref, "refs/heads/")
+	}
+	return refs, nil
+}
+
+// Upstream returns the remote-tracking ref the current branch merges from,
+// such as refs/remotes/origin/main, or "" when it has none. The ref is
+// mapped through the fetch refspecs of the remote, as git does.
+func (r *Repository) Upstream() string {
+	branch := r.Branch()
+	config, err := r.Config()
+	if branch == "" || err != nil {
+		return ""
+	}
+	remote := config.Get("branch", branch, "remote")
+	merge := config.Get("branch", branch, "merge")
+	if remote == "" || merge == "" {
+		return ""
+	}
+	if remote == "." {
+		// The branch tracks another local branch.
+		return merge
+	}
+	for _, spec := range config.GetAll("remote", remote, "fetch") {
+		if ref, ok := mapRefspec(spec, merge); ok {
+			return ref
+		}
+	}
+	return ""
+}
+
+// mapRefspec maps a ref of a remote to the local ref a fetch refspec such
+// as +refs/heads/*:refs/remotes/origin/* stores it under.
+func mapRefspec(spec, ref string) (string, bool) {
+	src, dst, ok := strings.Cut(strings.TrimPrefix(spec, "+"), ":")
+	if !ok || dst == "" {
+		return "", false
+	}
+	star := strings.IndexByte(src, '*')
+	if star < 0 {
+		return dst, src == ref
+	}
+	prefix, suffix := src[:star], src[star+1:]
+	if len(ref) < len(prefix)+len(suffix) || !strings.HasPrefix(ref, prefix) || !strings.HasSuffix(ref, suffix) {
+		return "", false
+	}
+	return strings.Replace(dst, "*", ref[len(prefix):len(ref)-len(suffix)], 1), true
+}
+
+// StashCount returns the number of stashed changes, one per entry of the
+// stash reflog.
+func (r *Repository) StashCount() (int, error) {
+	if r.reftable() {
+		out, err := r.command("stash", "list")
+		if err != nil {
+			return 0, err
+		}
+		if list := strings.TrimSpace(string(out)); list != "" {
+			return strings.Count(list, "\n") + 1, nil
+		}
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.CommonDir, "logs", "refs", "stash"))
+	if err == nil {
+		count := 0
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) != "" {
+				count++
+			}
+		}
+		return count, nil
+	}
+	if !os.IsNotExist(err) {
+		return 0, err
+	}
+	// Without a reflog only the latest stash is known.
+	if _, err := r.ResolveRef("refs/stash"); err == nil {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// ResolveRef returns the object a full ref name such as refs/heads/main
+// points to, following symbolic refs. Loose refs take precedence over
+// packed-refs.
+func (r *Repository) ResolveRef(name string) (string, error) {
+	if r.reftable() {
+		out, err := r.command("rev-parse", "--verify", "--quiet", name)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrRefNotFound, name)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	for depth := 0; depth < maxSymrefDepth; depth++ {
+		target, err := r.readRef(name)
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(target, "ref:") {
+			return target, nil
+		}
+		name = strings.TrimSpace(strings.TrimPrefix(target, "ref:"))
+	}
+	return "", fmt.Errorf("ref %s: too many levels of symbolic refs", name)
+}
+
+// readRef returns the content of a ref: an object name, or "ref: " and the
+// name of another ref.
+func (r *Repository) readRef(name string) (string, error) {
+	dir := r.CommonDir
+	if perWorktreeRef(name) {
+		dir = r.GitDir
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return "", err
+	}
+	if hash, ok := packed[name]; ok {
+		return hash, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrRefNotFound, name)
+}
+
+// perWorktreeRef reports whether a ref is kept in the git directory of each
+// worktree rather than shared: HEAD and the other pseudo refs, and the
+// refs git keeps per worktree.
+func perWorktreeRef(name string) bool {
+	if !strings.Contains(name, "/") {
+		return true
+	}
+	for _, prefix := range []string{"refs/bisect/", "refs/worktree/", "refs/rewritten/"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// packedRefs reads the packed-refs file into a map from ref name to object
+// name. Peeled tag lines are skipped.
+func (r *Repository) packedRefs() (map[string]string, error) {
+	refs := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(r.CommonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		if hash, name, ok := strings.Cut(line, " "); ok {
+			refs[name] = hash
+		}
+	}
+	return refs, nil
+}
+
+// reftable reports whether refs are stored in the reftable format, which
+// is read through the git command.
+func (r *Repository) reftable() bool {
+	config, err := r.Config()
+	return err == nil && strings.EqualFold(config.Get("extensions", "", "refStorage"), "reftable")
+}
+
+func (r *Repository) headFromCLI() (ref, hash string, err error) {
+	// symbolic-ref fails for a detached HEAD and rev-parse for an unborn
+	// branch; neither is an error here.
+	if out, err := r.command("symbolic-ref", "--quiet", "HEAD"); err == nil {
+		ref = strings.TrimSpace(string(out))
+	}
+	if out, err := r.command("rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		hash = strings.TrimSpace(string(out))
+	}
+	if ref == "" && hash == "" {
+		return "", "", fmt.Errorf("cannot resolve HEAD in %s", r.WorkTree)
+	}
+	return ref, hash, nil
+}
+
+// refs returns the names of the refs under prefix, loose and packed,
+// sorted.
+func (r *Repository) refs(prefix string) ([]string, error) {
+	if r.reftable() {
+		out, err := r.command("for-each-ref", "--format=%(refname)", prefix)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(string(out)), nil
+	}
+
+	found := make(map[string]bool)
+	root := filepath.Join(r.CommonDir, filepath.FromSlash(prefix))
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() && !strings.HasSuffix(path, ".lock") {
+			rel, err := filepath.Rel(r.CommonDir, path)
+			if err != nil {
+				return err
+			}
+			found[filepath.ToSlash(rel)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+	for name := range packed {
+		if strings.HasPrefix(name, prefix) {
+			found[name] = true
+		}
+	}
+
+	refs := make([]string, 0, len(found))
+	for name := range found {
+		refs = append(refs, name)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// treeEntry is a file, directory or submodule listed in a tree object.
+type treeEntry struct {
+	mode string
+	name string
+	hash string
+}
+
+func (e treeEntry) isTree() bool {
+	return e.mode == "40000"
+}
+
+// readTree returns the entries of a tree object, each written as
+// "<mode> <name>", a NUL byte and the binary object name. The empty hash
+// stands for an empty tree.
+func (r *Repository) readTree(hash string) ([]treeEntry, error) {
+	if hash == "" {
+		return nil, nil
+	}
+	s, err := r.store()
+	if err != nil {
+		return nil, err
+	}
+	typ, data, err := s.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objTree {
+		return nil, fmt.Errorf("object %s is a %s, not a tree", hash, typ)
+	}
+
+	var entries []treeEntry
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+1+s.hashSize {
+			return nil, fmt.Errorf("malformed tree %s", hash)
+		}
+		entries = append(entries, treeEntry{
+			mode: string(data[:space]),
+			name: string(data[space+1 : nul]),
+			hash: hex.EncodeToString(data[nul+1 : nul+1+s.hashSize]),
+		})
+		data = data[nul+1+s.hashSize:]
+	}
+	return entries, nil
+}
+
+// ChangedFiles returns the sorted paths of the files a commit added,
+// modified or deleted compared to its first parent. For a root commit it
+// returns every file of the commit.
+func (r *Repository) ChangedFiles(c *Commit) ([]string, error) {
+	parentTree := ""
+	if len(c.Parents) > 0 {
+		parent, err := r.Commit(c.Parents[0])
+		if err != nil {
+			return r.changedFilesFromCLI(c)
+		}
+		parentTree = parent.Tree
+	}
+
+	changed := make(map[string]bool)
+	if err := r.diffTrees(parentTree, c.Tree, "", changed); err != nil {
+		return r.changedFilesFromCLI(c)
+	}
+	files := make([]string, 0, len(changed))
+	for file := range changed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// diffTrees adds to changed the paths under dir that differ between two
+// trees.
+func (r *Repository) diffTrees(oldTree, newTree, dir string, changed map[string]bool) error {
+	if oldTree == newTree {
+		return nil
+	}
+	oldEntries, err := r.readTree(oldTree)
+	if err != nil {
+		return err
+	}
+	newEntries, err := r.readTree(newTree)
+	if err != nil {
+		return err
+	}
+
+	old := make(map[string]treeEntry, len(oldEntries))
+	for _, e := range oldEntries {
+		old[e.name] = e
+	}
+	for _, e := range newEntries {
+		before, existed := old[e.name]
+		delete(old, e.name)
+		switch {
+		case existed && before == e:
+		case existed && before.isTree() && e.isTree():
+			if err := r.diffTrees(before.hash, e.hash, path.Join(dir, e.name), changed); err != nil {
+				return err
+			}
+		default:
+			if existed {
+				if err := r.addEntry(before, dir, changed); err != nil {
+					return err
+				}
+			}
+			if err := r.addEntry(e, dir, changed); err != nil {
+				return err
+			}
+		}
+	}
+	for _, removed := range old {
+		if err := r.addEntry(removed, dir, changed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addEntry adds a file to changed, or every file below a directory.
+func (r *Repository) addEntry(e treeEntry, dir string, changed map[string]bool) error {
+	if e.isTree() {
+		return r.diffTrees("", e.hash, path.Join(dir, e.name), changed)
+	}
+	changed[path.Join(dir, e.name)] = true
+	return nil
+}
+
+func (r *Repository) changedFilesFromCLI(c *Commit) ([]string, error) {
+	args := []string{"diff-tree", "-r", "-z", "--name-only", "--no-commit-id"}
+	if len(c.Parents) > 0 {
+		args = append(args, c.Parents[0], c.Hash)
+	} else {
+		args = append(args, "--root", c.Hash)
+	}
+	out, err := r.command(append(args, "--")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
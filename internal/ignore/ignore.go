@@ -0,0 +1,299 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Files read from every directory that is walked, in increasing precedence.
+const (
+	GitIgnoreFile  = ".gitignore"
+	PmemIgnoreFile = ".pmemignore"
+)
+
+// DefaultPatterns are applied to every project before any user supplied
+// pattern, so a .pmemignore entry such as "!dist/" can re-include them.
+var DefaultPatterns = []string{
+	".*",
+	"node_modules/",
+	"vendor/",
+	"target/",
+	"build/",
+	"dist/",
+	"__pycache__/",
+}
+
+var (
+	globalMu       sync.RWMutex
+	globalPatterns []string
+)
+
+// SetGlobalPatterns replaces the user-wide patterns applied after the
+// defaults and before any per-project ignore file.
+func SetGlobalPatterns(patterns []string) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalPatterns = append([]string(nil), patterns...)
+}
+
+// GlobalPatterns returns the patterns set with SetGlobalPatterns.
+func GlobalPatterns() []string {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return append([]string(nil), globalPatterns...)
+}
+
+type rule struct {
+	base    string
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// Matcher decides whether paths below a root directory are ignored,
+// following .gitignore semantics: the last matching pattern wins, a leading
+// "!" negates, a trailing "/" only matches directories and a pattern that
+// contains a slash is anchored to the directory that declared it.
+type Matcher struct {
+	root  string
+	rules []rule
+}
+
+// New returns a matcher for root loaded with the default and global
+// patterns plus root's own .gitignore and .pmemignore files.
+func New(root string) *Matcher {
+	m := &Matcher{root: filepath.Clean(root)}
+	m.AddPatterns("", DefaultPatterns...)
+	m.AddPatterns("", GlobalPatterns()...)
+	m.loadDir("")
+	return m
+}
+
+// AddPatterns appends patterns declared in the directory base, given
+// relative to the matcher root with forward slashes ("" for the root).
+func (m *Matcher) AddPatterns(base string, patterns ...string) {
+	for _, p := range patterns {
+		if r, ok := compile(base, p); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+}
+
+// Match reports whether path is ignored. The path may be absolute or
+// relative to the matcher root. A path is also ignored when any of its
+// parent directories is.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	rel := m.rel(path)
+	if rel == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchOne(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matchOne(rel, isDir)
+}
+
+func (m *Matcher) matchOne(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		target := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			target = strings.TrimPrefix(rel, r.base+"/")
+		}
+		if r.re.MatchString(target) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+func (m *Matcher) rel(path string) string {
+	if filepath.IsAbs(path) {
+		r, err := filepath.Rel(m.root, path)
+		if err != nil {
+			return ""
+		}
+		path = r
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == "." || strings.HasPrefix(path, "../") {
+		return ""
+	}
+	return path
+}
+
+// loadDir reads the ignore files found in the directory rel.
+func (m *Matcher) loadDir(rel string) {
+	dir := filepath.Join(m.root, filepath.FromSlash(rel))
+	for _, name := range []string{GitIgnoreFile, PmemIgnoreFile} {
+		patterns, err := ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			m.AddPatterns(rel, patterns...)
+		}
+	}
+}
+
+// ReadFile returns the patterns in an ignore file, skipping blank lines and
+// comments.
+func ReadFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, sc.Err()
+}
+
+// Walk walks the tree rooted at root like filepath.Walk, but never calls fn
+// for ignored paths and does not descend into ignored directories. Ignore
+// files in nested directories are honored as they are reached.
+func Walk(root string, fn filepath.WalkFunc) error {
+	return New(root).Walk(fn)
+}
+
+// WalkExcept is like Walk but does not descend into the directories in
+// exclude, such as the member projects below a workspace root.
+func WalkExcept(root string, exclude []string, fn filepath.WalkFunc) error {
+	if len(exclude) == 0 {
+		return Walk(root, fn)
+	}
+	skip := make(map[string]bool, len(exclude))
+	for _, dir := range exclude {
+		skip[filepath.Clean(dir)] = true
+	}
+	return Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() && skip[path] {
+			return filepath.SkipDir
+		}
+		return fn(path, info, err)
+	})
+}
+
+// Walk walks the matcher root, see the package level Walk.
+func (m *Matcher) Walk(fn filepath.WalkFunc) error {
+	return filepath.Walk(m.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fn(path, info, err)
+		}
+
+		rel := m.rel(path)
+		if rel != "" {
+			if m.Match(rel, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				m.loadDir(rel)
+			}
+		}
+
+		return fn(path, info, nil)
+	})
+}
+
+func compile(base, pattern string) (rule, bool) {
+	r := rule{base: base}
+
+	if strings.HasPrefix(pattern, `\#`) || strings.HasPrefix(pattern, `\!`) {
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return r, false
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expr := globToRegexp(pattern)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return r, false
+	}
+	r.re = re
+	return r, true
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				atStart := i == 0 || pattern[i-1] == '/'
+				atEnd := i+2 == len(pattern) || pattern[i+2] == '/'
+				if atStart && atEnd {
+					if i+2 == len(pattern) {
+						b.WriteString(".*")
+					} else {
+						b.WriteString("(?:.*/)?")
+						i++
+					}
+					i++
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{name: "build dir", patterns: []string{"build/"}, path: "build", isDir: true, expected: true},
+		{name: "file under build dir", patterns: []string{"build/"}, path: "build/out.js", expected: true},
+		{name: "builder is not build", patterns: []string{"build/"}, path: "src/builder/main.go", expected: false},
+		{name: "dir-only skips files", patterns: []string{"build/"}, path: "build", isDir: false, expected: false},
+		{name: "unanchored at any depth", patterns: []string{"*.log"}, path: "a/b/c.log", expected: true},
+		{name: "anchored to root", patterns: []string{"/todo.txt"}, path: "sub/todo.txt", expected: false},
+		{name: "anchored match", patterns: []string{"/todo.txt"}, path: "todo.txt", expected: true},
+		{name: "middle slash anchors", patterns: []string{"doc/*.txt"}, path: "x/doc/a.txt", expected: false},
+		{name: "double star prefix", patterns: []string{"**/fixtures"}, path: "a/b/fixtures", isDir: true, expected: true},
+		{name: "double star middle", patterns: []string{"a/**/z"}, path: "a/b/c/z", expected: true},
+		{name: "double star suffix", patterns: []string{"logs/**"}, path: "logs/x/y.txt", expected: true},
+		{name: "negation re-includes", patterns: []string{"*.md", "!README.md"}, path: "README.md", expected: false},
+		{name: "last match wins", patterns: []string{"!keep.md", "*.md"}, path: "keep.md", expected: true},
+		{name: "character class", patterns: []string{"file[0-9].txt"}, path: "file7.txt", expected: true},
+		{name: "negated class", patterns: []string{"file[!0-9].txt"}, path: "file7.txt", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Matcher{root: "/project"}
+			m.AddPatterns("", tt.patterns...)
+
+			if got := m.Match(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		".gitignore":              "*.tmp\n",
+		".pmemignore":             "# keep dist output\n!dist/\ngenerated/\n",
+		"main.go":                 "",
+		"scratch.tmp":             "",
+		"dist/app.js":             "",
+		"build/app.js":            "",
+		"src/builder/builder.go":  "",
+		"generated/code.go":       "",
+		"node_modules/x/index.js": "",
+		"sub/.gitignore":          "local.txt\n",
+		"sub/local.txt":           "",
+		"sub/kept.txt":            "",
+		"other/local.txt":         "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	SetGlobalPatterns([]string{"other/"})
+	defer SetGlobalPatterns(nil)
+
+	var got []string
+	err := Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		got = append(got, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() failed: %v", err)
+	}
+	sort.Strings(got)
+
+	expected := []string{"dist/app.js", "main.go", "src/builder/builder.go", "sub/kept.txt"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Walk() visited %v, want %v", got, expected)
+	}
+}
+
+func TestWalkExcept(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"go.work", "tools/gen.go", "services/api/main.go", "services/api-client/client.go"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	var got []string
+	exclude := []string{filepath.Join(root, "services", "api"), filepath.Join(root, "missing")}
+	err := WalkExcept(root, exclude, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		got = append(got, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkExcept() failed: %v", err)
+	}
+	sort.Strings(got)
+
+	expected := []string{"go.work", "services/api-client/client.go", "tools/gen.go"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("WalkExcept() visited %v, want %v", got, expected)
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type ProjectStatus string
 
@@ -25,15 +28,46 @@ type Project struct {
 	GitRemote     string        `json:"git_remote,omitempty"`
 	GitBranch     string        `json:"git_branch,omitempty"`
 	Notes         string        `json:"notes"`
+	RootCommit    string        `json:"root_commit,omitempty"`
+	MissingSince  *time.Time    `json:"missing_since,omitempty"`
+	ParentID      string        `json:"parent_id,omitempty"`
+	LastCommitAt  *time.Time    `json:"last_commit_at,omitempty"`
 }
 
+// DependencyScope tells whether a dependency is needed at runtime, only to
+// build the project, or only for development and tests.
+type DependencyScope string
+
+const (
+	ScopeProd  DependencyScope = "prod"
+	ScopeBuild DependencyScope = "build"
+	ScopeDev   DependencyScope = "dev"
+)
+
+// Technology is a runtime, framework, tool or dependency found in a project.
+// Type is one of "runtime", "framework", "build" or "dependency" for the
+// language ecosystems, and for infrastructure "container" (a base image,
+// Version being its tag), "service" (a Compose service), "deploy" (Docker
+// Compose, Kubernetes, Kustomize, Helm or Terraform), "provider" and
+// "module" (Terraform providers and modules) or "ci". Version is the exact
+// version when a lock file or pin resolves it, and DeclaredVersion the
+// constraint written in the manifest. Transitive dependencies come from
+// lock files only. Source names where a dependency is fetched from when it
+// is not its ecosystem's default registry, such as
+// "git+https://github.com/org/repo?tag=v1" or "path+../lib", and Features
+// lists the optional features the manifest enables.
 type Technology struct {
-	ID           int    `json:"id"`
-	ProjectID    string `json:"project_id"`
-	Type         string `json:"type"`
-	Name         string `json:"name"`
-	Version      string `json:"version,omitempty"`
-	DetectedFrom string `json:"detected_from"`
+	ID              int             `json:"id"`
+	ProjectID       string          `json:"project_id"`
+	Type            string          `json:"type"`
+	Name            string          `json:"name"`
+	Version         string          `json:"version,omitempty"`
+	DeclaredVersion string          `json:"declared_version,omitempty"`
+	Scope           DependencyScope `json:"scope,omitempty"`
+	Transitive      bool            `json:"transitive,omitempty"`
+	Source          string          `json:"source,omitempty"`
+	Features        []string        `json:"features,omitempty"`
+	DetectedFrom    string          `json:"detected_from"`
 }
 
 type ProjectFile struct {
@@ -43,17 +77,45 @@ type ProjectFile struct {
 	FileType     string    `json:"file_type"`
 	SizeBytes    int64     `json:"size_bytes"`
 	LastModified time.Time `json:"last_modified"`
+	ContentHash  string    `json:"content_hash,omitempty"`
+}
+
+// LanguageStat counts the files of a project written in one language and
+// their code, comment and blank lines.
+type LanguageStat struct {
+	ProjectID    string `json:"project_id,omitempty"`
+	Language     string `json:"language"`
+	Files        int    `json:"files"`
+	CodeLines    int    `json:"code_lines"`
+	CommentLines int    `json:"comment_lines"`
+	BlankLines   int    `json:"blank_lines"`
 }
 
+// TodoSource tells whether a Todo was found in a code comment or added by
+// hand as a task.
+type TodoSource string
+
+const (
+	TodoSourceCode   TodoSource = "code"
+	TodoSourceManual TodoSource = "manual"
+)
+
+// Todo is an item of work on a project. Items extracted from code comments
+// carry their Kind (TODO, FIXME, HACK or XXX), file and line, and are
+// completed automatically once the comment disappears. Manual tasks are
+// completed by the user.
 type Todo struct {
-	ID         int       `json:"id"`
-	ProjectID  string    `json:"project_id"`
-	Content    string    `json:"content"`
-	SourceFile string    `json:"source_file,omitempty"`
-	LineNumber int       `json:"line_number,omitempty"`
-	Priority   string    `json:"priority"`
-	Completed  bool      `json:"completed"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID          int        `json:"id"`
+	ProjectID   string     `json:"project_id"`
+	Source      TodoSource `json:"source"`
+	Content     string     `json:"content"`
+	Kind        string     `json:"kind,omitempty"`
+	SourceFile  string     `json:"source_file,omitempty"`
+	LineNumber  int        `json:"line_number,omitempty"`
+	Priority    string     `json:"priority"`
+	Completed   bool       `json:"completed"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
 
 type AIAnalysis struct {
@@ -66,10 +128,28 @@ type AIAnalysis struct {
 	AnalyzedAt   time.Time `json:"analyzed_at"`
 }
 
+// Actions recorded in the activity log.
+const (
+	ActionProjectAdded     = "project_added"
+	ActionProjectUpdated   = "project_updated"
+	ActionProjectRelocated = "project_relocated"
+	ActionProjectMissing   = "project_missing"
+	ActionProjectRemoved   = "project_removed"
+	ActionStatusChanged    = "status_changed"
+	ActionProgressChanged  = "progress_changed"
+	ActionNotesChanged     = "notes_changed"
+	ActionAnalyzed         = "ai_analysis"
+	ActionTaskAdded        = "task_added"
+	ActionTaskDone         = "task_done"
+)
+
+// ActivityLog is an entry of the history of a project. Details is a JSON
+// object whose fields depend on the action, such as "from" and "to" for
+// status changes.
 type ActivityLog struct {
-	ID        int       `json:"id"`
-	ProjectID string    `json:"project_id"`
-	Action    string    `json:"action"`
-	Details   string    `json:"details,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	ID        int             `json:"id"`
+	ProjectID string          `json:"project_id"`
+	Action    string          `json:"action"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
 }
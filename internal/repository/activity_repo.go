@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type ActivityRepository struct {
+	db DBTX
+}
+
+func NewActivityRepository(db DBTX) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+func (r *ActivityRepository) Create(entry *models.ActivityLog) error {
+	var details interface{}
+	if len(entry.Details) > 0 {
+		details = string(entry.Details)
+	}
+	result, err := r.db.Exec(`
+		INSERT INTO activity_log (project_id, action, details, timestamp)
+		VALUES (?, ?, ?, ?)
+	`, entry.ProjectID, entry.Action, details, entry.Timestamp.Unix())
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+// Record logs an action on a project now. details is marshaled to JSON and
+// may be nil.
+func (r *ActivityRepository) Record(projectID, action string, details interface{}) error {
+	entry := &models.ActivityLog{ProjectID: projectID, Action: action, Timestamp: time.Now()}
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		entry.Details = data
+	}
+	return r.Create(entry)
+}
+
+// RecordChanges logs the changes of status, progress and notes between two
+// versions of a project. by names what made them, such as a command.
+func (r *ActivityRepository) RecordChanges(before, after *models.Project, by string) error {
+	if before.Status != after.Status {
+		err := r.Record(after.ID, models.ActionStatusChanged, map[string]interface{}{
+			"from": before.Status, "to": after.Status, "by": by,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if before.Progress != after.Progress {
+		err := r.Record(after.ID, models.ActionProgressChanged, map[string]interface{}{
+			"from": before.Progress, "to": after.Progress, "by": by,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if before.Notes != after.Notes {
+		details := map[string]interface{}{"by": by}
+		// Notes are mostly appended to; only the addition is kept then.
+		if before.Notes != "" && strings.HasPrefix(after.Notes, before.Notes) {
+			details["appended"] = strings.TrimPrefix(after.Notes[len(before.Notes):], "\n")
+		} else {
+			details["from"], details["to"] = before.Notes, after.Notes
+		}
+		if err := r.Record(after.ID, models.ActionNotesChanged, details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the entries of a project, or of every project when
+// projectID is empty, logged at or after since, the most recent first. A
+// zero since returns the whole history and a limit of zero or less every
+// entry.
+func (r *ActivityRepository) List(projectID string, since time.Time, limit int) ([]models.ActivityLog, error) {
+	conditions := []string{"timestamp >= ?"}
+	args := []interface{}{since.Unix()}
+	if since.IsZero() {
+		args[0] = 0
+	}
+	if projectID != "" {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, projectID)
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, project_id, action, details, timestamp
+		FROM activity_log
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY timestamp DESC, id DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.ActivityLog
+	for rows.Next() {
+		var entry models.ActivityLog
+		var details sql.NullString
+		var timestamp int64
+		if err := rows.Scan(&entry.ID, &entry.ProjectID, &entry.Action, &details, &timestamp); err != nil {
+			return nil, err
+		}
+		if details.Valid && details.String != "" {
+			entry.Details = json.RawMessage(details.String)
+		}
+		entry.Timestamp = time.Unix(timestamp, 0)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/database"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestActivityRepository_RecordChanges(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewActivityRepository(db)
+	before := models.Project{ID: "p1", Status: models.StatusActive, Progress: 40, Notes: "first"}
+	after := before
+	after.Status = models.StatusCompleted
+	after.Progress = 100
+	after.Notes = "first\nsecond"
+
+	if err := repo.RecordChanges(&before, &after, "status"); err != nil {
+		t.Fatalf("RecordChanges() failed: %v", err)
+	}
+	if err := repo.RecordChanges(&after, &after, "status"); err != nil {
+		t.Fatalf("RecordChanges() failed: %v", err)
+	}
+
+	entries, err := repo.List("p1", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("List() returned %d entries, want 3: %+v", len(entries), entries)
+	}
+
+	want := map[string]map[string]interface{}{
+		models.ActionStatusChanged:   {"from": "active", "to": "completed", "by": "status"},
+		models.ActionProgressChanged: {"from": 40.0, "to": 100.0, "by": "status"},
+		models.ActionNotesChanged:    {"appended": "second", "by": "status"},
+	}
+	for _, e := range entries {
+		var details map[string]interface{}
+		if err := json.Unmarshal(e.Details, &details); err != nil {
+			t.Fatalf("details of %s are not JSON: %s", e.Action, e.Details)
+		}
+		w, ok := want[e.Action]
+		if !ok {
+			t.Errorf("unexpected action %s", e.Action)
+			continue
+		}
+		for key, value := range w {
+			if details[key] != value {
+				t.Errorf("%s details[%s] = %v, want %v", e.Action, key, details[key], value)
+			}
+		}
+	}
+}
+
+func TestActivityRepository_List(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewActivityRepository(db)
+	base := time.Unix(1700000000, 0)
+	for i, e := range []models.ActivityLog{
+		{ProjectID: "p1", Action: models.ActionProjectAdded, Timestamp: base},
+		{ProjectID: "p2", Action: models.ActionProjectAdded, Timestamp: base.Add(time.Hour)},
+		{ProjectID: "p1", Action: models.ActionAnalyzed, Details: json.RawMessage(`{"model":"m"}`), Timestamp: base.Add(48 * time.Hour)},
+		{ProjectID: "p1", Action: models.ActionTaskAdded, Timestamp: base.Add(48 * time.Hour)},
+	} {
+		if err := repo.Create(&e); err != nil || e.ID == 0 {
+			t.Fatalf("Create(%d) = %v, ID %d", i, err, e.ID)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		projectID string
+		since     time.Time
+		limit     int
+		want      []string
+	}{
+		{"project", "p1", time.Time{}, 0, []string{models.ActionTaskAdded, models.ActionAnalyzed, models.ActionProjectAdded}},
+		{"since", "p1", base.Add(24 * time.Hour), 0, []string{models.ActionTaskAdded, models.ActionAnalyzed}},
+		{"limit", "p1", time.Time{}, 1, []string{models.ActionTaskAdded}},
+		{"all projects", "", base.Add(time.Minute), 0, []string{models.ActionTaskAdded, models.ActionAnalyzed, models.ActionProjectAdded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := repo.List(tt.projectID, tt.since, tt.limit)
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Action)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("List() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	entries, _ := repo.List("p1", base.Add(24*time.Hour), 0)
+	if string(entries[1].Details) != `{"model":"m"}` || entries[0].Details != nil {
+		t.Errorf("details = %s and %s, want the JSON stored and none", entries[1].Details, entries[0].Details)
+	}
+}
+
+// TestActivityRepository_OutlivesProject checks, on the migrated schema,
+// that deleting a project keeps its entries.
+func TestActivityRepository_OutlivesProject(t *testing.T) {
+	conn, err := database.New(filepath.Join(t.TempDir(), "projects.db"))
+	if err != nil {
+		t.Fatalf("database.New() failed: %v", err)
+	}
+	defer conn.Close()
+	db := conn.Conn()
+
+	now := time.Now()
+	projectRepo := NewProjectRepository(db)
+	if err := projectRepo.Create(&models.Project{ID: "p1", Name: "gone", Path: "/src/gone", Status: models.StatusActive, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	repo := NewActivityRepository(db)
+	if err := repo.Record("p1", models.ActionProjectAdded, map[string]string{"path": "/src/gone"}); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	if err := repo.Record("p1", models.ActionProjectRemoved, map[string]string{"name": "gone", "path": "/src/gone"}); err != nil {
+		t.Fatalf("Record() failed: %v", err)
+	}
+	if err := projectRepo.Delete("p1"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	entries, err := repo.List("p1", time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != models.ActionProjectRemoved {
+		t.Errorf("List() after deleting the project = %+v, want both entries, the removal first", entries)
+	}
+}
@@ -7,10 +7,10 @@ import (
 )
 
 type AnalysisRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAnalysisRepository(db *sql.DB) *AnalysisRepository {
+func NewAnalysisRepository(db DBTX) *AnalysisRepository {
 	return &AnalysisRepository{db: db}
 }
 
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+const configIgnorePatterns = "ignore_patterns"
+
+type ConfigRepository struct {
+	db DBTX
+}
+
+func NewConfigRepository(db DBTX) *ConfigRepository {
+	return &ConfigRepository{db: db}
+}
+
+func (r *ConfigRepository) Get(key string) (string, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM config WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+func (r *ConfigRepository) Set(key, value string) error {
+	query := `
+		INSERT INTO config (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = ?
+	`
+	_, err := r.db.Exec(query, key, value, value)
+	return err
+}
+
+// GetIgnorePatterns returns the global ignore patterns applied to every
+// project walk.
+func (r *ConfigRepository) GetIgnorePatterns() ([]string, error) {
+	value, err := r.Get(configIgnorePatterns)
+	if err != nil || value == "" {
+		return nil, err
+	}
+
+	var patterns []string
+	if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+		return nil, fmt.Errorf("invalid %s config value: %w", configIgnorePatterns, err)
+	}
+	return patterns, nil
+}
+
+func (r *ConfigRepository) SetIgnorePatterns(patterns []string) error {
+	if patterns == nil {
+		patterns = []string{}
+	}
+	data, err := json.Marshal(patterns)
+	if err != nil {
+		return err
+	}
+	return r.Set(configIgnorePatterns, string(data))
+}
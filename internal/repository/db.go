@@ -0,0 +1,11 @@
+package repository
+
+import "database/sql"
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so every repository can be
+// used inside a transaction.
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type LanguageStatRepository struct {
+	db DBTX
+}
+
+func NewLanguageStatRepository(db DBTX) *LanguageStatRepository {
+	return &LanguageStatRepository{db: db}
+}
+
+// ReplaceForProject swaps the stored language breakdown of a project for
+// the given one.
+func (r *LanguageStatRepository) ReplaceForProject(projectID string, stats []models.LanguageStat) error {
+	if _, err := r.db.Exec(`DELETE FROM language_stats WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO language_stats (project_id, language, files, code_lines, comment_lines, blank_lines)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, s := range stats {
+		_, err := r.db.Exec(query,
+			projectID,
+			s.Language,
+			s.Files,
+			s.CodeLines,
+			s.CommentLines,
+			s.BlankLines,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByProject returns the language breakdown of a project, the language
+// with the most code lines first.
+func (r *LanguageStatRepository) GetByProject(projectID string) ([]models.LanguageStat, error) {
+	query := `
+		SELECT project_id, language, files, code_lines, comment_lines, blank_lines
+		FROM language_stats WHERE project_id = ?
+		ORDER BY code_lines DESC, language
+	`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []models.LanguageStat
+	for rows.Next() {
+		var s models.LanguageStat
+		if err := rows.Scan(&s.ProjectID, &s.Language, &s.Files, &s.CodeLines, &s.CommentLines, &s.BlankLines); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestLanguageStatRepository_ReplaceForProject(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewLanguageStatRepository(db)
+	first := []models.LanguageStat{
+		{Language: "Go", Files: 3, CodeLines: 120, CommentLines: 10, BlankLines: 20},
+		{Language: "Shell", Files: 1, CodeLines: 5},
+	}
+	if err := repo.ReplaceForProject("p1", first); err != nil {
+		t.Fatalf("ReplaceForProject() failed: %v", err)
+	}
+	if err := repo.ReplaceForProject("p2", first[:1]); err != nil {
+		t.Fatalf("ReplaceForProject() failed: %v", err)
+	}
+
+	second := []models.LanguageStat{
+		{Language: "Markdown", Files: 1, CodeLines: 40},
+		{Language: "Go", Files: 4, CodeLines: 200, CommentLines: 15, BlankLines: 30},
+	}
+	if err := repo.ReplaceForProject("p1", second); err != nil {
+		t.Fatalf("ReplaceForProject() failed: %v", err)
+	}
+
+	stats, err := repo.GetByProject("p1")
+	if err != nil {
+		t.Fatalf("GetByProject() failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("GetByProject() returned %d languages, want 2: %+v", len(stats), stats)
+	}
+	want := models.LanguageStat{ProjectID: "p1", Language: "Go", Files: 4, CodeLines: 200, CommentLines: 15, BlankLines: 30}
+	if stats[0] != want {
+		t.Errorf("stats[0] = %+v, want %+v", stats[0], want)
+	}
+	if stats[1].Language != "Markdown" {
+		t.Errorf("stats[1] = %+v, want Markdown second", stats[1])
+	}
+
+	other, err := repo.GetByProject("p2")
+	if err != nil || len(other) != 1 {
+		t.Errorf("GetByProject(p2) = %+v, %v; want its own breakdown untouched", other, err)
+	}
+}
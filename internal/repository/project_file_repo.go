@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type ProjectFileRepository struct {
+	db DBTX
+}
+
+func NewProjectFileRepository(db DBTX) *ProjectFileRepository {
+	return &ProjectFileRepository{db: db}
+}
+
+// ReplaceForProject swaps the stored file fingerprints of a project for the
+// given set.
+func (r *ProjectFileRepository) ReplaceForProject(projectID string, files []models.ProjectFile) error {
+	if _, err := r.db.Exec(`DELETE FROM project_files WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO project_files (project_id, file_path, file_type, size_bytes, last_modified, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, f := range files {
+		_, err := r.db.Exec(query,
+			projectID,
+			f.FilePath,
+			f.FileType,
+			f.SizeBytes,
+			f.LastModified.Unix(),
+			f.ContentHash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ProjectFileRepository) GetByProject(projectID string) ([]models.ProjectFile, error) {
+	query := `
+		SELECT id, project_id, file_path, file_type, size_bytes, last_modified, content_hash
+		FROM project_files WHERE project_id = ?
+		ORDER BY file_path
+	`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []models.ProjectFile
+	for rows.Next() {
+		f, err := scanProjectFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+// GetAllByPath returns the stored file fingerprints of every project, keyed
+// by project path.
+func (r *ProjectFileRepository) GetAllByPath() (map[string][]models.ProjectFile, error) {
+	query := `
+		SELECT p.path, f.id, f.project_id, f.file_path, f.file_type, f.size_bytes, f.last_modified, f.content_hash
+		FROM project_files f
+		JOIN projects p ON p.id = f.project_id
+		ORDER BY p.path, f.file_path
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]models.ProjectFile)
+	for rows.Next() {
+		var path string
+		var f models.ProjectFile
+		var size sql.NullInt64
+		var hash sql.NullString
+		var modified int64
+
+		err := rows.Scan(&path, &f.ID, &f.ProjectID, &f.FilePath, &f.FileType, &size, &modified, &hash)
+		if err != nil {
+			return nil, err
+		}
+		f.SizeBytes = size.Int64
+		f.ContentHash = hash.String
+		f.LastModified = time.Unix(modified, 0)
+
+		result[path] = append(result[path], f)
+	}
+
+	return result, rows.Err()
+}
+
+func scanProjectFile(rows *sql.Rows) (models.ProjectFile, error) {
+	var f models.ProjectFile
+	var size sql.NullInt64
+	var hash sql.NullString
+	var modified int64
+
+	err := rows.Scan(&f.ID, &f.ProjectID, &f.FilePath, &f.FileType, &size, &modified, &hash)
+	if err != nil {
+		return f, err
+	}
+	f.SizeBytes = size.Int64
+	f.ContentHash = hash.String
+	f.LastModified = time.Unix(modified, 0)
+
+	return f, nil
+}
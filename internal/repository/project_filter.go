@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/filter"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// timeColumns are the projects columns of time filter fields.
+var timeColumns = map[string]string{
+	"updated": "updated_at",
+	"created": "created_at",
+	"scanned": "last_scanned_at",
+}
+
+// hasConditions are the conditions of has: filters.
+var hasConditions = map[string]string{
+	"git":         "is_git_repo = 1",
+	"remote":      "COALESCE(git_remote, '') != ''",
+	"description": "COALESCE(description, '') != ''",
+	"notes":       "COALESCE(notes, '') != ''",
+	"readme":      "EXISTS (SELECT 1 FROM project_readmes WHERE project_id = projects.id)",
+	"todos":       "EXISTS (SELECT 1 FROM todos WHERE project_id = projects.id AND completed = 0 AND source = 'code')",
+	"tasks":       "EXISTS (SELECT 1 FROM todos WHERE project_id = projects.id AND completed = 0 AND source = 'manual')",
+	"analysis":    "EXISTS (SELECT 1 FROM ai_analyses WHERE project_id = projects.id)",
+	"parent":      "parent_id IS NOT NULL",
+	"missing":     "missing_since IS NOT NULL",
+}
+
+// filterSQL compiles a filter to a WHERE clause over projects, without the
+// WHERE keyword, and its arguments. It returns "" for an empty filter.
+func filterSQL(f *filter.Filter) (string, []interface{}, error) {
+	if f.Empty() {
+		return "", nil, nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	for _, term := range f.Terms {
+		var matches []string
+		for i, value := range term.Values {
+			condition, termArgs, err := termSQL(term, i, value)
+			if err != nil {
+				return "", nil, err
+			}
+			matches = append(matches, condition)
+			args = append(args, termArgs...)
+		}
+
+		condition := strings.Join(matches, " OR ")
+		if term.Negate {
+			// A NULL column neither matches nor fails to match; treat it
+			// as not matching, so that it is kept by the negation.
+			condition = "NOT COALESCE((" + condition + "), 0)"
+		} else {
+			condition = "(" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+func termSQL(term filter.Term, i int, value string) (string, []interface{}, error) {
+	op := string(term.Op)
+	switch term.Field {
+	case filter.Text:
+		pattern := "%" + escapeLike(value) + "%"
+		return `(name LIKE ? ESCAPE '\' OR COALESCE(description, '') LIKE ? ESCAPE '\' OR path LIKE ? ESCAPE '\')`,
+			[]interface{}{pattern, pattern, pattern}, nil
+	case "status":
+		return "status = ?", []interface{}{value}, nil
+	case "tech":
+		return "EXISTS (SELECT 1 FROM technologies WHERE project_id = projects.id AND transitive = 0 AND name = ? COLLATE NOCASE)",
+			[]interface{}{value}, nil
+	case "name", "path":
+		return term.Field + ` LIKE ? ESCAPE '\'`, []interface{}{"%" + escapeLike(value) + "%"}, nil
+	case "remote":
+		// scp-like remotes (git@host:owner/repo) are matched as host/owner/repo.
+		pattern := "%" + escapeLike(strings.ReplaceAll(value, ":", "/")) + "%"
+		return `REPLACE(COALESCE(git_remote, ''), ':', '/') LIKE ? ESCAPE '\'`, []interface{}{pattern}, nil
+	case "branch":
+		return "COALESCE(git_branch, '') = ?", []interface{}{value}, nil
+	case "progress":
+		if term.Op == filter.OpMatch {
+			op = "="
+		}
+		return "progress " + op + " ?", []interface{}{term.Numbers[i]}, nil
+	case "updated", "created", "scanned":
+		if term.Op == filter.OpMatch {
+			op = ">="
+		}
+		return timeColumns[term.Field] + " " + op + " ?", []interface{}{term.Times[i].Unix()}, nil
+	case "has":
+		return hasConditions[value], nil, nil
+	}
+	return "", nil, fmt.Errorf("unsupported filter field: %s", term.Field)
+}
+
+// Find returns the projects matching a filter, the most recently updated
+// first. A limit of 0 or less returns them all.
+func (r *ProjectRepository) Find(f *filter.Filter, limit, offset int) ([]models.Project, error) {
+	page, err := r.Page(ProjectQuery{Filter: f, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	return page.Projects, nil
+}
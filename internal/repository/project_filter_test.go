@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/filter"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestProjectRepository_Find(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+	now := time.Now()
+	projects := []*models.Project{
+		{ID: "api", Name: "acme-api", Path: "/src/acme-api", Status: models.StatusActive, Progress: 30,
+			IsGitRepo: true, GitRemote: "git@github.com:acme/api.git", GitBranch: "main",
+			CreatedAt: now.Add(-90 * 24 * time.Hour), UpdatedAt: now.Add(-time.Hour)},
+		{ID: "web", Name: "acme-web", Path: "/src/acme-web", Description: "Storefront", Status: models.StatusActive, Progress: 80,
+			IsGitRepo: true, GitRemote: "https://gitlab.com/acme/web", GitBranch: "develop",
+			CreatedAt: now.Add(-10 * 24 * time.Hour), UpdatedAt: now.Add(-40 * 24 * time.Hour)},
+		{ID: "notes", Name: "notes", Path: "/src/notes", Status: models.StatusPaused, Progress: 0, Notes: "Reorganize",
+			CreatedAt: now.Add(-200 * 24 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour)},
+	}
+	for _, p := range projects {
+		if err := repo.Create(p); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+	techRepo := NewTechnologyRepository(db)
+	for _, tech := range []models.Technology{
+		{ProjectID: "api", Type: "runtime", Name: "Go", DetectedFrom: "go.mod"},
+		{ProjectID: "web", Type: "framework", Name: "React", DetectedFrom: "package.json"},
+		{ProjectID: "notes", Type: "library", Name: "go", Transitive: true, DetectedFrom: "go.sum"},
+	} {
+		tech := tech
+		if err := techRepo.Create(&tech); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+	if err := NewTodoRepository(db).Create(&models.Todo{ProjectID: "notes", Content: "sort them", Priority: "low"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "api notes web"},
+		{"status:active", "api web"},
+		{"status:active,paused -status:active", "notes"},
+		{"tech:GO", "api"},
+		{"-tech:go", "notes web"},
+		{"progress<50", "api notes"},
+		{"progress>=30 progress<=80 progress:80", "web"},
+		{"updated>30d", "api notes"},
+		{"updated<30d", "web"},
+		{"created<60d", "api notes"},
+		{"has:git", "api web"},
+		{"-has:remote", "notes"},
+		{"has:notes,description", "notes web"},
+		{"has:tasks", "notes"},
+		{"has:todos", ""},
+		{"remote:github.com/acme", "api"},
+		{"branch:develop", "web"},
+		{"acme", "api web"},
+		{"storefront", "web"},
+		{"name:acme -name:web", "api"},
+		{"scanned>1d", ""},
+		{"-scanned>1d", "api notes web"},
+		{"remote:%", ""},
+	}
+	for _, tt := range tests {
+		f, err := filter.Parse(tt.expr, now)
+		if err != nil {
+			t.Fatalf("filter.Parse(%q) failed: %v", tt.expr, err)
+		}
+		found, err := repo.Find(f, 0, 0)
+		if err != nil {
+			t.Fatalf("Find(%q) failed: %v", tt.expr, err)
+		}
+		var ids []string
+		for _, p := range found {
+			ids = append(ids, p.ID)
+		}
+		sort.Strings(ids)
+		if got := strings.Join(ids, " "); got != tt.want {
+			t.Errorf("Find(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+
+	found, err := repo.Find(nil, 1, 1)
+	if err != nil || len(found) != 1 || found[0].ID != "notes" {
+		t.Errorf("Find(nil, 1, 1) = %+v, %v; want the second most recently updated project", found, err)
+	}
+}
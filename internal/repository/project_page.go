@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/filter"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// ErrInvalidSort is returned for an unknown sort key.
+var ErrInvalidSort = errors.New("invalid sort")
+
+// ErrInvalidCursor is returned for a cursor that was not returned by Page
+// with the same sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DefaultSort lists the most recently updated projects first.
+const DefaultSort = "-updated"
+
+// SortKeys are the keys projects can be sorted by, in ascending order or,
+// prefixed with "-", in descending order.
+var SortKeys = []string{"name", "progress", "updated", "created", "last_scanned", "last_commit"}
+
+// sortKey is how projects are sorted by a key: expr is the ORDER BY
+// expression and value returns it for a project, to resume after it.
+type sortKey struct {
+	expr  string
+	value func(p *models.Project) interface{}
+}
+
+// Projects never scanned or without commits sort as the oldest.
+var sortKeys = map[string]sortKey{
+	"name":         {"name COLLATE NOCASE", func(p *models.Project) interface{} { return p.Name }},
+	"progress":     {"progress", func(p *models.Project) interface{} { return int64(p.Progress) }},
+	"updated":      {"updated_at", func(p *models.Project) interface{} { return p.UpdatedAt.Unix() }},
+	"created":      {"created_at", func(p *models.Project) interface{} { return p.CreatedAt.Unix() }},
+	"last_scanned": {"COALESCE(last_scanned_at, 0)", func(p *models.Project) interface{} { return unixOrZero(p.LastScannedAt) }},
+	"last_commit":  {"COALESCE(last_commit_at, 0)", func(p *models.Project) interface{} { return unixOrZero(p.LastCommitAt) }},
+}
+
+// ProjectQuery selects a page of projects.
+type ProjectQuery struct {
+	Filter *filter.Filter
+	// Sort is a key of SortKeys, prefixed with "-" for descending order;
+	// empty means DefaultSort. Projects with the same key are ordered by ID.
+	Sort string
+	// Limit is the size of the page; 0 or less returns every project.
+	Limit int
+	// Cursor resumes after the last project of a previous page, as given
+	// by its NextCursor. Offset is only used without a cursor.
+	Cursor string
+	Offset int
+}
+
+// ProjectPage is a page of projects. NextCursor is empty on the last page.
+type ProjectPage struct {
+	Projects   []models.Project
+	NextCursor string
+}
+
+// cursor is the position after a project in a sort, encoded as base64 JSON
+// so it can be passed around as an opaque token.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Page returns the projects matching q.Filter in the order of q.Sort,
+// starting after q.Cursor. Unlike offsets, cursors neither skip nor repeat
+// projects when others are added or removed between pages.
+func (r *ProjectRepository) Page(q ProjectQuery) (*ProjectPage, error) {
+	sort, key, desc, err := parseSort(q.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args, err := filterSQL(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var conditions []string
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+	offset := q.Offset
+	if q.Cursor != "" {
+		value, id, err := decodeCursor(q.Cursor, sort, key)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", key.expr, cmp))
+		args = append(args, value, value, id)
+		offset = 0
+	}
+
+	query := `SELECT ` + projectColumns + ` FROM projects`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT ? OFFSET ?", key.expr, dir, dir)
+
+	// One project more than asked tells whether there is a next page.
+	limit := -1
+	if q.Limit > 0 {
+		limit = q.Limit + 1
+	}
+	args = append(args, limit, offset)
+
+	projects, err := r.queryProjects(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ProjectPage{Projects: projects}
+	if q.Limit > 0 && len(projects) > q.Limit {
+		page.Projects = projects[:q.Limit]
+		page.NextCursor = encodeCursor(sort, key, &page.Projects[q.Limit-1])
+	}
+	return page, nil
+}
+
+// Each calls fn for every project matching q, page by page, so that all of
+// them are never held at once. q.Limit is the size of the pages. fn is
+// called while no query is open, so it may use the database.
+func (r *ProjectRepository) Each(q ProjectQuery, fn func(p *models.Project) error) error {
+	if q.Limit <= 0 {
+		q.Limit = 200
+	}
+	for {
+		page, err := r.Page(q)
+		if err != nil {
+			return err
+		}
+		for i := range page.Projects {
+			if err := fn(&page.Projects[i]); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// parseSort returns the canonical form of a sort, its key and whether it
+// is descending.
+func parseSort(sort string) (string, sortKey, bool, error) {
+	if sort == "" {
+		sort = DefaultSort
+	}
+	sort = strings.ToLower(sort)
+	name := strings.TrimPrefix(sort, "-")
+	key, ok := sortKeys[name]
+	if !ok {
+		return "", key, false, fmt.Errorf("%w %q (use %s, or -key for descending order)", ErrInvalidSort, sort, strings.Join(SortKeys, ", "))
+	}
+	return sort, key, name != sort, nil
+}
+
+func encodeCursor(sort string, key sortKey, p *models.Project) string {
+	c := cursor{Sort: sort, ID: p.ID}
+	switch v := key.value(p).(type) {
+	case string:
+		c.Value = v
+	case int64:
+		c.Value = strconv.FormatInt(v, 10)
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the sort value and ID a cursor resumes after.
+func decodeCursor(token, sort string, key sortKey) (interface{}, string, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.ID == "" {
+		return nil, "", fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	if c.Sort != sort {
+		return nil, "", fmt.Errorf("%w: it was made for sort %s, not %s", ErrInvalidCursor, c.Sort, sort)
+	}
+
+	if _, text := key.value(&models.Project{}).(string); text {
+		return c.Value, c.ID, nil
+	}
+	n, err := strconv.ParseInt(c.Value, 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	return n, c.ID, nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/filter"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestProjectRepository_Page(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+	now := time.Unix(1700000000, 0)
+	commit := now.Add(-time.Hour)
+	projects := []*models.Project{
+		{ID: "p1", Name: "Beta", Path: "/src/beta", Status: models.StatusActive, Progress: 50,
+			CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now, LastCommitAt: &commit},
+		{ID: "p2", Name: "alpha", Path: "/src/alpha", Status: models.StatusActive, Progress: 50,
+			CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now, LastScannedAt: &now},
+		{ID: "p3", Name: "gamma", Path: "/src/gamma", Status: models.StatusPaused, Progress: 10,
+			CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Minute)},
+		{ID: "p4", Name: "delta", Path: "/src/delta", Status: models.StatusActive, Progress: 90,
+			CreatedAt: now.Add(-4 * time.Hour), UpdatedAt: now.Add(-time.Hour)},
+	}
+	for _, p := range projects {
+		if err := repo.Create(p); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sort   string
+		filter string
+		want   string
+	}{
+		{"", "", "p2 p1 p3 p4"},
+		{"name", "", "p2 p1 p4 p3"},
+		{"-name", "", "p3 p4 p1 p2"},
+		{"progress", "", "p3 p1 p2 p4"},
+		{"-progress", "", "p4 p2 p1 p3"},
+		{"created", "", "p4 p1 p2 p3"},
+		{"-last_scanned", "", "p2 p4 p3 p1"},
+		{"-last_commit", "", "p1 p4 p3 p2"},
+		{"name", "status:active", "p2 p1 p4"},
+	}
+	for _, tt := range tests {
+		f, err := filter.Parse(tt.filter, now)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.filter, err)
+		}
+
+		// Pages of every size list the same projects, once each.
+		for size := 1; size <= len(projects); size++ {
+			var ids []string
+			q := ProjectQuery{Filter: f, Sort: tt.sort, Limit: size}
+			for pages := 0; ; pages++ {
+				if pages > len(projects) {
+					t.Fatalf("Page(%q) does not end", tt.sort)
+				}
+				page, err := repo.Page(q)
+				if err != nil {
+					t.Fatalf("Page(%q) failed: %v", tt.sort, err)
+				}
+				if len(page.Projects) > size {
+					t.Errorf("Page(%q) returned %d projects, want at most %d", tt.sort, len(page.Projects), size)
+				}
+				for _, p := range page.Projects {
+					ids = append(ids, p.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				q.Cursor = page.NextCursor
+			}
+			if got := strings.Join(ids, " "); got != tt.want {
+				t.Errorf("Page(%q, %q) in pages of %d = %s, want %s", tt.sort, tt.filter, size, got, tt.want)
+			}
+		}
+	}
+
+	// A cursor resumes after its project even once projects before it are
+	// gone, where an offset would skip one.
+	page, err := repo.Page(ProjectQuery{Sort: "name", Limit: 2})
+	if err != nil {
+		t.Fatalf("Page() failed: %v", err)
+	}
+	if err := repo.Delete("p2"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	next, err := repo.Page(ProjectQuery{Sort: "name", Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("Page() failed: %v", err)
+	}
+	if got := fmt.Sprint(projectIDs(next.Projects)); got != "[p4 p3]" || next.NextCursor != "" {
+		t.Errorf("Page() after a deletion = %s, %q; want [p4 p3] and no cursor", got, next.NextCursor)
+	}
+
+	if _, err := repo.Page(ProjectQuery{Sort: "size"}); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("Page() with an unknown sort = %v, want ErrInvalidSort", err)
+	}
+	if _, err := repo.Page(ProjectQuery{Sort: "-name", Cursor: page.NextCursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Page() with a cursor of another sort = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := repo.Page(ProjectQuery{Cursor: "not a cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Page() with a malformed cursor = %v, want ErrInvalidCursor", err)
+	}
+
+	var each []string
+	err = repo.Each(ProjectQuery{Sort: "name", Limit: 1}, func(p *models.Project) error {
+		// The database is free while fn runs.
+		if _, err := repo.GetByID(p.ID); err != nil {
+			return err
+		}
+		each = append(each, p.ID)
+		return nil
+	})
+	if err != nil || strings.Join(each, " ") != "p1 p4 p3" {
+		t.Errorf("Each() = %v, %v; want p1 p4 p3", each, err)
+	}
+}
+
+func projectIDs(projects []models.Project) []string {
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
@@ -8,25 +8,23 @@ import (
 	"github.com/snowarch/project-memory/internal/models"
 )
 
+// projectColumns is the column list every project query selects, in the
+// order expected by scanProject.
+const projectColumns = `id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes, root_commit, missing_since, parent_id, last_commit_at`
+
 type ProjectRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewProjectRepository(db *sql.DB) *ProjectRepository {
+func NewProjectRepository(db DBTX) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
 func (r *ProjectRepository) Create(project *models.Project) error {
 	query := `
-		INSERT INTO projects (id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO projects (id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes, root_commit, missing_since, parent_id, last_commit_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	
-	var lastScanned *int64
-	if project.LastScannedAt != nil {
-		ts := project.LastScannedAt.Unix()
-		lastScanned = &ts
-	}
 
 	_, err := r.db.Exec(query,
 		project.ID,
@@ -37,11 +35,15 @@ func (r *ProjectRepository) Create(project *models.Project) error {
 		project.Progress,
 		project.CreatedAt.Unix(),
 		project.UpdatedAt.Unix(),
-		lastScanned,
+		unixOrNil(project.LastScannedAt),
 		project.IsGitRepo,
 		project.GitRemote,
 		project.GitBranch,
 		project.Notes,
+		project.RootCommit,
+		unixOrNil(project.MissingSince),
+		nullIfEmpty(project.ParentID),
+		unixOrNil(project.LastCommitAt),
 	)
 
 	return err
@@ -50,15 +52,9 @@ func (r *ProjectRepository) Create(project *models.Project) error {
 func (r *ProjectRepository) Update(project *models.Project) error {
 	query := `
 		UPDATE projects 
-		SET name = ?, description = ?, status = ?, progress = ?, updated_at = ?, last_scanned_at = ?, git_remote = ?, git_branch = ?, notes = ?
+		SET name = ?, description = ?, status = ?, progress = ?, updated_at = ?, last_scanned_at = ?, git_remote = ?, git_branch = ?, notes = ?, root_commit = ?, missing_since = ?, parent_id = ?, last_commit_at = ?
 		WHERE id = ?
 	`
-	
-	var lastScanned *int64
-	if project.LastScannedAt != nil {
-		ts := project.LastScannedAt.Unix()
-		lastScanned = &ts
-	}
 
 	_, err := r.db.Exec(query,
 		project.Name,
@@ -66,42 +62,65 @@ func (r *ProjectRepository) Update(project *models.Project) error {
 		project.Status,
 		project.Progress,
 		project.UpdatedAt.Unix(),
-		lastScanned,
+		unixOrNil(project.LastScannedAt),
 		project.GitRemote,
 		project.GitBranch,
 		project.Notes,
+		project.RootCommit,
+		unixOrNil(project.MissingSince),
+		nullIfEmpty(project.ParentID),
+		unixOrNil(project.LastCommitAt),
 		project.ID,
 	)
 
 	return err
 }
 
-func (r *ProjectRepository) GetByID(id string) (*models.Project, error) {
+// MarkScannedByPath records a scan of a project that did not need to be
+// analyzed again.
+func (r *ProjectRepository) MarkScannedByPath(path string, scannedAt time.Time) error {
+	query := `UPDATE projects SET last_scanned_at = ?, missing_since = NULL WHERE path = ?`
+	_, err := r.db.Exec(query, scannedAt.Unix(), path)
+	return err
+}
+
+// MarkMissing flags a project whose directory no longer exists. Projects
+// already flagged keep their original timestamp.
+func (r *ProjectRepository) MarkMissing(id string, since time.Time) error {
+	query := `UPDATE projects SET missing_since = COALESCE(missing_since, ?) WHERE id = ?`
+	_, err := r.db.Exec(query, since.Unix(), id)
+	return err
+}
+
+// Relocate moves a project to a new path, keeping its identity and history,
+// and clears its missing flag.
+func (r *ProjectRepository) Relocate(id, newPath, name string) error {
+	query := `UPDATE projects SET path = ?, name = ?, missing_since = NULL, updated_at = ? WHERE id = ?`
+	_, err := r.db.Exec(query, newPath, name, time.Now().Unix(), id)
+	return err
+}
+
+// SaveReadme stores the README contents of a project for search, or
+// removes them when content is empty.
+func (r *ProjectRepository) SaveReadme(id, content string, now time.Time) error {
+	if content == "" {
+		_, err := r.db.Exec(`DELETE FROM project_readmes WHERE project_id = ?`, id)
+		return err
+	}
+
 	query := `
-		SELECT id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes
-		FROM projects WHERE id = ?
+		INSERT INTO project_readmes (project_id, content, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(project_id) DO UPDATE SET content = excluded.content, updated_at = excluded.updated_at
+		WHERE content != excluded.content
 	`
+	_, err := r.db.Exec(query, id, content, now.Unix())
+	return err
+}
 
-	var project models.Project
-	var lastScanned sql.NullInt64
-	var createdAt, updatedAt int64
-
-	err := r.db.QueryRow(query, id).Scan(
-		&project.ID,
-		&project.Name,
-		&project.Path,
-		&project.Description,
-		&project.Status,
-		&project.Progress,
-		&createdAt,
-		&updatedAt,
-		&lastScanned,
-		&project.IsGitRepo,
-		&project.GitRemote,
-		&project.GitBranch,
-		&project.Notes,
-	)
+func (r *ProjectRepository) GetByID(id string) (*models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = ?`
 
+	project, err := scanProject(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("project not found")
@@ -109,43 +128,13 @@ func (r *ProjectRepository) GetByID(id string) (*models.Project, error) {
 		return nil, err
 	}
 
-	project.CreatedAt = time.Unix(createdAt, 0)
-	project.UpdatedAt = time.Unix(updatedAt, 0)
-
-	if lastScanned.Valid {
-		ts := time.Unix(lastScanned.Int64, 0)
-		project.LastScannedAt = &ts
-	}
-
-	return &project, nil
+	return project, nil
 }
 
 func (r *ProjectRepository) GetByPath(path string) (*models.Project, error) {
-	query := `
-		SELECT id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes
-		FROM projects WHERE path = ?
-	`
-
-	var project models.Project
-	var lastScanned sql.NullInt64
-	var createdAt, updatedAt int64
-
-	err := r.db.QueryRow(query, path).Scan(
-		&project.ID,
-		&project.Name,
-		&project.Path,
-		&project.Description,
-		&project.Status,
-		&project.Progress,
-		&createdAt,
-		&updatedAt,
-		&lastScanned,
-		&project.IsGitRepo,
-		&project.GitRemote,
-		&project.GitBranch,
-		&project.Notes,
-	)
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE path = ?`
 
+	project, err := scanProject(r.db.QueryRow(query, path))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -153,15 +142,7 @@ func (r *ProjectRepository) GetByPath(path string) (*models.Project, error) {
 		return nil, err
 	}
 
-	project.CreatedAt = time.Unix(createdAt, 0)
-	project.UpdatedAt = time.Unix(updatedAt, 0)
-
-	if lastScanned.Valid {
-		ts := time.Unix(lastScanned.Int64, 0)
-		project.LastScannedAt = &ts
-	}
-
-	return &project, nil
+	return project, nil
 }
 
 func (r *ProjectRepository) List(status string, limit, offset int) ([]models.Project, error) {
@@ -170,61 +151,53 @@ func (r *ProjectRepository) List(status string, limit, offset int) ([]models.Pro
 
 	if status != "" {
 		query = `
-			SELECT id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes
+			SELECT ` + projectColumns + `
 			FROM projects WHERE status = ? ORDER BY updated_at DESC LIMIT ? OFFSET ?
 		`
 		args = []interface{}{status, limit, offset}
 	} else {
 		query = `
-			SELECT id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes
+			SELECT ` + projectColumns + `
 			FROM projects ORDER BY updated_at DESC LIMIT ? OFFSET ?
 		`
 		args = []interface{}{limit, offset}
 	}
 
-	rows, err := r.db.Query(query, args...)
+	return r.queryProjects(query, args...)
+}
+
+// ListByName returns the projects with exactly the given name, ordered by
+// path.
+func (r *ProjectRepository) ListByName(name string) ([]models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE name = ? ORDER BY path`
+	return r.queryProjects(query, name)
+}
+
+// ListChildren returns the member projects of a workspace project, ordered
+// by name.
+func (r *ProjectRepository) ListChildren(parentID string) ([]models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE parent_id = ? ORDER BY name`
+	return r.queryProjects(query, parentID)
+}
+
+// RootCommitsByPath returns the root commit recorded for every project
+// that has one, keyed by project path.
+func (r *ProjectRepository) RootCommitsByPath() (map[string]string, error) {
+	rows, err := r.db.Query(`SELECT path, root_commit FROM projects WHERE root_commit IS NOT NULL AND root_commit != ''`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var projects []models.Project
+	roots := make(map[string]string)
 	for rows.Next() {
-		var project models.Project
-		var lastScanned sql.NullInt64
-		var createdAt, updatedAt int64
-
-		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Path,
-			&project.Description,
-			&project.Status,
-			&project.Progress,
-			&createdAt,
-			&updatedAt,
-			&lastScanned,
-			&project.IsGitRepo,
-			&project.GitRemote,
-			&project.GitBranch,
-			&project.Notes,
-		)
-		if err != nil {
+		var path, root string
+		if err := rows.Scan(&path, &root); err != nil {
 			return nil, err
 		}
-
-		project.CreatedAt = time.Unix(createdAt, 0)
-		project.UpdatedAt = time.Unix(updatedAt, 0)
-
-		if lastScanned.Valid {
-			ts := time.Unix(lastScanned.Int64, 0)
-			project.LastScannedAt = &ts
-		}
-
-		projects = append(projects, project)
+		roots[path] = root
 	}
-
-	return projects, nil
+	return roots, rows.Err()
 }
 
 func (r *ProjectRepository) Delete(id string) error {
@@ -251,14 +224,18 @@ func (r *ProjectRepository) Count(status string) (int, error) {
 
 func (r *ProjectRepository) Search(query string) ([]models.Project, error) {
 	sqlQuery := `
-		SELECT id, name, path, description, status, progress, created_at, updated_at, last_scanned_at, is_git_repo, git_remote, git_branch, notes
+		SELECT ` + projectColumns + `
 		FROM projects 
 		WHERE name LIKE ? OR description LIKE ? OR path LIKE ?
 		ORDER BY updated_at DESC
 	`
 
 	searchTerm := "%" + query + "%"
-	rows, err := r.db.Query(sqlQuery, searchTerm, searchTerm, searchTerm)
+	return r.queryProjects(sqlQuery, searchTerm, searchTerm, searchTerm)
+}
+
+func (r *ProjectRepository) queryProjects(query string, args ...interface{}) ([]models.Project, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -266,39 +243,92 @@ func (r *ProjectRepository) Search(query string) ([]models.Project, error) {
 
 	var projects []models.Project
 	for rows.Next() {
-		var project models.Project
-		var lastScanned sql.NullInt64
-		var createdAt, updatedAt int64
-
-		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Path,
-			&project.Description,
-			&project.Status,
-			&project.Progress,
-			&createdAt,
-			&updatedAt,
-			&lastScanned,
-			&project.IsGitRepo,
-			&project.GitRemote,
-			&project.GitBranch,
-			&project.Notes,
-		)
+		project, err := scanProject(rows)
 		if err != nil {
 			return nil, err
 		}
+		projects = append(projects, *project)
+	}
 
-		project.CreatedAt = time.Unix(createdAt, 0)
-		project.UpdatedAt = time.Unix(updatedAt, 0)
+	return projects, rows.Err()
+}
 
-		if lastScanned.Valid {
-			ts := time.Unix(lastScanned.Int64, 0)
-			project.LastScannedAt = &ts
-		}
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-		projects = append(projects, project)
+func scanProject(row rowScanner) (*models.Project, error) {
+	var project models.Project
+	var description, gitRemote, gitBranch, notes, rootCommit, parentID sql.NullString
+	var lastScanned, missingSince, lastCommit sql.NullInt64
+	var createdAt, updatedAt int64
+
+	err := row.Scan(
+		&project.ID,
+		&project.Name,
+		&project.Path,
+		&description,
+		&project.Status,
+		&project.Progress,
+		&createdAt,
+		&updatedAt,
+		&lastScanned,
+		&project.IsGitRepo,
+		&gitRemote,
+		&gitBranch,
+		&notes,
+		&rootCommit,
+		&missingSince,
+		&parentID,
+		&lastCommit,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return projects, nil
+	project.Description = description.String
+	project.GitRemote = gitRemote.String
+	project.GitBranch = gitBranch.String
+	project.Notes = notes.String
+	project.RootCommit = rootCommit.String
+	project.ParentID = parentID.String
+	project.CreatedAt = time.Unix(createdAt, 0)
+	project.UpdatedAt = time.Unix(updatedAt, 0)
+	project.LastScannedAt = timeOrNil(lastScanned)
+	project.MissingSince = timeOrNil(missingSince)
+	project.LastCommitAt = timeOrNil(lastCommit)
+
+	return &project, nil
+}
+
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	ts := t.Unix()
+	return &ts
+}
+
+// unixOrZero returns t as a Unix time, or 0 for nil.
+func unixOrZero(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func timeOrNil(ts sql.NullInt64) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := time.Unix(ts.Int64, 0)
+	return &t
 }
@@ -34,7 +34,75 @@ func setupTestDB(t *testing.T) *sql.DB {
 		is_git_repo BOOLEAN DEFAULT 0,
 		git_remote TEXT,
 		git_branch TEXT,
-		notes TEXT
+		notes TEXT,
+		root_commit TEXT,
+		missing_since INTEGER,
+		parent_id TEXT,
+		last_commit_at INTEGER
+	);
+
+	CREATE TABLE IF NOT EXISTS technologies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		version TEXT,
+		declared_version TEXT,
+		scope TEXT,
+		transitive INTEGER NOT NULL DEFAULT 0,
+		source TEXT,
+		features TEXT,
+		detected_from TEXT,
+		UNIQUE(project_id, type, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS language_stats (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id TEXT NOT NULL,
+		language TEXT NOT NULL,
+		files INTEGER NOT NULL DEFAULT 0,
+		code_lines INTEGER NOT NULL DEFAULT 0,
+		comment_lines INTEGER NOT NULL DEFAULT 0,
+		blank_lines INTEGER NOT NULL DEFAULT 0,
+		UNIQUE(project_id, language)
+	);
+
+	CREATE TABLE IF NOT EXISTS todos (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT 'code',
+		content TEXT NOT NULL,
+		source_file TEXT,
+		line_number INTEGER,
+		kind TEXT,
+		priority TEXT DEFAULT 'medium' CHECK(priority IN ('low', 'medium', 'high')),
+		completed BOOLEAN DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		completed_at INTEGER
+	);
+
+	CREATE TABLE IF NOT EXISTS activity_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		details TEXT,
+		timestamp INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS ai_analyses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		project_id TEXT NOT NULL,
+		analysis_type TEXT NOT NULL,
+		result TEXT NOT NULL,
+		model TEXT NOT NULL,
+		tokens_used INTEGER,
+		analyzed_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS project_readmes (
+		project_id TEXT PRIMARY KEY,
+		content TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
 	);
 	`
 	
@@ -398,3 +466,52 @@ func TestProjectRepository_Delete(t *testing.T) {
 		t.Error("GetByID() should return nil for deleted project")
 	}
 }
+
+func TestProjectRepository_MarkMissingAndRelocate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+	now := time.Now()
+
+	project := &models.Project{
+		ID:        "moved",
+		Name:      "old-name",
+		Path:      "/old/path",
+		Status:    models.StatusActive,
+		Progress:  40,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(project); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	first := now.Add(-time.Hour)
+	if err := repo.MarkMissing("moved", first); err != nil {
+		t.Fatalf("MarkMissing() failed: %v", err)
+	}
+	if err := repo.MarkMissing("moved", now); err != nil {
+		t.Fatalf("MarkMissing() failed: %v", err)
+	}
+
+	retrieved, _ := repo.GetByID("moved")
+	if retrieved.MissingSince == nil || retrieved.MissingSince.Unix() != first.Unix() {
+		t.Errorf("MissingSince = %v, want %v", retrieved.MissingSince, first)
+	}
+
+	if err := repo.Relocate("moved", "/new/path", "new-name"); err != nil {
+		t.Fatalf("Relocate() failed: %v", err)
+	}
+
+	retrieved, err := repo.GetByPath("/new/path")
+	if err != nil || retrieved == nil {
+		t.Fatalf("GetByPath() after Relocate() = %v, %v", retrieved, err)
+	}
+	if retrieved.ID != "moved" || retrieved.Name != "new-name" || retrieved.Progress != 40 {
+		t.Errorf("Relocated project = %+v, want same ID and progress with new name", retrieved)
+	}
+	if retrieved.MissingSince != nil {
+		t.Errorf("MissingSince = %v, want nil after Relocate()", retrieved.MissingSince)
+	}
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/snowarch/project-memory/internal/database"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// SearchResult is a project matching a search, with the best matching
+// part of its text. Score is higher for better matches; it is zero, and
+// Snippet empty, when the search index is not available.
+type SearchResult struct {
+	Project models.Project `json:"project"`
+	Snippet string         `json:"snippet,omitempty"`
+	Score   float64        `json:"score"`
+}
+
+// ErrEmptyQuery is returned by Search for a query without any term.
+var ErrEmptyQuery = errors.New("empty search query")
+
+// searchFields maps the field names accepted in queries to search_index
+// columns.
+var searchFields = map[string]string{
+	"name":        "name",
+	"path":        "path",
+	"description": "description",
+	"desc":        "description",
+	"notes":       "notes",
+	"note":        "notes",
+	"status":      "status",
+	"tech":        "tech",
+	"technology":  "tech",
+	"readme":      "readme",
+	"todo":        "todo",
+	"todos":       "todo",
+	"analysis":    "analysis",
+	"ai":          "analysis",
+}
+
+// likeConditions is how each search_index column is matched without the
+// index, with a LIKE pattern as the only argument.
+var likeConditions = map[string]string{
+	"name":        `name LIKE ? ESCAPE '\'`,
+	"path":        `path LIKE ? ESCAPE '\'`,
+	"description": `description LIKE ? ESCAPE '\'`,
+	"notes":       `notes LIKE ? ESCAPE '\'`,
+	"tech":        `EXISTS (SELECT 1 FROM technologies WHERE project_id = projects.id AND transitive = 0 AND name LIKE ? ESCAPE '\')`,
+	"readme":      `EXISTS (SELECT 1 FROM project_readmes WHERE project_id = projects.id AND content LIKE ? ESCAPE '\')`,
+	"todo":        `EXISTS (SELECT 1 FROM todos WHERE project_id = projects.id AND completed = 0 AND content LIKE ? ESCAPE '\')`,
+	"analysis":    `EXISTS (SELECT 1 FROM ai_analyses WHERE project_id = projects.id AND result LIKE ? ESCAPE '\')`,
+}
+
+// searchColumns are the columns a term without a field is looked up in,
+// in the order of search_index.
+var searchColumns = []string{"name", "path", "description", "notes", "tech", "readme", "todo", "analysis"}
+
+// searchTerm is a word or quoted phrase of a query, optionally restricted
+// to a column.
+type searchTerm struct {
+	column string
+	text   string
+	phrase bool
+}
+
+// parseSearchQuery splits a query such as `tech:react "state machine"` into
+// terms. Prefixes that are not known fields are kept as part of the text.
+func parseSearchQuery(query string) []searchTerm {
+	var terms []searchTerm
+	for _, word := range splitQuery(query) {
+		var term searchTerm
+		if field, text, ok := strings.Cut(word, ":"); ok {
+			if column, known := searchFields[strings.ToLower(field)]; known && text != "" {
+				term.column = column
+				word = text
+			}
+		}
+		if len(word) >= 2 && strings.HasPrefix(word, `"`) && strings.HasSuffix(word, `"`) {
+			term.phrase = true
+			word = word[1 : len(word)-1]
+		}
+		term.text = strings.TrimSpace(word)
+		if strings.IndexFunc(term.text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// splitQuery splits a query on spaces outside double quotes.
+func splitQuery(query string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			word.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// matchExpression builds the FTS5 query matching every term. Words match
+// as prefixes, quoted phrases as written.
+func matchExpression(terms []searchTerm) string {
+	var parts []string
+	for _, t := range terms {
+		expr := `"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`
+		if !t.phrase {
+			expr += "*"
+		}
+		if t.column != "" {
+			expr = t.column + " : " + expr
+		}
+		parts = append(parts, expr)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+type SearchRepository struct {
+	db DBTX
+}
+
+func NewSearchRepository(db DBTX) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Indexed reports whether searches use the full-text index. Without it,
+// terms are matched as substrings and results are not ranked.
+func (r *SearchRepository) Indexed() bool {
+	var indexed bool
+	err := r.db.QueryRow(`
+		SELECT sqlite_compileoption_used('ENABLE_FTS5')
+			AND EXISTS (SELECT 1 FROM sqlite_master WHERE name = 'search_index')
+	`).Scan(&indexed)
+	return err == nil && indexed
+}
+
+// Reindex rebuilds the search entry of a project. Changes to the project
+// itself are indexed as they are made; writers of its technologies,
+// README, TODOs or analyses call Reindex once they are done.
+func (r *SearchRepository) Reindex(projectID string) error {
+	return database.ReindexProject(r.db, projectID)
+}
+
+// Search returns the projects matching every term of query, the best
+// matches first. Terms may be restricted to a field, as in tech:react;
+// status:active only keeps the projects with that exact status. A limit
+// of 0 or less returns every match.
+func (r *SearchRepository) Search(query string, limit int) ([]SearchResult, error) {
+	var terms []searchTerm
+	var conditions []string
+	var args []interface{}
+	for _, t := range parseSearchQuery(query) {
+		if t.column == "status" {
+			conditions = append(conditions, "status = ?")
+			args = append(args, strings.ToLower(t.text))
+			continue
+		}
+		terms = append(terms, t)
+	}
+	if len(terms) == 0 && len(conditions) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	if len(terms) > 0 && r.Indexed() {
+		return r.searchIndex(terms, conditions, args, limit)
+	}
+
+	for _, t := range terms {
+		columns := searchColumns
+		if t.column != "" {
+			columns = []string{t.column}
+		}
+		var matches []string
+		for _, column := range columns {
+			matches = append(matches, likeConditions[column])
+			args = append(args, "%"+escapeLike(t.text)+"%")
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	sqlQuery := `SELECT ` + projectColumns + ` FROM projects WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY updated_at DESC`
+	if limit > 0 {
+		sqlQuery += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	projects, err := (&ProjectRepository{db: r.db}).queryProjects(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(projects))
+	for i, p := range projects {
+		results[i] = SearchResult{Project: p}
+	}
+	return results, nil
+}
+
+func (r *SearchRepository) searchIndex(terms []searchTerm, conditions []string, args []interface{}, limit int) ([]SearchResult, error) {
+	// Column weights follow search_index: a match in the name counts most,
+	// then the description, README and technologies.
+	sqlQuery := `
+		SELECT ` + projectColumns + `, m.snippet, m.score
+		FROM projects
+		JOIN (
+			SELECT project_id,
+				snippet(search_index, -1, '**', '**', '...', 12) AS snippet,
+				-bm25(search_index, 0, 10, 2, 5, 3, 0, 4, 4, 1, 1) AS score
+			FROM search_index WHERE search_index MATCH ?
+		) m ON m.project_id = projects.id
+	`
+	args = append([]interface{}{matchExpression(terms)}, args...)
+	if len(conditions) > 0 {
+		sqlQuery += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += ` ORDER BY m.score DESC, name`
+	if limit > 0 {
+		sqlQuery += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var result SearchResult
+		project, err := scanProject(extraScanner{rows, []interface{}{&result.Snippet, &result.Score}})
+		if err != nil {
+			return nil, err
+		}
+		result.Project = *project
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// extraScanner scans the columns scanProject expects followed by extra
+// ones.
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/database"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []searchTerm
+	}{
+		{"react", []searchTerm{{text: "react"}}},
+		{"tech:React status:active", []searchTerm{{column: "tech", text: "React"}, {column: "status", text: "active"}}},
+		{`"state machine" todo:"retry loop"`, []searchTerm{{text: "state machine", phrase: true}, {column: "todo", text: "retry loop", phrase: true}}},
+		{"http://example.com", []searchTerm{{text: "http://example.com"}}},
+		{"tech: !! go", []searchTerm{{text: "tech:"}, {text: "go"}}},
+	}
+	for _, tt := range tests {
+		if got := parseSearchQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSearchQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+
+	got := matchExpression(parseSearchQuery(`tech:react "state machine" o"reilly`))
+	if got != `tech : "react"* AND "state machine" AND "o""reilly"*` {
+		t.Errorf("matchExpression() = %s", got)
+	}
+}
+
+// TestSearchRepository_Search runs with or without FTS5, depending on the
+// sqlite_fts5 build tag; matches are the same either way.
+func TestSearchRepository_Search(t *testing.T) {
+	conn, err := database.New(filepath.Join(t.TempDir(), "projects.db"))
+	if err != nil {
+		t.Fatalf("database.New() failed: %v", err)
+	}
+	defer conn.Close()
+	db := conn.Conn()
+
+	projectRepo := NewProjectRepository(db)
+	now := time.Now()
+	for _, p := range []models.Project{
+		{ID: "p1", Name: "storefront", Path: "/src/storefront", Description: "Online shop", Status: models.StatusActive, CreatedAt: now, UpdatedAt: now},
+		{ID: "p2", Name: "dotfiles", Path: "/src/dotfiles", Notes: "Move the shop scripts out", Status: models.StatusPaused, CreatedAt: now, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "p3", Name: "ledger", Path: "/src/ledger", Status: models.StatusActive, CreatedAt: now, UpdatedAt: now.Add(-2 * time.Hour)},
+	} {
+		p := p
+		if err := projectRepo.Create(&p); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+	techRepo := NewTechnologyRepository(db)
+	for _, tech := range []models.Technology{
+		{ProjectID: "p1", Type: "framework", Name: "React", DetectedFrom: "package.json"},
+		{ProjectID: "p2", Type: "library", Name: "react-dom", Transitive: true, DetectedFrom: "package-lock.json"},
+	} {
+		tech := tech
+		if err := techRepo.Create(&tech); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+	if err := projectRepo.SaveReadme("p3", "# Ledger\n\nDouble-entry bookkeeping for freelancers.", now); err != nil {
+		t.Fatalf("SaveReadme() failed: %v", err)
+	}
+	if _, _, err := NewTodoRepository(db).SyncExtracted("p3", []models.Todo{
+		{Kind: "TODO", Content: "export invoices as PDF", SourceFile: "main.go", LineNumber: 1, Priority: "medium"},
+	}, now); err != nil {
+		t.Fatalf("SyncExtracted() failed: %v", err)
+	}
+	if err := NewAnalysisRepository(db).Create(&models.AIAnalysis{
+		ProjectID: "p2", AnalysisType: "general", Result: "Mostly shell configuration", Model: "test", AnalyzedAt: now,
+	}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Child rows reach the index when a project is reindexed, once its
+	// save is done.
+	repo := NewSearchRepository(db)
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if err := repo.Reindex(id); err != nil {
+			t.Fatalf("Reindex(%s) failed: %v", id, err)
+		}
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"shop", "p1 p2"},
+		{"name:shop", ""},
+		{"description:shop", "p1"},
+		{"tech:react", "p1"},
+		{"tech:react status:paused", ""},
+		{"tech:react-dom", ""},
+		{"status:active", "p1 p3"},
+		{"bookkeeping", "p3"},
+		{"readme:freelance", "p3"},
+		{"todo:invoices", "p3"},
+		{"analysis:shell", "p2"},
+		{`"double-entry bookkeeping"`, "p3"},
+		{"ledger pdf", "p3"},
+	}
+	for _, tt := range tests {
+		results, err := repo.Search(tt.query, 0)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.Project.ID)
+		}
+		if strings.Join(ids, " ") != tt.want {
+			t.Errorf("Search(%q) = %v, want %s", tt.query, ids, tt.want)
+		}
+	}
+
+	// The index follows deletions and edits.
+	if err := projectRepo.SaveReadme("p3", "", now); err != nil {
+		t.Fatalf("SaveReadme() failed: %v", err)
+	}
+	if err := repo.Reindex("p3"); err != nil {
+		t.Fatalf("Reindex() failed: %v", err)
+	}
+	if results, _ := repo.Search("bookkeeping", 0); len(results) != 0 {
+		t.Errorf("Search() after removing the README = %+v, want nothing", results)
+	}
+	if err := projectRepo.Delete("p1"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if results, _ := repo.Search("shop", 0); len(results) != 1 || results[0].Project.ID != "p2" {
+		t.Errorf("Search() after deleting a project = %+v, want p2 only", results)
+	}
+
+	if repo.Indexed() {
+		results, err := repo.Search("scripts", 1)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("Search() = %+v, %v; want one result", results, err)
+		}
+		if !strings.Contains(results[0].Snippet, "**scripts**") || results[0].Score <= 0 {
+			t.Errorf("Search() = snippet %q, score %v; want the match highlighted and a positive score", results[0].Snippet, results[0].Score)
+		}
+	}
+
+	if _, err := repo.Search("  !! ", 0); err == nil {
+		t.Error("Search() of an empty query succeeded")
+	}
+}
@@ -2,23 +2,31 @@ package repository
 
 import (
 	"database/sql"
+	"strings"
 
 	"github.com/snowarch/project-memory/internal/models"
 )
 
 type TechnologyRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewTechnologyRepository(db *sql.DB) *TechnologyRepository {
+func NewTechnologyRepository(db DBTX) *TechnologyRepository {
 	return &TechnologyRepository{db: db}
 }
 
 func (r *TechnologyRepository) Create(tech *models.Technology) error {
 	query := `
-		INSERT INTO technologies (project_id, type, name, version, detected_from)
-		VALUES (?, ?, ?, ?, ?)
-		ON CONFLICT(project_id, type, name) DO UPDATE SET version = ?, detected_from = ?
+		INSERT INTO technologies (project_id, type, name, version, declared_version, scope, transitive, source, features, detected_from)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(project_id, type, name) DO UPDATE SET
+			version = excluded.version,
+			declared_version = excluded.declared_version,
+			scope = excluded.scope,
+			transitive = excluded.transitive,
+			source = excluded.source,
+			features = excluded.features,
+			detected_from = excluded.detected_from
 	`
 
 	_, err := r.db.Exec(query,
@@ -26,22 +34,42 @@ func (r *TechnologyRepository) Create(tech *models.Technology) error {
 		tech.Type,
 		tech.Name,
 		tech.Version,
-		tech.DetectedFrom,
-		tech.Version,
+		nullIfEmpty(tech.DeclaredVersion),
+		nullIfEmpty(string(tech.Scope)),
+		tech.Transitive,
+		nullIfEmpty(tech.Source),
+		nullIfEmpty(strings.Join(tech.Features, ",")),
 		tech.DetectedFrom,
 	)
 
 	return err
 }
 
+// GetByProject returns the technologies a project declares directly.
+// Transitive dependencies read from lock files are left out; use
+// GetAllByProject to include them.
 func (r *TechnologyRepository) GetByProject(projectID string) ([]models.Technology, error) {
-	query := `
-		SELECT id, project_id, type, name, version, detected_from
-		FROM technologies WHERE project_id = ?
+	return r.queryTechnologies(`
+		SELECT `+technologyColumns+`
+		FROM technologies WHERE project_id = ? AND transitive = 0
 		ORDER BY type, name
-	`
+	`, projectID)
+}
 
-	rows, err := r.db.Query(query, projectID)
+// GetAllByProject returns every technology of a project, transitive
+// dependencies last.
+func (r *TechnologyRepository) GetAllByProject(projectID string) ([]models.Technology, error) {
+	return r.queryTechnologies(`
+		SELECT `+technologyColumns+`
+		FROM technologies WHERE project_id = ?
+		ORDER BY transitive, type, name
+	`, projectID)
+}
+
+const technologyColumns = `id, project_id, type, name, version, declared_version, scope, transitive, source, features, detected_from`
+
+func (r *TechnologyRepository) queryTechnologies(query string, args ...interface{}) ([]models.Technology, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -50,21 +78,35 @@ func (r *TechnologyRepository) GetByProject(projectID string) ([]models.Technolo
 	var techs []models.Technology
 	for rows.Next() {
 		var tech models.Technology
+		var version, declared, scope, source, features, detectedFrom sql.NullString
 		err := rows.Scan(
 			&tech.ID,
 			&tech.ProjectID,
 			&tech.Type,
 			&tech.Name,
-			&tech.Version,
-			&tech.DetectedFrom,
+			&version,
+			&declared,
+			&scope,
+			&tech.Transitive,
+			&source,
+			&features,
+			&detectedFrom,
 		)
 		if err != nil {
 			return nil, err
 		}
+		tech.Version = version.String
+		tech.DeclaredVersion = declared.String
+		tech.Scope = models.DependencyScope(scope.String)
+		tech.Source = source.String
+		if features.String != "" {
+			tech.Features = strings.Split(features.String, ",")
+		}
+		tech.DetectedFrom = detectedFrom.String
 		techs = append(techs, tech)
 	}
 
-	return techs, nil
+	return techs, rows.Err()
 }
 
 func (r *TechnologyRepository) DeleteByProject(projectID string) error {
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestTechnologyRepository_Transitive(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTechnologyRepository(db)
+	techs := []models.Technology{
+		{ProjectID: "p1", Type: "framework", Name: "react", Version: "18.2.0", DeclaredVersion: "^18.2.0", Scope: models.ScopeProd, DetectedFrom: "package.json"},
+		{ProjectID: "p1", Type: "dependency", Name: "typescript", Version: "5.3.3", DeclaredVersion: "~5.3.0", Scope: models.ScopeDev, DetectedFrom: "package.json"},
+		{ProjectID: "p1", Type: "dependency", Name: "loose-envify", Version: "1.4.0", Scope: models.ScopeProd, Transitive: true, DetectedFrom: "package-lock.json"},
+		{ProjectID: "p1", Type: "runtime", Name: "Node.js", DetectedFrom: "package.json"},
+	}
+	for i := range techs {
+		if err := repo.Create(&techs[i]); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+	}
+
+	direct, err := repo.GetByProject("p1")
+	if err != nil {
+		t.Fatalf("GetByProject() failed: %v", err)
+	}
+	if len(direct) != 3 {
+		t.Fatalf("GetByProject() returned %d technologies, want 3 without transitive ones", len(direct))
+	}
+	for _, tech := range direct {
+		if tech.Name == "typescript" && (tech.Scope != models.ScopeDev || tech.DeclaredVersion != "~5.3.0" || tech.Version != "5.3.3") {
+			t.Errorf("typescript = %+v, want dev scope with declared and resolved versions", tech)
+		}
+		if tech.Name == "Node.js" && (tech.Scope != "" || tech.DeclaredVersion != "") {
+			t.Errorf("Node.js = %+v, want no scope or declared version", tech)
+		}
+	}
+
+	all, err := repo.GetAllByProject("p1")
+	if err != nil {
+		t.Fatalf("GetAllByProject() failed: %v", err)
+	}
+	if len(all) != 4 || !all[3].Transitive || all[3].Name != "loose-envify" {
+		t.Errorf("GetAllByProject() = %+v, want the transitive dependency last", all)
+	}
+}
+
+func TestTechnologyRepository_SourceAndFeatures(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTechnologyRepository(db)
+	tech := models.Technology{
+		ProjectID:    "p1",
+		Type:         "framework",
+		Name:         "axum",
+		Scope:        models.ScopeProd,
+		Source:       "git+https://github.com/tokio-rs/axum?branch=main",
+		Features:     []string{"macros", "ws"},
+		DetectedFrom: "Cargo.toml",
+	}
+	if err := repo.Create(&tech); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	techs, err := repo.GetByProject("p1")
+	if err != nil {
+		t.Fatalf("GetByProject() failed: %v", err)
+	}
+	if len(techs) != 1 {
+		t.Fatalf("GetByProject() returned %d technologies, want 1", len(techs))
+	}
+	got := techs[0]
+	if got.Source != tech.Source || len(got.Features) != 2 || got.Features[0] != "macros" || got.Features[1] != "ws" {
+		t.Errorf("GetByProject() = %+v, want source and features preserved", got)
+	}
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+type TodoRepository struct {
+	db DBTX
+}
+
+func NewTodoRepository(db DBTX) *TodoRepository {
+	return &TodoRepository{db: db}
+}
+
+// TodoFilter narrows the todos returned by List. Empty fields match every
+// item; only open items are returned unless Completed or All is set.
+type TodoFilter struct {
+	Source   models.TodoSource
+	Priority string
+	Kind     string
+	// File matches items whose source file starts with it.
+	File      string
+	Completed bool
+	All       bool
+}
+
+// todoKey identifies an extracted item across scans; line numbers shift as
+// code is edited, so they are not part of it.
+type todoKey struct {
+	file    string
+	kind    string
+	content string
+}
+
+// SyncExtracted reconciles the items extracted from the code of a project
+// with the stored ones. Items found again keep their ID and creation time
+// and get their new line and priority, items that reappear are reopened,
+// new ones are added and open items no longer found are marked completed.
+// It returns how many items were added and completed.
+func (r *TodoRepository) SyncExtracted(projectID string, found []models.Todo, now time.Time) (added, completed int, err error) {
+	// Open items come first, so a comment matches the item already open
+	// before one completed earlier.
+	existing, err := r.query(`
+		SELECT `+todoColumns+` FROM todos
+		WHERE project_id = ? AND source = ?
+		ORDER BY completed, id
+	`, projectID, models.TodoSourceCode)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	byKey := make(map[todoKey][]*models.Todo)
+	for i := range existing {
+		key := todoKey{existing[i].SourceFile, existing[i].Kind, existing[i].Content}
+		byKey[key] = append(byKey[key], &existing[i])
+	}
+
+	matched := make(map[int]bool)
+	for _, t := range found {
+		key := todoKey{t.SourceFile, t.Kind, t.Content}
+		if candidates := byKey[key]; len(candidates) > 0 {
+			old := candidates[0]
+			byKey[key] = candidates[1:]
+			matched[old.ID] = true
+			if old.Completed || old.LineNumber != t.LineNumber || old.Priority != t.Priority {
+				_, err := r.db.Exec(`
+					UPDATE todos SET line_number = ?, priority = ?, completed = 0, completed_at = NULL
+					WHERE id = ?
+				`, t.LineNumber, t.Priority, old.ID)
+				if err != nil {
+					return added, completed, err
+				}
+			}
+			continue
+		}
+
+		_, err := r.db.Exec(`
+			INSERT INTO todos (project_id, source, content, source_file, line_number, kind, priority, completed, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?)
+		`, projectID, models.TodoSourceCode, t.Content, t.SourceFile, t.LineNumber, t.Kind, t.Priority, now.Unix())
+		if err != nil {
+			return added, completed, err
+		}
+		added++
+	}
+
+	for _, old := range existing {
+		if old.Completed || matched[old.ID] {
+			continue
+		}
+		_, err := r.db.Exec(`UPDATE todos SET completed = 1, completed_at = ? WHERE id = ?`, now.Unix(), old.ID)
+		if err != nil {
+			return added, completed, err
+		}
+		completed++
+	}
+
+	return added, completed, nil
+}
+
+// Create adds a manual task and sets its ID and creation time.
+func (r *TodoRepository) Create(t *models.Todo) error {
+	t.Source = models.TodoSourceManual
+	t.CreatedAt = time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO todos (project_id, source, content, priority, completed, created_at)
+		VALUES (?, ?, ?, ?, 0, ?)
+	`, t.ProjectID, t.Source, t.Content, t.Priority, t.CreatedAt.Unix())
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.ID = int(id)
+	return nil
+}
+
+func (r *TodoRepository) GetByID(id int) (*models.Todo, error) {
+	todos, err := r.query(`SELECT `+todoColumns+` FROM todos WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, fmt.Errorf("todo not found: %d", id)
+	}
+	return &todos[0], nil
+}
+
+// Complete marks an item completed at the given time.
+func (r *TodoRepository) Complete(id int, now time.Time) error {
+	_, err := r.db.Exec(`UPDATE todos SET completed = 1, completed_at = ? WHERE id = ?`, now.Unix(), id)
+	return err
+}
+
+// OpenCounts returns the number of open items from source per project.
+// Projects without any are left out.
+func (r *TodoRepository) OpenCounts(source models.TodoSource) (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT project_id, COUNT(*) FROM todos
+		WHERE source = ? AND completed = 0
+		GROUP BY project_id
+	`, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var projectID string
+		var count int
+		if err := rows.Scan(&projectID, &count); err != nil {
+			return nil, err
+		}
+		counts[projectID] = count
+	}
+	return counts, rows.Err()
+}
+
+// List returns the todos matching filter of a project, or of every project
+// when projectID is empty, the highest priority first, then by file and
+// line.
+func (r *TodoRepository) List(projectID string, filter TodoFilter) ([]models.Todo, error) {
+	var conditions []string
+	var args []interface{}
+
+	if projectID != "" {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, projectID)
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "source = ?")
+		args = append(args, filter.Source)
+	}
+	if !filter.All {
+		conditions = append(conditions, "completed = ?")
+		args = append(args, filter.Completed)
+	}
+	if filter.Priority != "" {
+		conditions = append(conditions, "priority = ?")
+		args = append(args, strings.ToLower(filter.Priority))
+	}
+	if filter.Kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, strings.ToUpper(filter.Kind))
+	}
+	if filter.File != "" {
+		conditions = append(conditions, "source_file LIKE ? ESCAPE '\\'")
+		args = append(args, escapeLike(filter.File)+"%")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return r.query(`
+		SELECT `+todoColumns+` FROM todos
+		`+where+`
+		ORDER BY completed,
+			CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END,
+			source_file, line_number, id
+	`, args...)
+}
+
+const todoColumns = `id, project_id, source, content, source_file, line_number, kind, priority, completed, created_at, completed_at`
+
+func (r *TodoRepository) query(query string, args ...interface{}) ([]models.Todo, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var todos []models.Todo
+	for rows.Next() {
+		var t models.Todo
+		var sourceFile, kind, priority sql.NullString
+		var lineNumber, completedAt sql.NullInt64
+		var createdAt int64
+		err := rows.Scan(&t.ID, &t.ProjectID, &t.Source, &t.Content, &sourceFile, &lineNumber, &kind, &priority, &t.Completed, &createdAt, &completedAt)
+		if err != nil {
+			return nil, err
+		}
+		t.SourceFile = sourceFile.String
+		t.LineNumber = int(lineNumber.Int64)
+		t.Kind = kind.String
+		t.Priority = priority.String
+		t.CreatedAt = time.Unix(createdAt, 0)
+		t.CompletedAt = timeOrNil(completedAt)
+		todos = append(todos, t)
+	}
+
+	return todos, rows.Err()
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
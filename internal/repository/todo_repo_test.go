@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestTodoRepository_SyncExtracted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTodoRepository(db)
+	now := time.Unix(1700000000, 0)
+
+	first := []models.Todo{
+		{Kind: "TODO", Content: "wire the flags", SourceFile: "main.go", LineNumber: 3, Priority: "medium"},
+		{Kind: "FIXME", Content: "close rows", SourceFile: "db/db.go", LineNumber: 10, Priority: "high"},
+		{Kind: "HACK", Content: "retry", SourceFile: "db/db.go", LineNumber: 20, Priority: "low"},
+	}
+	added, completed, err := repo.SyncExtracted("p1", first, now)
+	if err != nil {
+		t.Fatalf("SyncExtracted() failed: %v", err)
+	}
+	if added != 3 || completed != 0 {
+		t.Errorf("SyncExtracted() = %d added, %d completed; want 3, 0", added, completed)
+	}
+
+	before, err := repo.List("p1", TodoFilter{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(before) != 3 || before[0].Kind != "FIXME" || before[2].Kind != "HACK" {
+		t.Fatalf("List() = %+v, want FIXME first and HACK last", before)
+	}
+	ids := make(map[string]int)
+	for _, todo := range before {
+		ids[todo.Content] = todo.ID
+	}
+
+	// The TODO moved down, the FIXME was fixed and a new item appeared.
+	second := []models.Todo{
+		{Kind: "TODO", Content: "wire the flags", SourceFile: "main.go", LineNumber: 8, Priority: "medium"},
+		{Kind: "HACK", Content: "retry", SourceFile: "db/db.go", LineNumber: 18, Priority: "low"},
+		{Kind: "XXX", Content: "tag first", SourceFile: "release.sh", LineNumber: 2, Priority: "high"},
+	}
+	added, completed, err = repo.SyncExtracted("p1", second, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SyncExtracted() failed: %v", err)
+	}
+	if added != 1 || completed != 1 {
+		t.Errorf("SyncExtracted() = %d added, %d completed; want 1, 1", added, completed)
+	}
+
+	open, err := repo.List("p1", TodoFilter{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(open) != 3 {
+		t.Fatalf("List() returned %d open items, want 3: %+v", len(open), open)
+	}
+	for _, todo := range open {
+		if todo.Content == "wire the flags" && (todo.ID != ids["wire the flags"] || todo.LineNumber != 8) {
+			t.Errorf("moved item = %+v, want ID %d at line 8", todo, ids["wire the flags"])
+		}
+	}
+
+	done, err := repo.List("p1", TodoFilter{Completed: true})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(done) != 1 || done[0].ID != ids["close rows"] || done[0].CompletedAt == nil || !done[0].CompletedAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("completed items = %+v, want the FIXME completed at the second sync", done)
+	}
+
+	// The fixed comment comes back and is reopened.
+	if _, _, err := repo.SyncExtracted("p1", append(second, first[1]), now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("SyncExtracted() failed: %v", err)
+	}
+	done, err = repo.List("p1", TodoFilter{Completed: true})
+	if err != nil || len(done) != 0 {
+		t.Errorf("completed items = %+v, %v; want the FIXME reopened", done, err)
+	}
+
+	all, err := repo.List("p1", TodoFilter{All: true})
+	if err != nil || len(all) != 4 {
+		t.Errorf("List(All) = %+v, %v; want 4 items", all, err)
+	}
+}
+
+func TestTodoRepository_ListFilters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTodoRepository(db)
+	found := []models.Todo{
+		{Kind: "TODO", Content: "a", SourceFile: "cmd/main.go", LineNumber: 1, Priority: "medium"},
+		{Kind: "FIXME", Content: "b", SourceFile: "cmd/main.go", LineNumber: 2, Priority: "high"},
+		{Kind: "TODO", Content: "c", SourceFile: "internal/x_y.go", LineNumber: 3, Priority: "high"},
+		{Kind: "TODO", Content: "d", SourceFile: "internal/xay.go", LineNumber: 4, Priority: "low"},
+	}
+	if _, _, err := repo.SyncExtracted("p1", found, time.Now()); err != nil {
+		t.Fatalf("SyncExtracted() failed: %v", err)
+	}
+	if _, _, err := repo.SyncExtracted("p2", found[:1], time.Now()); err != nil {
+		t.Fatalf("SyncExtracted() failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter TodoFilter
+		want   string
+	}{
+		{"all open", TodoFilter{}, "bcad"},
+		{"priority", TodoFilter{Priority: "HIGH"}, "bc"},
+		{"kind", TodoFilter{Kind: "todo"}, "cad"},
+		{"file prefix", TodoFilter{File: "cmd/"}, "ba"},
+		{"file with wildcard", TodoFilter{File: "internal/x_"}, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, err := repo.List("p1", tt.filter)
+			if err != nil {
+				t.Fatalf("List() failed: %v", err)
+			}
+			got := ""
+			for _, todo := range todos {
+				got += todo.Content
+			}
+			if got != tt.want {
+				t.Errorf("List(%+v) = %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoRepository_ManualTasks(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTodoRepository(db)
+	task := &models.Todo{ProjectID: "p1", Content: "write the release notes", Priority: "high"}
+	if err := repo.Create(task); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if task.ID == 0 || task.Source != models.TodoSourceManual {
+		t.Fatalf("Create() left %+v, want an ID and the manual source", task)
+	}
+	if err := repo.Create(&models.Todo{ProjectID: "p2", Content: "rename the binary", Priority: "low"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Scans reconcile code comments only and leave tasks alone.
+	found := []models.Todo{{Kind: "TODO", Content: "wire the flags", SourceFile: "main.go", LineNumber: 3, Priority: "medium"}}
+	if _, completed, err := repo.SyncExtracted("p1", found, time.Now()); err != nil || completed != 0 {
+		t.Fatalf("SyncExtracted() = %d completed, %v; want the task untouched", completed, err)
+	}
+
+	tasks, err := repo.List("", TodoFilter{Source: models.TodoSourceManual})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != task.ID {
+		t.Fatalf("List() = %+v, want both tasks, the high priority one first", tasks)
+	}
+
+	counts, err := repo.OpenCounts(models.TodoSourceManual)
+	if err != nil {
+		t.Fatalf("OpenCounts() failed: %v", err)
+	}
+	if counts["p1"] != 1 || counts["p2"] != 1 {
+		t.Errorf("OpenCounts() = %v, want one task in each project", counts)
+	}
+
+	if err := repo.Complete(task.ID, time.Now()); err != nil {
+		t.Fatalf("Complete() failed: %v", err)
+	}
+	got, err := repo.GetByID(task.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if !got.Completed || got.CompletedAt == nil || got.Content != task.Content {
+		t.Errorf("GetByID() = %+v, want the task completed", got)
+	}
+
+	counts, err = repo.OpenCounts(models.TodoSourceManual)
+	if err != nil || counts["p1"] != 0 {
+		t.Errorf("OpenCounts() = %v, %v; want no open task left in p1", counts, err)
+	}
+	if _, err := repo.GetByID(9999); err == nil {
+		t.Error("GetByID() of a missing item succeeded")
+	}
+}
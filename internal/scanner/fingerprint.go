@@ -0,0 +1,221 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/snowarch/project-memory/internal/detect"
+	"github.com/snowarch/project-memory/internal/git"
+	"github.com/snowarch/project-memory/internal/ignore"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/stats"
+)
+
+// gitHeadFile is the pseudo path under which the resolved HEAD commit is
+// stored in a fingerprint.
+const gitHeadFile = ".git/HEAD"
+
+// sourcesFile is the pseudo path under which a digest of the source files
+// of a project is stored in a fingerprint. It only decides whether language
+// stats and TODOs are read again, so FingerprintChanged ignores it.
+const sourcesFile = "<sources>"
+
+type trackedFile struct {
+	path     string
+	fileType string
+}
+
+// trackedFiles are the files whose changes cause a project to be analyzed
+// again during an incremental scan, in addition to every file read by a
+// technology detector.
+var trackedFiles = []trackedFile{
+	{"package.json", "manifest"},
+	{"requirements.txt", "manifest"},
+	{"go.mod", "manifest"},
+	{"Cargo.toml", "manifest"},
+	{"pom.xml", "manifest"},
+	{"build.gradle", "manifest"},
+	{"pnpm-workspace.yaml", "manifest"},
+	{"go.work", "manifest"},
+	{"README.md", "readme"},
+	{".git/config", "git"},
+}
+
+// SetPreviousFingerprints enables incremental scanning. Projects whose
+// fingerprint matches the one recorded for their path are reported as
+// unchanged instead of being analyzed again.
+func (s *Scanner) SetPreviousFingerprints(previous map[string][]models.ProjectFile) {
+	s.previous = previous
+}
+
+// Fingerprint records size, modification time and content hash of the
+// tracked files of a project, plus the commit its git HEAD points to.
+// Hashes from previous are reused for files whose size and modification
+// time did not change, so unchanged manifests are never read.
+func Fingerprint(projectPath string, previous []models.ProjectFile) []models.ProjectFile {
+	known := make(map[string]models.ProjectFile, len(previous))
+	for _, f := range previous {
+		known[f.FilePath] = f
+	}
+
+	candidates := append([]trackedFile(nil), trackedFiles...)
+	for _, manifest := range detect.ManifestFiles(projectPath) {
+		candidates = append(candidates, trackedFile{manifest, "manifest"})
+	}
+
+	var files []models.ProjectFile
+	seen := make(map[string]bool)
+	for _, tracked := range candidates {
+		if seen[tracked.path] {
+			continue
+		}
+		seen[tracked.path] = true
+
+		fullPath := filepath.Join(projectPath, filepath.FromSlash(tracked.path))
+		info, err := os.Stat(fullPath)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		f := models.ProjectFile{
+			FilePath:     tracked.path,
+			FileType:     tracked.fileType,
+			SizeBytes:    info.Size(),
+			LastModified: info.ModTime(),
+		}
+
+		if prev, ok := known[tracked.path]; ok && prev.ContentHash != "" &&
+			prev.SizeBytes == f.SizeBytes && prev.LastModified.Unix() == f.LastModified.Unix() {
+			f.ContentHash = prev.ContentHash
+		} else if hash, err := hashFile(fullPath); err == nil {
+			f.ContentHash = hash
+		}
+
+		files = append(files, f)
+	}
+
+	if commit, info := resolveGitHead(projectPath); commit != "" {
+		files = append(files, models.ProjectFile{
+			FilePath:     gitHeadFile,
+			FileType:     "git_head",
+			LastModified: info.ModTime(),
+			ContentHash:  commit,
+		})
+	}
+
+	return files
+}
+
+// sourcesFingerprint digests the names, sizes and modification times of the
+// files stats and TODOs are read from, leaving out the directories in
+// exclude. Only the directory entries are read, not the files. The size is
+// the total of the files and the modification time the latest.
+func sourcesFingerprint(projectPath string, exclude []string) models.ProjectFile {
+	f := models.ProjectFile{FilePath: sourcesFile, FileType: "sources"}
+	h := sha256.New()
+	ignore.WalkExcept(projectPath, exclude, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !stats.Countable(info) {
+			return nil
+		}
+		rel, _ := filepath.Rel(projectPath, path)
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		f.SizeBytes += info.Size()
+		if info.ModTime().After(f.LastModified) {
+			f.LastModified = info.ModTime()
+		}
+		return nil
+	})
+	f.ContentHash = hex.EncodeToString(h.Sum(nil))
+	return f
+}
+
+// FingerprintChanged reports whether two fingerprints differ in the set of
+// tracked files or in any of their contents. The source tree digest is not
+// compared; see SourcesChanged.
+func FingerprintChanged(previous, current []models.ProjectFile) bool {
+	previous, current = withoutSources(previous), withoutSources(current)
+	if len(previous) == 0 || len(previous) != len(current) {
+		return true
+	}
+
+	hashes := make(map[string]string, len(previous))
+	for _, f := range previous {
+		hashes[f.FilePath] = f.ContentHash
+	}
+	for _, f := range current {
+		hash, ok := hashes[f.FilePath]
+		if !ok || hash != f.ContentHash {
+			return true
+		}
+	}
+	return false
+}
+
+// SourcesChanged reports whether the source files of a project changed
+// between two fingerprints. A fingerprint without a source tree digest
+// counts as changed.
+func SourcesChanged(previous, current []models.ProjectFile) bool {
+	before, after := sourcesDigest(previous), sourcesDigest(current)
+	return before == "" || before != after
+}
+
+func sourcesDigest(files []models.ProjectFile) string {
+	for _, f := range files {
+		if f.FilePath == sourcesFile {
+			return f.ContentHash
+		}
+	}
+	return ""
+}
+
+func withoutSources(files []models.ProjectFile) []models.ProjectFile {
+	kept := make([]models.ProjectFile, 0, len(files))
+	for _, f := range files {
+		if f.FilePath != sourcesFile {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveGitHead returns the commit HEAD points to, with the HEAD file of
+// the working tree, which lives outside the project for worktrees and
+// submodules.
+func resolveGitHead(projectPath string) (string, os.FileInfo) {
+	repo, err := git.Open(projectPath)
+	if err != nil {
+		return "", nil
+	}
+	info, err := os.Stat(filepath.Join(repo.GitDir, "HEAD"))
+	if err != nil {
+		return "", nil
+	}
+	ref, hash, err := repo.Head()
+	if err != nil {
+		return "", nil
+	}
+	if hash == "" {
+		// An unborn branch has no commit yet; the ref name still
+		// identifies it.
+		return ref, info
+	}
+	return hash, info
+}
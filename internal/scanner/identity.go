@@ -0,0 +1,228 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/snowarch/project-memory/internal/git"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// IDMarkerFile holds a project ID inside the project directory, so the
+// project keeps its identity wherever the directory is moved.
+const IDMarkerFile = ".pmem-id"
+
+// ReadIDMarker returns the project ID stored in the marker file of a
+// project, or "" when there is none.
+func ReadIDMarker(projectPath string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, IDMarkerFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// WriteIDMarker stores id in the marker file of a project.
+func WriteIDMarker(projectPath, id string) error {
+	return os.WriteFile(filepath.Join(projectPath, IDMarkerFile), []byte(id+"\n"), 0644)
+}
+
+// SetPreviousRootCommits gives the root commits recorded for the projects,
+// keyed by path. A project whose HEAD only moved forward since its previous
+// fingerprint keeps its root commit without its history being read.
+func (s *Scanner) SetPreviousRootCommits(roots map[string]string) {
+	s.roots = roots
+}
+
+// rootCommit returns the hash of the first commit of the repository at
+// projectPath. Forks and clones share it regardless of their remote.
+func (s *Scanner) rootCommit(projectPath string) string {
+	repo, err := git.Open(projectPath)
+	if err != nil {
+		return ""
+	}
+	defer repo.Close()
+
+	if known := s.roots[projectPath]; known != "" {
+		for _, f := range s.previous[projectPath] {
+			if f.FilePath != gitHeadFile {
+				continue
+			}
+			_, head, err := repo.Head()
+			if err != nil || head == "" {
+				break
+			}
+			if ok, err := repo.IsAncestor(f.ContentHash, head); err == nil && ok {
+				return known
+			}
+		}
+	}
+
+	root, err := repo.RootCommit()
+	if err != nil {
+		return ""
+	}
+	return root
+}
+
+// lastCommitTime returns when the commit HEAD points to in the repository
+// at projectPath was committed, or nil when it has no commits.
+func lastCommitTime(projectPath string) *time.Time {
+	repo, err := git.Open(projectPath)
+	if err != nil {
+		return nil
+	}
+	defer repo.Close()
+
+	last, err := repo.LastCommitTime()
+	if err != nil || last.IsZero() {
+		return nil
+	}
+	return &last
+}
+
+// Relocator matches project directories found during a scan against
+// tracked projects whose directories have disappeared, so a moved or
+// renamed project keeps its row instead of being added again.
+//
+// A found directory matches a missing project, in order of preference, by
+// its .pmem-id marker, its git remote or its root commit. With MatchNames
+// it also matches by its directory name when that name is unique among the
+// missing projects and neither the remote nor the root commit contradict
+// it; an unrelated project may share a name, so that is left to callers
+// that confirm such matches.
+type Relocator struct {
+	missing    []models.Project
+	MatchNames bool
+}
+
+// MatchKind tells what a directory was matched to a missing project by.
+type MatchKind string
+
+const (
+	MatchMarker     MatchKind = "marker"
+	MatchRemote     MatchKind = "remote"
+	MatchRootCommit MatchKind = "root commit"
+	MatchName       MatchKind = "directory name"
+)
+
+// NewRelocator returns a relocator for the given missing projects.
+func NewRelocator(missing []models.Project) *Relocator {
+	return &Relocator{missing: missing}
+}
+
+// Find returns the missing project the scan result describes, if any, and
+// what it was matched by.
+func (r *Relocator) Find(res Result) (*models.Project, MatchKind) {
+	if i, kind := r.index(res); i >= 0 {
+		p := r.missing[i]
+		return &p, kind
+	}
+	return nil, ""
+}
+
+// Match is like Find but removes the returned project, so a missing project
+// is relocated at most once.
+func (r *Relocator) Match(res Result) *models.Project {
+	i, _ := r.index(res)
+	if i < 0 {
+		return nil
+	}
+	p := r.missing[i]
+	r.missing = append(r.missing[:i], r.missing[i+1:]...)
+	return &p
+}
+
+// Remaining returns the missing projects that were not matched.
+func (r *Relocator) Remaining() []models.Project {
+	if r == nil {
+		return nil
+	}
+	return r.missing
+}
+
+func (r *Relocator) index(res Result) (int, MatchKind) {
+	if r == nil {
+		return -1, ""
+	}
+	found := res.Project
+
+	if res.MarkerID != "" {
+		for i, p := range r.missing {
+			if p.ID == res.MarkerID {
+				return i, MatchMarker
+			}
+		}
+	}
+
+	remote := NormalizeRemote(found.GitRemote)
+	if remote != "" {
+		for i, p := range r.missing {
+			if NormalizeRemote(p.GitRemote) == remote {
+				return i, MatchRemote
+			}
+		}
+	}
+
+	if found.RootCommit != "" {
+		for i, p := range r.missing {
+			if p.RootCommit != found.RootCommit {
+				continue
+			}
+			// Forks share the root commit of the repository they were
+			// forked from; a different remote tells them apart.
+			if old := NormalizeRemote(p.GitRemote); old == "" || remote == "" || old == remote {
+				return i, MatchRootCommit
+			}
+		}
+	}
+
+	if !r.MatchNames {
+		return -1, ""
+	}
+	byName := -1
+	for i, p := range r.missing {
+		if filepath.Base(p.Path) != filepath.Base(res.Path) {
+			continue
+		}
+		if byName >= 0 {
+			return -1, ""
+		}
+		byName = i
+	}
+	if byName < 0 {
+		return -1, ""
+	}
+	p := r.missing[byName]
+	if old := NormalizeRemote(p.GitRemote); old != "" && remote != "" && old != remote {
+		return -1, ""
+	}
+	if p.RootCommit != "" && found.RootCommit != "" && p.RootCommit != found.RootCommit {
+		return -1, ""
+	}
+	return byName, MatchName
+}
+
+// NormalizeRemote reduces a git remote URL to host/path so the SSH and HTTPS
+// forms of the same repository compare equal.
+func NormalizeRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+
+	if i := strings.Index(remote, "://"); i >= 0 {
+		remote = remote[i+3:]
+	} else if i := strings.Index(remote, ":"); i >= 0 && !strings.Contains(remote[:i], "/") {
+		// scp-like syntax: git@host:owner/repo
+		remote = remote[:i] + "/" + remote[i+1:]
+	}
+	if i := strings.Index(remote, "@"); i >= 0 && i < strings.Index(remote+"/", "/") {
+		remote = remote[i+1:]
+	}
+
+	remote = strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+	return strings.ToLower(remote)
+}
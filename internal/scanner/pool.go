@@ -0,0 +1,161 @@
+package scanner
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/stats"
+	"github.com/snowarch/project-memory/internal/todo"
+)
+
+// Result is the outcome of analyzing a single project directory. When
+// Unchanged is set the project matched its previous fingerprint and only
+// Path, MarkerID, Files, SourcesChanged and Members are filled in, plus
+// Languages and Todos when SourcesChanged is set: the source files are
+// tracked apart from the manifests, so TODOs removed from the code are seen
+// without the project being analyzed again. Members holds the results for
+// the member projects of a workspace root.
+type Result struct {
+	Path         string
+	Project      models.Project
+	Technologies []models.Technology
+	Languages    []models.LanguageStat
+	Todos        []models.Todo
+	Readme       string
+	Files        []models.ProjectFile
+	MarkerID     string
+	Members      []Result
+	Unchanged    bool
+	// SourcesChanged is set on unchanged projects whose source files
+	// changed since the previous scan.
+	SourcesChanged bool
+	Err            error
+}
+
+// AnalyzeConcurrent analyzes the given project directories with up to jobs
+// workers. Results are passed to handle one at a time from the calling
+// goroutine, so handle can safely write to a database that only allows a
+// single connection.
+//
+// When ctx is cancelled no further directories are started, results that are
+// still in flight are discarded and ctx.Err() is returned. If handle returns
+// an error the scan stops the same way and that error is returned.
+func (s *Scanner) AnalyzeConcurrent(ctx context.Context, paths []string, jobs int, handle func(Result) error) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := make(chan string)
+	results := make(chan Result)
+
+	go func() {
+		defer close(work)
+		for _, path := range paths {
+			select {
+			case work <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range work {
+				select {
+				case results <- s.analyze(path):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var handleErr error
+	for res := range results {
+		if handleErr != nil || ctx.Err() != nil {
+			continue
+		}
+		if err := handle(res); err != nil {
+			handleErr = err
+			cancel()
+		}
+	}
+
+	if handleErr != nil {
+		return handleErr
+	}
+	return ctx.Err()
+}
+
+func (s *Scanner) analyze(path string) Result {
+	return s.analyzeNamed(path, filepath.Base(path))
+}
+
+// analyzeNamed analyzes the project at path and, when it is a workspace
+// root, each of its members. Members are named after the root followed by
+// their path inside it.
+func (s *Scanner) analyzeNamed(path, name string) Result {
+	res := Result{Path: path, MarkerID: ReadIDMarker(path)}
+
+	ws := DetectWorkspace(path)
+	var members []string
+	if ws != nil {
+		members = ws.Members
+		for _, member := range ws.Members {
+			rel, _ := filepath.Rel(path, member)
+			res.Members = append(res.Members, s.analyzeNamed(member, name+"/"+filepath.ToSlash(rel)))
+		}
+	}
+
+	previous, known := s.previous[path]
+	res.Files = append(Fingerprint(path, previous), sourcesFingerprint(path, members))
+	if known && !FingerprintChanged(previous, res.Files) {
+		res.Unchanged = true
+		if SourcesChanged(previous, res.Files) {
+			res.SourcesChanged = true
+			res.Err = countSources(&res, members)
+		}
+		return res
+	}
+
+	res.Project, res.Err = s.analyzeProject(path, name)
+	if res.Err != nil {
+		return res
+	}
+
+	res.Technologies, res.Err = s.DetectTechnologies(path)
+	if res.Err == nil && ws != nil {
+		res.Technologies = append(res.Technologies, ws.Technology())
+	}
+	if res.Err == nil {
+		res.Err = countSources(&res, members)
+	}
+	res.Readme = readReadme(path)
+	return res
+}
+
+// countSources fills in the language stats and TODOs of res from its
+// source files. The members of a workspace root are left out, as they are
+// counted as projects of their own.
+func countSources(res *Result, members []string) error {
+	var err error
+	if res.Languages, err = stats.Count(res.Path, members...); err != nil {
+		return err
+	}
+	res.Todos, err = todo.Extract(res.Path, members...)
+	return err
+}
@@ -3,52 +3,121 @@ package scanner
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/snowarch/project-memory/internal/detect"
+	"github.com/snowarch/project-memory/internal/git"
+	"github.com/snowarch/project-memory/internal/ignore"
 	"github.com/snowarch/project-memory/internal/logger"
 	"github.com/snowarch/project-memory/internal/models"
 )
 
+// DefaultMaxDepth is how many directory levels below the root ScanProjects
+// descends by default while looking for projects.
+const DefaultMaxDepth = 3
+
+// skippedDirs are never descended into while discovering projects.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	".git":         true,
+	"target":       true,
+}
+
 type Scanner struct {
 	rootPath string
+	maxDepth int
+	previous map[string][]models.ProjectFile
+	roots    map[string]string
 }
 
+// New returns a scanner for the projects below rootPath. A relative root is
+// made absolute, so the paths it reports can be stored as they are.
 func New(rootPath string) *Scanner {
-	return &Scanner{rootPath: rootPath}
+	if abs, err := filepath.Abs(rootPath); err == nil {
+		rootPath = abs
+	}
+	return &Scanner{rootPath: rootPath, maxDepth: DefaultMaxDepth}
+}
+
+// SetMaxDepth limits how many levels below the root are searched for
+// projects. A depth of 1 only inspects the immediate children of the root;
+// zero or a negative value removes the limit.
+func (s *Scanner) SetMaxDepth(depth int) {
+	s.maxDepth = depth
 }
 
 func (s *Scanner) ScanProjects() ([]models.Project, error) {
 	var projects []models.Project
 
-	entries, err := os.ReadDir(s.rootPath)
+	paths, err := s.DiscoverProjects()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
+	for _, projectPath := range paths {
+		logger.Debug("Analyzing project: %s", projectPath)
+		name := filepath.Base(projectPath)
+		project, err := s.analyzeProject(projectPath, name)
+		if err != nil {
+			logger.Warn("Failed to analyze project %s: %v", name, err)
 			continue
 		}
+		projects = append(projects, project)
+	}
 
-		projectPath := filepath.Join(s.rootPath, entry.Name())
-		
-		if s.isProjectDirectory(projectPath) {
-			logger.Debug("Analyzing project: %s", projectPath)
-			project, err := s.analyzeProject(projectPath, entry.Name())
-			if err != nil {
-				logger.Warn("Failed to analyze project %s: %v", entry.Name(), err)
-				continue
-			}
-			projects = append(projects, project)
+	return projects, nil
+}
+
+// DiscoverProjects walks the root directory and returns the path of every
+// project directory found within the configured depth. The walk honors the
+// ignore rules of the root and does not descend into a directory once it has
+// been recognized as a project.
+func (s *Scanner) DiscoverProjects() ([]string, error) {
+	root := filepath.Clean(s.rootPath)
+	if _, err := os.ReadDir(root); err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var paths []string
+	err := ignore.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Debug("Skipping %s: %v", path, err)
+			return nil
+		}
+		if !info.IsDir() || path == root {
+			return nil
 		}
+		if skippedDirs[info.Name()] {
+			return filepath.SkipDir
+		}
+
+		if s.isProjectDirectory(path) {
+			paths = append(paths, path)
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return filepath.SkipDir
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		if s.maxDepth > 0 && depth >= s.maxDepth {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
-	return projects, nil
+	return paths, nil
 }
 
 func (s *Scanner) isProjectDirectory(path string) bool {
@@ -69,7 +138,7 @@ func (s *Scanner) isProjectDirectory(path string) bool {
 		}
 	}
 
-	return false
+	return detect.Matches(path)
 }
 
 func (s *Scanner) analyzeProject(projectPath, projectName string) (models.Project, error) {
@@ -101,6 +170,11 @@ func (s *Scanner) analyzeProject(projectPath, projectName string) (models.Projec
 		GitBranch:     branch,
 	}
 
+	if isGit {
+		project.RootCommit = s.rootCommit(projectPath)
+		project.LastCommitAt = lastCommitTime(projectPath)
+	}
+
 	return project, nil
 }
 
@@ -128,176 +202,39 @@ func (s *Scanner) extractDescription(projectPath string) string {
 	return ""
 }
 
-func (s *Scanner) getGitInfo(projectPath string) (bool, string, string) {
-	gitDir := filepath.Join(projectPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		return false, "", ""
-	}
+// maxReadmeSize caps how much of a README is kept for search.
+const maxReadmeSize = 64 * 1024
 
-	configPath := filepath.Join(gitDir, "config")
-	data, err := os.ReadFile(configPath)
+// readReadme returns the README of a project, cut at maxReadmeSize, or ""
+// when it has none.
+func readReadme(projectPath string) string {
+	f, err := os.Open(filepath.Join(projectPath, "README.md"))
 	if err != nil {
-		return true, "", ""
+		return ""
 	}
+	defer f.Close()
 
-	remote := ""
-	content := string(data)
-	for _, line := range strings.Split(content, "\n") {
-		if strings.Contains(line, "url =") {
-			parts := strings.Split(line, "=")
-			if len(parts) == 2 {
-				remote = strings.TrimSpace(parts[1])
-				break
-			}
-		}
+	data, err := io.ReadAll(io.LimitReader(f, maxReadmeSize))
+	if err != nil {
+		return ""
 	}
+	return strings.ToValidUTF8(string(data), "")
+}
 
-	headPath := filepath.Join(gitDir, "HEAD")
-	headData, err := os.ReadFile(headPath)
-	branch := ""
-	if err == nil {
-		headContent := strings.TrimSpace(string(headData))
-		if strings.HasPrefix(headContent, "ref: refs/heads/") {
-			branch = strings.TrimPrefix(headContent, "ref: refs/heads/")
-		}
+// getGitInfo reports whether a project is a git repository, with the URL
+// of its remote and the branch checked out.
+func (s *Scanner) getGitInfo(projectPath string) (bool, string, string) {
+	repo, err := git.Open(projectPath)
+	if err != nil {
+		return false, "", ""
 	}
-
-	return true, remote, branch
+	return true, repo.Remote(), repo.Branch()
 }
 
+// DetectTechnologies returns the technologies found by the registered
+// detectors in the project root.
 func (s *Scanner) DetectTechnologies(projectPath string) ([]models.Technology, error) {
-	var techs []models.Technology
-
-	// Node.js detection
-	packageJSON := filepath.Join(projectPath, "package.json")
-	if data, err := os.ReadFile(packageJSON); err == nil {
-		var pkg struct {
-			Dependencies    map[string]string `json:"dependencies"`
-			DevDependencies map[string]string `json:"devDependencies"`
-		}
-		if json.Unmarshal(data, &pkg) == nil {
-			techs = append(techs, models.Technology{
-				Type:         "runtime",
-				Name:         "Node.js",
-				DetectedFrom: "package.json",
-			})
-
-			// Detectar frameworks populares
-			frameworks := map[string]string{
-				"next":         "framework",
-				"react":        "framework",
-				"vue":          "framework",
-				"@angular/core": "framework",
-				"express":      "framework",
-				"nestjs":       "framework",
-				"svelte":       "framework",
-			}
-
-			// Priorizar frameworks
-			for dep, ver := range pkg.Dependencies {
-				if fType, isFramework := frameworks[dep]; isFramework {
-					techs = append(techs, models.Technology{
-						Type:         fType,
-						Name:         dep,
-						Version:      strings.Trim(ver, "^~"),
-						DetectedFrom: "package.json",
-					})
-				}
-			}
-
-			// Limitar dependencias regulares a las 10 más importantes
-			count := 0
-			for dep, ver := range pkg.Dependencies {
-				if _, isFramework := frameworks[dep]; !isFramework && count < 10 {
-					techs = append(techs, models.Technology{
-						Type:         "dependency",
-						Name:         dep,
-						Version:      strings.Trim(ver, "^~"),
-						DetectedFrom: "package.json",
-					})
-					count++
-				}
-			}
-		}
-	}
-
-	requirementsTxt := filepath.Join(projectPath, "requirements.txt")
-	if data, err := os.ReadFile(requirementsTxt); err == nil {
-		techs = append(techs, models.Technology{
-			Type:         "runtime",
-			Name:         "Python",
-			DetectedFrom: "requirements.txt",
-		})
-
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.Split(line, "==")
-			name := strings.TrimSpace(parts[0])
-			version := ""
-			if len(parts) == 2 {
-				version = strings.TrimSpace(parts[1])
-			}
-			techs = append(techs, models.Technology{
-				Type:         "dependency",
-				Name:         name,
-				Version:      version,
-				DetectedFrom: "requirements.txt",
-			})
-		}
-	}
-
-	// Go detection
-	goMod := filepath.Join(projectPath, "go.mod")
-	if data, err := os.ReadFile(goMod); err == nil {
-		techs = append(techs, models.Technology{
-			Type:         "runtime",
-			Name:         "Go",
-			DetectedFrom: "go.mod",
-		})
-
-		// Detectar versión de Go
-		content := string(data)
-		lines := strings.Split(content, "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(strings.TrimSpace(line), "go ") {
-				version := strings.TrimPrefix(strings.TrimSpace(line), "go ")
-				techs[len(techs)-1].Version = version
-				break
-			}
-		}
-	}
-
-	// Rust detection
-	cargoToml := filepath.Join(projectPath, "Cargo.toml")
-	if data, err := os.ReadFile(cargoToml); err == nil {
-		techs = append(techs, models.Technology{
-			Type:         "runtime",
-			Name:         "Rust",
-			DetectedFrom: "Cargo.toml",
-		})
-
-		// Buscar edición de Rust en el Cargo.toml
-		content := string(data)
-		if strings.Contains(content, "edition") {
-			lines := strings.Split(content, "\n")
-			for _, line := range lines {
-				if strings.Contains(line, "edition") && strings.Contains(line, "=") {
-					parts := strings.Split(line, "=")
-					if len(parts) == 2 {
-						edition := strings.Trim(strings.TrimSpace(parts[1]), `"`)
-						techs[len(techs)-1].Version = edition
-						break
-					}
-				}
-			}
-		}
-	}
-
-	return techs, nil
+	return detect.Detect(projectPath)
 }
 
 func generateProjectID(path string) string {
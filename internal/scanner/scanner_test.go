@@ -1,8 +1,12 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/snowarch/project-memory/internal/models"
@@ -34,11 +38,31 @@ func TestIsProjectDirectory(t *testing.T) {
 			files:    []string{"Cargo.toml"},
 			expected: true,
 		},
+		{
+			name:     ".NET solution",
+			files:    []string{"App.sln"},
+			expected: true,
+		},
+		{
+			name:     "Elixir project",
+			files:    []string{"mix.exs"},
+			expected: true,
+		},
+		{
+			name:     "Zig project",
+			files:    []string{"build.zig"},
+			expected: true,
+		},
 		{
 			name:     "Git repository",
 			files:    []string{".git"},
 			expected: true,
 		},
+		{
+			name:     "Deployment files only",
+			files:    []string{"docker-compose.yml", "Dockerfile", "main.tf"},
+			expected: false,
+		},
 		{
 			name:     "Not a project",
 			files:    []string{"random.txt"},
@@ -75,6 +99,92 @@ func TestIsProjectDirectory(t *testing.T) {
 	}
 }
 
+func TestScanProjects_Nested(t *testing.T) {
+	root := t.TempDir()
+
+	dirs := map[string]string{
+		"top":                            "go.mod",
+		"acme":                           "docker-compose.yml",
+		"acme/api":                       "package.json",
+		"acme/web/node_modules/left-pad": "package.json",
+		"acme/tools/deep/cli":            "Cargo.toml",
+		"top/nested":                     "requirements.txt",
+		"vendor/lib":                     "go.mod",
+	}
+	for dir, file := range dirs {
+		path := filepath.Join(root, dir)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(path, file), []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create %s/%s: %v", dir, file, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		expected []string
+	}{
+		{
+			name:     "immediate children only",
+			maxDepth: 1,
+			expected: []string{"top"},
+		},
+		{
+			name:     "org/repo layout",
+			maxDepth: 2,
+			expected: []string{"acme/api", "top"},
+		},
+		{
+			name:     "unlimited depth",
+			maxDepth: 0,
+			expected: []string{"acme/api", "acme/tools/deep/cli", "top"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(root)
+			s.SetMaxDepth(tt.maxDepth)
+
+			paths, err := s.DiscoverProjects()
+			if err != nil {
+				t.Fatalf("DiscoverProjects() failed: %v", err)
+			}
+
+			var got []string
+			for _, p := range paths {
+				rel, _ := filepath.Rel(root, p)
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("DiscoverProjects() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiscoverProjects_RelativeRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "ws", "api"), 0755); err != nil {
+		t.Fatalf("Failed to create ws/api: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "ws", "api", "go.mod"), []byte("module api\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+	t.Chdir(root)
+
+	paths, err := New("./ws").DiscoverProjects()
+	if err != nil {
+		t.Fatalf("DiscoverProjects() failed: %v", err)
+	}
+	if len(paths) != 1 || !filepath.IsAbs(paths[0]) || filepath.Base(paths[0]) != "api" {
+		t.Errorf("DiscoverProjects() = %v, want the absolute path of ws/api", paths)
+	}
+}
+
 func TestDetectTechnologies_NodeJS(t *testing.T) {
 	tmpDir := t.TempDir()
 	
@@ -320,3 +430,398 @@ func TestAnalyzeProject(t *testing.T) {
 		t.Error("Project ID should not be empty")
 	}
 }
+
+func TestAnalyzeConcurrent(t *testing.T) {
+	root := t.TempDir()
+
+	var paths []string
+	for _, name := range []string{"alpha", "beta", "gamma", "delta", "epsilon"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+name+"\n\ngo 1.22\n"), 0644); err != nil {
+			t.Fatalf("Failed to create go.mod: %v", err)
+		}
+		paths = append(paths, dir)
+	}
+
+	s := New(root)
+
+	seen := make(map[string]bool)
+	err := s.AnalyzeConcurrent(context.Background(), paths, 3, func(res Result) error {
+		if res.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", res.Path, res.Err)
+		}
+		if res.Project.Path != res.Path {
+			t.Errorf("Project path = %s, want %s", res.Project.Path, res.Path)
+		}
+		if len(res.Technologies) == 0 || res.Technologies[0].Name != "Go" {
+			t.Errorf("Technologies for %s = %v, want Go runtime", res.Path, res.Technologies)
+		}
+		seen[res.Path] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeConcurrent() failed: %v", err)
+	}
+
+	if len(seen) != len(paths) {
+		t.Errorf("AnalyzeConcurrent() handled %d projects, want %d", len(seen), len(paths))
+	}
+
+	// Cancelling stops the scan and reports the context error.
+	ctx, cancel := context.WithCancel(context.Background())
+	handled := 0
+	err = s.AnalyzeConcurrent(ctx, paths, 2, func(res Result) error {
+		handled++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("AnalyzeConcurrent() after cancel = %v, want context.Canceled", err)
+	}
+	if handled != 1 {
+		t.Errorf("AnalyzeConcurrent() handled %d results after cancel, want 1", handled)
+	}
+}
+
+// TestAnalyzeConcurrent_Unchanged checks that TODOs and language stats are
+// read again when a project's fingerprint did not change, since editing
+// source files does not change it.
+func TestAnalyzeConcurrent_Unchanged(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+	source := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(source, []byte("package main\n\n// TODO: handle errors\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to create main.go: %v", err)
+	}
+
+	analyze := func(s *Scanner) Result {
+		var result Result
+		if err := s.AnalyzeConcurrent(context.Background(), []string{dir}, 1, func(res Result) error {
+			result = res
+			return nil
+		}); err != nil {
+			t.Fatalf("AnalyzeConcurrent() failed: %v", err)
+		}
+		return result
+	}
+
+	s := New(dir)
+	first := analyze(s)
+	if first.Unchanged || len(first.Todos) != 1 {
+		t.Fatalf("first scan: Unchanged = %v, Todos = %v; want a changed project with one TODO", first.Unchanged, first.Todos)
+	}
+
+	if err := os.WriteFile(source, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit main.go: %v", err)
+	}
+	s.SetPreviousFingerprints(map[string][]models.ProjectFile{dir: first.Files})
+	second := analyze(s)
+	if second.Err != nil || !second.Unchanged || !second.SourcesChanged {
+		t.Fatalf("second scan: Unchanged = %v, SourcesChanged = %v, Err = %v; want an unchanged project with changed sources",
+			second.Unchanged, second.SourcesChanged, second.Err)
+	}
+	if len(second.Todos) != 0 {
+		t.Errorf("second scan: Todos = %v, want none after the comment was removed", second.Todos)
+	}
+	if len(second.Languages) == 0 {
+		t.Error("second scan: no language stats")
+	}
+
+	// Untouched sources are not read again.
+	s.SetPreviousFingerprints(map[string][]models.ProjectFile{dir: second.Files})
+	third := analyze(s)
+	if !third.Unchanged || third.SourcesChanged || third.Languages != nil {
+		t.Errorf("third scan: Unchanged = %v, SourcesChanged = %v, Languages = %v; want untouched sources left alone",
+			third.Unchanged, third.SourcesChanged, third.Languages)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tmpDir := t.TempDir()
+	goMod := filepath.Join(tmpDir, "go.mod")
+	if err := os.WriteFile(goMod, []byte("module example\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git", "refs", "heads"), 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatalf("Failed to create HEAD: %v", err)
+	}
+	mainRef := filepath.Join(tmpDir, ".git", "refs", "heads", "main")
+	if err := os.WriteFile(mainRef, []byte("1111111111111111111111111111111111111111\n"), 0644); err != nil {
+		t.Fatalf("Failed to create ref: %v", err)
+	}
+
+	first := Fingerprint(tmpDir, nil)
+	if len(first) != 2 {
+		t.Fatalf("Fingerprint() returned %d files, want 2 (go.mod and HEAD)", len(first))
+	}
+
+	second := Fingerprint(tmpDir, first)
+	if FingerprintChanged(first, second) {
+		t.Error("FingerprintChanged() = true for an untouched project")
+	}
+
+	// A new commit changes the fingerprint.
+	if err := os.WriteFile(mainRef, []byte("2222222222222222222222222222222222222222\n"), 0644); err != nil {
+		t.Fatalf("Failed to update ref: %v", err)
+	}
+	afterCommit := Fingerprint(tmpDir, first)
+	if !FingerprintChanged(first, afterCommit) {
+		t.Error("FingerprintChanged() = false after HEAD moved")
+	}
+
+	// Editing a manifest changes the fingerprint.
+	if err := os.WriteFile(goMod, []byte("module example\n\ngo 1.22.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to update go.mod: %v", err)
+	}
+	afterEdit := Fingerprint(tmpDir, afterCommit)
+	if !FingerprintChanged(afterCommit, afterEdit) {
+		t.Error("FingerprintChanged() = false after go.mod changed")
+	}
+
+	// Without a previous fingerprint a project always counts as changed.
+	if !FingerprintChanged(nil, afterEdit) {
+		t.Error("FingerprintChanged(nil, ...) = false, want true")
+	}
+}
+
+func TestRootCommit_Reuse(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet", "-b", "main")
+	git("commit", "--quiet", "--allow-empty", "-m", "first")
+	previous := Fingerprint(dir, nil)
+	git("commit", "--quiet", "--allow-empty", "-m", "second")
+
+	// The recorded root is kept while HEAD moves forward; a made up one
+	// shows it was not read again.
+	s := New(dir)
+	s.SetPreviousFingerprints(map[string][]models.ProjectFile{dir: previous})
+	s.SetPreviousRootCommits(map[string]string{dir: "recorded"})
+	if got := s.rootCommit(dir); got != "recorded" {
+		t.Errorf("rootCommit() = %q after a new commit, want the recorded root", got)
+	}
+
+	// On an unrelated history the previous HEAD is no ancestor.
+	git("checkout", "--quiet", "--orphan", "fresh")
+	git("commit", "--quiet", "--allow-empty", "-m", "new root")
+	if got, want := s.rootCommit(dir), git("rev-parse", "HEAD"); got != want {
+		t.Errorf("rootCommit() = %q on an unrelated history, want %q", got, want)
+	}
+}
+
+func TestRelocator(t *testing.T) {
+	missing := []models.Project{
+		{ID: "marked", Name: "marked", Path: "/old/marked"},
+		{ID: "remote", Name: "remote", Path: "/old/remote", GitRemote: "git@github.com:me/app.git"},
+		{ID: "root", Name: "root", Path: "/old/root", RootCommit: "abc123"},
+		{ID: "named", Name: "tool", Path: "/old/tool"},
+		{ID: "dup1", Name: "dup", Path: "/a/dup"},
+		{ID: "dup2", Name: "dup", Path: "/b/dup"},
+		{ID: "other", Name: "lib", Path: "/old/lib", GitRemote: "https://github.com/me/lib"},
+		{ID: "upstream", Name: "upstream", Path: "/old/upstream", RootCommit: "def456", GitRemote: "https://github.com/org/upstream"},
+	}
+
+	tests := []struct {
+		name       string
+		res        Result
+		matchNames bool
+		expected   string
+	}{
+		{name: "marker file", res: Result{Path: "/new/x", MarkerID: "marked"}, expected: "marked"},
+		{name: "https remote matches ssh", res: Result{Path: "/new/y", Project: models.Project{GitRemote: "https://github.com/Me/app"}}, expected: "remote"},
+		{name: "root commit", res: Result{Path: "/new/z", Project: models.Project{RootCommit: "abc123"}}, expected: "root"},
+		{name: "root commit with different remote", res: Result{Path: "/new/fork", Project: models.Project{RootCommit: "def456", GitRemote: "https://github.com/me/fork"}}, expected: ""},
+		{name: "directory name without MatchNames", res: Result{Path: "/new/tool"}, expected: ""},
+		{name: "unique directory name", res: Result{Path: "/new/tool"}, matchNames: true, expected: "named"},
+		{name: "ambiguous directory name", res: Result{Path: "/new/dup"}, matchNames: true, expected: ""},
+		{name: "name with different remote", res: Result{Path: "/new/lib", Project: models.Project{GitRemote: "https://github.com/you/lib"}}, matchNames: true, expected: ""},
+		{name: "no match", res: Result{Path: "/new/unrelated"}, matchNames: true, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRelocator(append([]models.Project(nil), missing...))
+			r.MatchNames = tt.matchNames
+			got := ""
+			if p := r.Match(tt.res); p != nil {
+				got = p.ID
+			}
+			if got != tt.expected {
+				t.Errorf("Match() = %q, want %q", got, tt.expected)
+			}
+			if got != "" && len(r.Remaining()) != len(missing)-1 {
+				t.Errorf("Remaining() has %d projects, want %d", len(r.Remaining()), len(missing)-1)
+			}
+		})
+	}
+}
+
+func TestDetectWorkspace(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		kind     string
+		expected []string
+	}{
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"package.json":                 `{"name": "root"}`,
+				"pnpm-workspace.yaml":          "packages:\n  - 'packages/*'\n  - \"apps/**\"\n  - '!packages/legacy'\n",
+				"packages/ui/package.json":     "{}",
+				"packages/legacy/package.json": "{}",
+				"apps/web/site/package.json":   "{}",
+				"apps/readme.txt":              "",
+			},
+			kind:     WorkspacePnpm,
+			expected: []string{"apps/web/site", "packages/ui"},
+		},
+		{
+			name: "yarn object form",
+			files: map[string]string{
+				"package.json":          `{"workspaces": {"packages": ["libs/*"]}}`,
+				"yarn.lock":             "",
+				"libs/a/package.json":   "{}",
+				"libs/b/no-manifest.md": "",
+			},
+			kind:     WorkspaceYarn,
+			expected: []string{"libs/a"},
+		},
+		{
+			name: "npm array form",
+			files: map[string]string{
+				"package.json":       `{"workspaces": ["pkg/*"]}`,
+				"pkg/x/package.json": "{}",
+			},
+			kind:     WorkspaceNpm,
+			expected: []string{"pkg/x"},
+		},
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work":      "go 1.22\n\nuse (\n\t./cmd // tools\n\t./lib\n)\nuse ./extra\n",
+				"cmd/go.mod":   "module cmd\n",
+				"lib/go.mod":   "module lib\n",
+				"extra/go.mod": "module extra\n",
+			},
+			kind:     WorkspaceGo,
+			expected: []string{"cmd", "extra", "lib"},
+		},
+		{
+			name: "cargo",
+			files: map[string]string{
+				"Cargo.toml":             "[workspace]\nmembers = [\n  \"crates/*\", # all crates\n]\nexclude = [\"crates/old\"]\n\n[workspace.dependencies]\nserde = \"1\"\n",
+				"crates/core/Cargo.toml": "[package]\nname = \"core\"\n",
+				"crates/old/Cargo.toml":  "[package]\nname = \"old\"\n",
+			},
+			kind:     WorkspaceCargo,
+			expected: []string{"crates/core"},
+		},
+		{
+			name:  "plain project",
+			files: map[string]string{"package.json": `{"name": "app"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create dir for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			ws := DetectWorkspace(root)
+			if tt.kind == "" {
+				if ws != nil {
+					t.Fatalf("DetectWorkspace() = %+v, want nil", ws)
+				}
+				return
+			}
+			if ws == nil {
+				t.Fatalf("DetectWorkspace() = nil, want %s workspace", tt.kind)
+			}
+			if ws.Kind != tt.kind {
+				t.Errorf("Kind = %s, want %s", ws.Kind, tt.kind)
+			}
+
+			var got []string
+			for _, m := range ws.Members {
+				rel, _ := filepath.Rel(root, m)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Members = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeConcurrent_WorkspaceMembers(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "mono")
+	for name, content := range map[string]string{
+		"go.work":    "go 1.22\n\nuse ./api\n",
+		"api/go.mod": "module api\n\ngo 1.22\n",
+		"README.md":  "# Mono\n",
+	} {
+		path := filepath.Join(repo, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	s := New(root)
+	var results []Result
+	err := s.AnalyzeConcurrent(context.Background(), []string{repo}, 1, func(res Result) error {
+		results = append(results, res)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeConcurrent() failed: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Members) != 1 {
+		t.Fatalf("AnalyzeConcurrent() = %+v, want one root with one member", results)
+	}
+
+	member := results[0].Members[0]
+	if member.Project.Name != "mono/api" {
+		t.Errorf("Member name = %s, want mono/api", member.Project.Name)
+	}
+
+	hasWorkspace := false
+	for _, tech := range results[0].Technologies {
+		if tech.Type == "workspace" && tech.Name == WorkspaceGo {
+			hasWorkspace = true
+		}
+	}
+	if !hasWorkspace {
+		t.Errorf("Root technologies = %v, want go workspace entry", results[0].Technologies)
+	}
+}
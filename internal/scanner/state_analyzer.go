@@ -1,11 +1,14 @@
 package scanner
 
 import (
+	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/snowarch/project-memory/internal/git"
+	"github.com/snowarch/project-memory/internal/ignore"
 )
 
 // ProjectStateAnalyzer provides intelligent project state detection
@@ -68,20 +71,11 @@ func (psa *ProjectStateAnalyzer) getRecentFileActivity() ([]string, time.Duratio
 	
 	cutoff := time.Now().AddDate(0, 0, -7) // Last 7 days
 	
-	err := filepath.Walk(psa.projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(psa.projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 		
-		// Skip hidden files and common directories
-		if strings.HasPrefix(info.Name(), ".") || 
-		   strings.Contains(path, "node_modules") ||
-		   strings.Contains(path, ".git") ||
-		   strings.Contains(path, "target") ||
-		   strings.Contains(path, "build") {
-			return nil
-		}
-		
 		if info.ModTime().After(cutoff) {
 			recentFiles = append(recentFiles, path)
 			totalAge += time.Since(info.ModTime())
@@ -103,26 +97,21 @@ func (psa *ProjectStateAnalyzer) analyzeGitActivity() ([]string, int) {
 	progressBoost := 0
 	
 	// Check if it's a git repo
-	gitDir := filepath.Join(psa.projectPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+	repo, err := git.Open(psa.projectPath)
+	if err != nil {
 		return insights, 0
 	}
+	defer repo.Close()
 	
 	// Get recent commits
-	cmd := exec.Command("git", "-C", psa.projectPath, "log", "--since=2.weeks", "--oneline")
-	output, err := cmd.Output()
-	if err == nil {
-		commits := strings.Split(strings.TrimSpace(string(output)), "\n")
-		if len(commits) > 0 && commits[0] != "" {
-			progressBoost = minInt(30, len(commits)*5)
-			insights = append(insights, "Recent git activity: "+string(rune(len(commits)))+" commits in last 2 weeks")
-		}
+	commits, err := repo.Log(git.LogOptions{Since: time.Now().AddDate(0, 0, -14)})
+	if err == nil && len(commits) > 0 {
+		progressBoost = minInt(30, len(commits)*5)
+		insights = append(insights, fmt.Sprintf("Recent git activity: %d commits in last 2 weeks", len(commits)))
 	}
 	
 	// Check for uncommitted changes
-	cmd = exec.Command("git", "-C", psa.projectPath, "status", "--porcelain")
-	output, err = cmd.Output()
-	if err == nil && len(strings.TrimSpace(string(output))) > 0 {
+	if dirty, err := repo.HasChanges(); err == nil && dirty {
 		insights = append(insights, "Uncommitted changes detected")
 		progressBoost = minInt(100, progressBoost+10)
 	}
@@ -151,18 +140,11 @@ func (psa *ProjectStateAnalyzer) estimateTestCoverage() int {
 	testFiles := 0
 	sourceFiles := 0
 	
-	err := filepath.Walk(psa.projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(psa.projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
 		
-		// Skip vendor and dependencies
-		if strings.Contains(path, "node_modules") || 
-		   strings.Contains(path, "vendor") ||
-		   strings.Contains(path, ".git") {
-			return nil
-		}
-		
 		ext := strings.ToLower(filepath.Ext(path))
 		switch ext {
 		case ".go", ".js", ".ts", ".py", ".rs", ".java":
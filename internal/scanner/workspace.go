@@ -0,0 +1,322 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/ignore"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/toml"
+)
+
+// Workspace kinds reported by DetectWorkspace.
+const (
+	WorkspacePnpm  = "pnpm"
+	WorkspaceYarn  = "yarn"
+	WorkspaceNpm   = "npm"
+	WorkspaceGo    = "go"
+	WorkspaceCargo = "cargo"
+)
+
+// Workspace describes a workspace manifest at the root of a project and the
+// member directories it declares.
+type Workspace struct {
+	Kind     string
+	Manifest string
+	Members  []string
+}
+
+// Technology returns the record stored for the workspace itself among the
+// technologies of its root project.
+func (w *Workspace) Technology() models.Technology {
+	return models.Technology{
+		Name:         w.Kind,
+		Type:         "workspace",
+		DetectedFrom: w.Manifest,
+	}
+}
+
+// DetectWorkspace looks for a pnpm, yarn or npm workspace, a go.work file
+// or a Cargo [workspace] table in projectPath. It returns nil when the
+// directory is not a workspace root or declares no existing members.
+func DetectWorkspace(projectPath string) *Workspace {
+	detectors := []func(string) *Workspace{
+		detectPnpmWorkspace,
+		detectPackageJSONWorkspace,
+		detectGoWorkspace,
+		detectCargoWorkspace,
+	}
+	for _, detect := range detectors {
+		if ws := detect(projectPath); ws != nil && len(ws.Members) > 0 {
+			return ws
+		}
+	}
+	return nil
+}
+
+func detectPnpmWorkspace(projectPath string) *Workspace {
+	f, err := os.Open(filepath.Join(projectPath, "pnpm-workspace.yaml"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []string
+	inPackages := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := stripComment(sc.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(line, "packages:")
+			continue
+		}
+		if inPackages {
+			item := strings.TrimSpace(line)
+			if strings.HasPrefix(item, "-") {
+				patterns = append(patterns, unquote(strings.TrimSpace(item[1:])))
+			}
+		}
+	}
+
+	return &Workspace{
+		Kind:     WorkspacePnpm,
+		Manifest: "pnpm-workspace.yaml",
+		Members:  expandMembers(projectPath, patterns, "package.json"),
+	}
+}
+
+func detectPackageJSONWorkspace(projectPath string) *Workspace {
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || len(pkg.Workspaces) == 0 {
+		return nil
+	}
+
+	// Workspaces is either a list of globs or, with yarn, an object holding
+	// that list under "packages".
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+		var nested struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &nested); err != nil {
+			return nil
+		}
+		patterns = nested.Packages
+	}
+
+	kind := WorkspaceNpm
+	if _, err := os.Stat(filepath.Join(projectPath, "yarn.lock")); err == nil {
+		kind = WorkspaceYarn
+	}
+
+	return &Workspace{
+		Kind:     kind,
+		Manifest: "package.json",
+		Members:  expandMembers(projectPath, patterns, "package.json"),
+	}
+}
+
+func detectGoWorkspace(projectPath string) *Workspace {
+	f, err := os.Open(filepath.Join(projectPath, "go.work"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var dirs []string
+	inUse := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(stripComment(sc.Text(), "//"))
+		switch {
+		case inUse && line == ")":
+			inUse = false
+		case inUse && line != "":
+			dirs = append(dirs, unquote(line))
+		case line == "use (":
+			inUse = true
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, unquote(strings.TrimSpace(strings.TrimPrefix(line, "use "))))
+		}
+	}
+
+	return &Workspace{
+		Kind:     WorkspaceGo,
+		Manifest: "go.work",
+		Members:  expandMembers(projectPath, dirs, "go.mod"),
+	}
+}
+
+func detectCargoWorkspace(projectPath string) *Workspace {
+	data, err := os.ReadFile(filepath.Join(projectPath, "Cargo.toml"))
+	if err != nil {
+		return nil
+	}
+
+	doc, err := toml.Parse(data)
+	if err != nil {
+		return nil
+	}
+	ws := toml.Table(doc, "workspace")
+	if _, ok := ws["members"].([]interface{}); !ok {
+		return nil
+	}
+	members := toml.Strings(ws, "members")
+	for _, e := range toml.Strings(ws, "exclude") {
+		members = append(members, "!"+e)
+	}
+
+	return &Workspace{
+		Kind:     WorkspaceCargo,
+		Manifest: "Cargo.toml",
+		Members:  expandMembers(projectPath, members, "Cargo.toml"),
+	}
+}
+
+// expandMembers resolves workspace member patterns relative to root into
+// the directories that contain manifest. Patterns prefixed with "!" exclude
+// matching directories, and "**" matches any number of directories.
+func expandMembers(root string, patterns []string, manifest string) []string {
+	var include, exclude []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			exclude = append(exclude, cleanMemberPattern(p[1:]))
+		} else if p != "" {
+			include = append(include, cleanMemberPattern(p))
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, pattern := range include {
+		for _, dir := range globDirs(root, pattern) {
+			if _, err := os.Stat(filepath.Join(dir, manifest)); err != nil {
+				continue
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if !memberExcluded(filepath.ToSlash(rel), exclude) {
+				found[dir] = true
+			}
+		}
+	}
+
+	members := make([]string, 0, len(found))
+	for dir := range found {
+		members = append(members, dir)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func cleanMemberPattern(p string) string {
+	p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+	return strings.TrimSuffix(p, "/")
+}
+
+// globDirs returns the directories below root matching pattern. A "**"
+// element matches any depth, walking the tree with the ignore rules applied.
+func globDirs(root, pattern string) []string {
+	i := strings.Index(pattern, "**")
+	if i < 0 {
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		return matches
+	}
+
+	base := filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(pattern[:i], "/")))
+	var dirs []string
+	ignore.Walk(base, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			rel, _ := filepath.Rel(root, path)
+			if matchMemberPattern(pattern, filepath.ToSlash(rel)) {
+				dirs = append(dirs, path)
+			}
+		}
+		return nil
+	})
+	return dirs
+}
+
+func memberExcluded(rel string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matchMemberPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMemberPattern matches rel against a slash separated glob in which
+// "**" stands for zero or more whole directories.
+func matchMemberPattern(pattern, rel string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
+
+// stripComment removes a trailing comment that starts with marker outside
+// of a quoted string.
+func stripComment(line, marker string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(line[i:], marker):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package stats
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// language describes how comments are written in a language.
+type language struct {
+	name          string
+	lineComments  []string
+	blockComments [][2]string
+}
+
+var (
+	cStyle    = language{lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}}}
+	hashStyle = language{lineComments: []string{"#"}}
+	xmlStyle  = language{blockComments: [][2]string{{"<!--", "-->"}}}
+	noComment = language{}
+)
+
+func lang(name string, style language) *language {
+	style.name = name
+	return &style
+}
+
+// byExtension maps lower case file extensions to languages.
+var byExtension = map[string]*language{
+	".go":      lang("Go", cStyle),
+	".rs":      lang("Rust", cStyle),
+	".c":       lang("C", cStyle),
+	".h":       lang("C", cStyle),
+	".cc":      lang("C++", cStyle),
+	".cpp":     lang("C++", cStyle),
+	".cxx":     lang("C++", cStyle),
+	".hh":      lang("C++", cStyle),
+	".hpp":     lang("C++", cStyle),
+	".hxx":     lang("C++", cStyle),
+	".m":       lang("Objective-C", cStyle),
+	".mm":      lang("Objective-C++", cStyle),
+	".cs":      lang("C#", cStyle),
+	".fs":      lang("F#", language{lineComments: []string{"//"}, blockComments: [][2]string{{"(*", "*)"}}}),
+	".fsx":     lang("F#", language{lineComments: []string{"//"}, blockComments: [][2]string{{"(*", "*)"}}}),
+	".vb":      lang("Visual Basic", language{lineComments: []string{"'"}}),
+	".java":    lang("Java", cStyle),
+	".kt":      lang("Kotlin", cStyle),
+	".kts":     lang("Kotlin", cStyle),
+	".scala":   lang("Scala", cStyle),
+	".groovy":  lang("Groovy", cStyle),
+	".gradle":  lang("Groovy", cStyle),
+	".swift":   lang("Swift", cStyle),
+	".dart":    lang("Dart", cStyle),
+	".js":      lang("JavaScript", cStyle),
+	".mjs":     lang("JavaScript", cStyle),
+	".cjs":     lang("JavaScript", cStyle),
+	".jsx":     lang("JavaScript", cStyle),
+	".ts":      lang("TypeScript", cStyle),
+	".mts":     lang("TypeScript", cStyle),
+	".cts":     lang("TypeScript", cStyle),
+	".tsx":     lang("TypeScript", cStyle),
+	".vue":     lang("Vue", language{lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}, {"<!--", "-->"}}}),
+	".svelte":  lang("Svelte", language{lineComments: []string{"//"}, blockComments: [][2]string{{"/*", "*/"}, {"<!--", "-->"}}}),
+	".py":      lang("Python", language{lineComments: []string{"#"}, blockComments: [][2]string{{`"""`, `"""`}, {"'''", "'''"}}}),
+	".pyi":     lang("Python", language{lineComments: []string{"#"}, blockComments: [][2]string{{`"""`, `"""`}, {"'''", "'''"}}}),
+	".rb":      lang("Ruby", language{lineComments: []string{"#"}, blockComments: [][2]string{{"=begin", "=end"}}}),
+	".php":     lang("PHP", language{lineComments: []string{"//", "#"}, blockComments: [][2]string{{"/*", "*/"}}}),
+	".ex":      lang("Elixir", hashStyle),
+	".exs":     lang("Elixir", hashStyle),
+	".erl":     lang("Erlang", language{lineComments: []string{"%"}}),
+	".hs":      lang("Haskell", language{lineComments: []string{"--"}, blockComments: [][2]string{{"{-", "-}"}}}),
+	".ml":      lang("OCaml", language{blockComments: [][2]string{{"(*", "*)"}}}),
+	".clj":     lang("Clojure", language{lineComments: []string{";"}}),
+	".lua":     lang("Lua", language{lineComments: []string{"--"}, blockComments: [][2]string{{"--[[", "]]"}}}),
+	".pl":      lang("Perl", hashStyle),
+	".r":       lang("R", hashStyle),
+	".jl":      lang("Julia", language{lineComments: []string{"#"}, blockComments: [][2]string{{"#=", "=#"}}}),
+	".nim":     lang("Nim", hashStyle),
+	".zig":     lang("Zig", language{lineComments: []string{"//"}}),
+	".sol":     lang("Solidity", cStyle),
+	".sh":      lang("Shell", hashStyle),
+	".bash":    lang("Shell", hashStyle),
+	".zsh":     lang("Shell", hashStyle),
+	".fish":    lang("Shell", hashStyle),
+	".ps1":     lang("PowerShell", language{lineComments: []string{"#"}, blockComments: [][2]string{{"<#", "#>"}}}),
+	".sql":     lang("SQL", language{lineComments: []string{"--"}, blockComments: [][2]string{{"/*", "*/"}}}),
+	".html":    lang("HTML", xmlStyle),
+	".htm":     lang("HTML", xmlStyle),
+	".xml":     lang("XML", xmlStyle),
+	".css":     lang("CSS", language{blockComments: [][2]string{{"/*", "*/"}}}),
+	".scss":    lang("SCSS", cStyle),
+	".sass":    lang("Sass", cStyle),
+	".less":    lang("Less", cStyle),
+	".md":      lang("Markdown", xmlStyle),
+	".json":    lang("JSON", noComment),
+	".yaml":    lang("YAML", hashStyle),
+	".yml":     lang("YAML", hashStyle),
+	".toml":    lang("TOML", hashStyle),
+	".tf":      lang("HCL", language{lineComments: []string{"#", "//"}, blockComments: [][2]string{{"/*", "*/"}}}),
+	".hcl":     lang("HCL", language{lineComments: []string{"#", "//"}, blockComments: [][2]string{{"/*", "*/"}}}),
+	".proto":   lang("Protocol Buffers", cStyle),
+	".graphql": lang("GraphQL", hashStyle),
+	".cmake":   lang("CMake", hashStyle),
+	".mk":      lang("Makefile", hashStyle),
+}
+
+// byName maps file names without a telling extension to languages.
+var byName = map[string]*language{
+	"Makefile":       lang("Makefile", hashStyle),
+	"GNUmakefile":    lang("Makefile", hashStyle),
+	"CMakeLists.txt": lang("CMake", hashStyle),
+	"Dockerfile":     lang("Dockerfile", hashStyle),
+	"Containerfile":  lang("Dockerfile", hashStyle),
+	"Jenkinsfile":    lang("Groovy", cStyle),
+	"Gemfile":        lang("Ruby", hashStyle),
+	"Rakefile":       lang("Ruby", hashStyle),
+}
+
+// languageOf returns the language of a file from its name, or nil when it
+// is not source code this package knows.
+func languageOf(name string) *language {
+	if l, ok := byName[name]; ok {
+		return l
+	}
+	if strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile") {
+		return byName["Dockerfile"]
+	}
+	return byExtension[strings.ToLower(filepath.Ext(name))]
+}
+
+// CommentSyntax returns how comments are written in the language of the
+// named file: its line comment prefixes and block comment delimiters. ok is
+// false for files that are not source code this package knows.
+func CommentSyntax(name string) (lineComments []string, blockComments [][2]string, ok bool) {
+	l := languageOf(name)
+	if l == nil {
+		return nil, nil, false
+	}
+	return l.lineComments, l.blockComments, true
+}
@@ -0,0 +1,206 @@
+// Package stats breaks a project down by language, counting the files and
+// the code, comment and blank lines written in each.
+//
+// Languages are recognized from file names and extensions. Files excluded
+// by the project's ignore rules, binary files, minified files and lock files
+// are not counted. Comments are recognized at the start of a line and, for
+// block comments, where they open after code; markers inside string
+// literals are not told apart, so counts are close estimates.
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/ignore"
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+// maxFileSize bounds the files counted; larger ones are almost always
+// generated or data.
+const maxFileSize = 8 << 20
+
+// SniffSize is how much of a file is checked for NUL bytes to tell binary
+// files apart.
+const SniffSize = 8000
+
+// generatedFiles are lock files written by tools, whose extension would
+// otherwise count them as JSON or YAML.
+var generatedFiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"pnpm-lock.yaml":      true,
+	"packages.lock.json":  true,
+	"composer.lock":       true,
+	"Package.resolved":    true,
+	"pubspec.lock":        true,
+}
+
+// Count walks the project at root and returns its languages, the one with
+// the most code lines first. Directories in exclude are not walked.
+func Count(root string, exclude ...string) ([]models.LanguageStat, error) {
+	byLanguage := make(map[string]*models.LanguageStat)
+
+	err := ignore.WalkExcept(root, exclude, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !Countable(info) {
+			return nil
+		}
+		lang := languageOf(info.Name())
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		counts, ok := countFile(f, lang)
+		if !ok {
+			return nil
+		}
+		stat := byLanguage[lang.name]
+		if stat == nil {
+			stat = &models.LanguageStat{Language: lang.name}
+			byLanguage[lang.name] = stat
+		}
+		stat.Files++
+		stat.CodeLines += counts.CodeLines
+		stat.CommentLines += counts.CommentLines
+		stat.BlankLines += counts.BlankLines
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.LanguageStat, 0, len(byLanguage))
+	for _, stat := range byLanguage {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CodeLines != result[j].CodeLines {
+			return result[i].CodeLines > result[j].CodeLines
+		}
+		return result[i].Language < result[j].Language
+	})
+	return result, nil
+}
+
+// Countable reports whether a file is source code Count reads: a regular
+// file in a known language that is neither too large, a lock file nor
+// minified. Binary files are only told apart once read.
+func Countable(info os.FileInfo) bool {
+	name := info.Name()
+	if !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		return false
+	}
+	if generatedFiles[name] || strings.Contains(name, ".min.") {
+		return false
+	}
+	return languageOf(name) != nil
+}
+
+// IsBinary reports whether the start of a file holds a NUL byte, which
+// text files never do.
+func IsBinary(head []byte) bool {
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+// countFile counts the lines of a file written in lang. It reports false
+// for binary files.
+func countFile(r io.Reader, lang *language) (models.LanguageStat, bool) {
+	var counts models.LanguageStat
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(SniffSize); IsBinary(head) {
+		return counts, false
+	}
+
+	sc := bufio.NewScanner(br)
+	sc.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	closing := ""
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+			counts.BlankLines++
+		case closing != "":
+			// Inside a block comment; code may follow where it ends.
+			i := strings.Index(line, closing)
+			if i < 0 {
+				counts.CommentLines++
+				continue
+			}
+			rest := strings.TrimSpace(line[i+len(closing):])
+			closing = ""
+			if rest == "" || lang.startsComment(rest) {
+				counts.CommentLines++
+			} else {
+				counts.CodeLines++
+				closing = lang.unclosedBlock(rest)
+			}
+		case lang.startsComment(line):
+			counts.CommentLines++
+			closing = lang.unclosedBlock(line)
+		default:
+			counts.CodeLines++
+			closing = lang.unclosedBlock(line)
+		}
+	}
+	return counts, true
+}
+
+// startsComment reports whether line starts with a comment.
+func (l *language) startsComment(line string) bool {
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	for _, block := range l.blockComments {
+		if strings.HasPrefix(line, block[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// unclosedBlock returns the marker closing a block comment opened but not
+// closed on line, or "". Text after a line comment is ignored. Block
+// comments delimited by quotes, such as Python docstrings, only count at
+// the start of a line, since elsewhere they are strings.
+func (l *language) unclosedBlock(line string) string {
+	for len(line) > 0 {
+		start, block := -1, [2]string{}
+		for _, b := range l.blockComments {
+			i := strings.Index(line, b[0])
+			if i < 0 || b[0] == b[1] && i > 0 {
+				continue
+			}
+			if start < 0 || i < start {
+				start, block = i, b
+			}
+		}
+		if start < 0 {
+			return ""
+		}
+		for _, prefix := range l.lineComments {
+			if i := strings.Index(line, prefix); i >= 0 && i < start && !strings.HasPrefix(block[0], prefix) {
+				return ""
+			}
+		}
+		line = line[start+len(block[0]):]
+		end := strings.Index(line, block[1])
+		if end < 0 {
+			return block[1]
+		}
+		line = line[end+len(block[1]):]
+		if block[0] == block[1] {
+			// A docstring closed on its own line.
+			return ""
+		}
+	}
+	return ""
+}
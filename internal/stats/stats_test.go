@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/snowarch/project-memory/internal/models"
+)
+
+func TestCountFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    models.LanguageStat
+	}{
+		{
+			name: "Go",
+			file: "main.go",
+			content: `// Package main does things.
+package main
+
+/*
+Block comment.
+*/
+func main() { /* inline */
+	x := 1 // trailing
+	/* one line */
+	_ = x /* opens
+	closes */ println()
+}
+`,
+			want: models.LanguageStat{CodeLines: 6, CommentLines: 5, BlankLines: 1},
+		},
+		{
+			name: "Python docstrings",
+			file: "app.py",
+			content: `"""Module docstring
+spanning lines."""
+import os
+
+def f():
+    """One line."""
+    s = """not a
+    comment"""
+    # comment
+    return s
+`,
+			want: models.LanguageStat{CodeLines: 5, CommentLines: 4, BlankLines: 1},
+		},
+		{
+			name: "Lua block after line comment marker",
+			file: "init.lua",
+			content: `--[[ block
+still block ]]
+-- line
+local x = 1
+`,
+			want: models.LanguageStat{CodeLines: 1, CommentLines: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := countFile(strings.NewReader(tt.content), languageOf(tt.file))
+			if !ok {
+				t.Fatalf("countFile() reported a binary file")
+			}
+			if got != tt.want {
+				t.Errorf("countFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":                 "package main\n\nfunc main() {}\n",
+		"internal/util/util.go":   "// Package util.\npackage util\n",
+		"scripts/build.sh":        "#!/bin/sh\necho build\n",
+		"Makefile":                "build:\n\tgo build\n",
+		"vendor/lib/lib.go":       "package lib\n",
+		"node_modules/x/index.js": "module.exports = 1\n",
+		"generated/api.go":        "package generated\n",
+		".gitignore":              "generated/\n",
+		"web/app.min.js":          "var a=1;\n",
+		"web/package-lock.json":   "{}\n",
+		"assets/logo.go":          "package\x00binary\n",
+		"notes.txt":               "plain text\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := Count(root)
+	if err != nil {
+		t.Fatalf("Count() failed: %v", err)
+	}
+
+	want := []models.LanguageStat{
+		{Language: "Go", Files: 2, CodeLines: 3, CommentLines: 1, BlankLines: 1},
+		{Language: "Makefile", Files: 1, CodeLines: 2},
+		{Language: "Shell", Files: 1, CodeLines: 1, CommentLines: 1},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("Count() = %+v, want %+v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package todo extracts TODO, FIXME, HACK and XXX comments from the source
+// files of a project.
+//
+// A marker counts when it starts the text of a comment, as in
+// "// TODO: retry" or " * FIXME(high) leaks on close", so words such as
+// "todo" in code or prose are not picked up. Comments are recognized with
+// the syntax of each language known to the stats package.
+package todo
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/snowarch/project-memory/internal/ignore"
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/stats"
+)
+
+// MaxPerProject bounds the items extracted from a project, so generated or
+// vendored code that escaped the ignore rules cannot flood the database.
+const MaxPerProject = 2000
+
+// Priorities given to items, as stored in the todos table.
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// markerPattern matches a marker at the start of a comment, with an
+// optional tag in parentheses, optional exclamation marks and the text.
+var markerPattern = regexp.MustCompile(`^(TODO|FIXME|HACK|XXX)\b(?:\(([^)]*)\))?(!*)\s*[:\-]?\s*(.*)$`)
+
+// urgentWords raise the priority of an item mentioning them.
+var urgentWords = []string{"urgent", "asap", "critical", "security", "crash"}
+
+// Extract walks the project at root and returns its TODO comments in file
+// and line order. SourceFile is relative to root, with forward slashes.
+// Directories in exclude are not walked.
+func Extract(root string, exclude ...string) ([]models.Todo, error) {
+	var todos []models.Todo
+
+	err := ignore.WalkExcept(root, exclude, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !stats.Countable(info) || len(todos) >= MaxPerProject {
+			return nil
+		}
+		lineComments, blockComments, _ := stats.CommentSyntax(info.Name())
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		found := extractFile(f, lineComments, blockComments)
+		for i := range found {
+			found[i].SourceFile = filepath.ToSlash(rel)
+		}
+		todos = append(todos, found[:min(len(found), MaxPerProject-len(todos))]...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// extractFile returns the items found in the comments of a file. Binary
+// files have none.
+func extractFile(r io.Reader, lineComments []string, blockComments [][2]string) []models.Todo {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(stats.SniffSize); stats.IsBinary(head) {
+		return nil
+	}
+
+	c := &commentReader{lineComments: lineComments, blockComments: blockComments}
+	var todos []models.Todo
+	sc := bufio.NewScanner(br)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for number := 1; sc.Scan(); number++ {
+		for _, text := range c.comments(strings.TrimSpace(sc.Text())) {
+			if todo, ok := parseComment(text); ok {
+				todo.LineNumber = number
+				todos = append(todos, todo)
+			}
+		}
+	}
+	return todos
+}
+
+// parseComment reads the item a comment starts with. Decoration such as the
+// asterisks of block comments or doubled comment characters is skipped.
+func parseComment(text string) (models.Todo, bool) {
+	text = strings.TrimLeft(text, " \t*/#!-;%'")
+	m := markerPattern.FindStringSubmatch(text)
+	if m == nil {
+		return models.Todo{}, false
+	}
+
+	kind, tag, bangs := m[1], strings.ToLower(strings.TrimSpace(m[2])), m[3]
+	content := strings.TrimSpace(m[4])
+	// A block comment closed on the same line leaves its closer behind.
+	for _, closer := range []string{"*/", "-->", "*)", "-}", "#>"} {
+		content = strings.TrimSpace(strings.TrimSuffix(content, closer))
+	}
+
+	return models.Todo{
+		Kind:     kind,
+		Content:  content,
+		Priority: inferPriority(kind, tag, bangs != "", content),
+	}, true
+}
+
+// inferPriority ranks an item. A priority written as its tag, as in
+// TODO(high) or TODO(p1), wins; otherwise exclamation marks and urgent
+// words make it high, FIXME and XXX are high, TODO medium and HACK low.
+func inferPriority(kind, tag string, exclaimed bool, content string) string {
+	switch tag {
+	case "high", "p0", "p1", "urgent", "critical":
+		return PriorityHigh
+	case "medium", "p2":
+		return PriorityMedium
+	case "low", "p3", "p4", "minor":
+		return PriorityLow
+	}
+
+	if exclaimed {
+		return PriorityHigh
+	}
+	lower := strings.ToLower(content)
+	for _, word := range urgentWords {
+		if strings.Contains(lower, word) {
+			return PriorityHigh
+		}
+	}
+
+	switch kind {
+	case "FIXME", "XXX":
+		return PriorityHigh
+	case "HACK":
+		return PriorityLow
+	}
+	return PriorityMedium
+}
+
+// commentReader returns the comment text of successive lines, following
+// block comments across lines.
+type commentReader struct {
+	lineComments  []string
+	blockComments [][2]string
+	closing       string
+}
+
+func (c *commentReader) comments(line string) []string {
+	var texts []string
+	for {
+		if c.closing != "" {
+			end := strings.Index(line, c.closing)
+			if end < 0 {
+				return append(texts, line)
+			}
+			texts = append(texts, line[:end])
+			line = line[end+len(c.closing):]
+			c.closing = ""
+			continue
+		}
+
+		start, block := -1, [2]string{}
+		for _, b := range c.blockComments {
+			var i int
+			if b[0] == b[1] {
+				// Blocks delimited by quotes, such as Python docstrings,
+				// are strings anywhere but at the start of a line.
+				if i = strings.Index(line, b[0]); i > 0 {
+					continue
+				}
+			} else {
+				i = indexOutsideString(line, b[0])
+			}
+			if i < 0 {
+				continue
+			}
+			if start < 0 || i < start {
+				start, block = i, b
+			}
+		}
+		lineStart, prefix := -1, ""
+		for _, p := range c.lineComments {
+			if i := indexOutsideString(line, p); i >= 0 && (lineStart < 0 || i < lineStart) {
+				lineStart, prefix = i, p
+			}
+		}
+		// A line comment wins unless a block opens first, or at the same
+		// place with a longer opener such as Lua's "--[[".
+		if lineStart >= 0 && (start < 0 || lineStart < start || lineStart == start && !strings.HasPrefix(block[0], prefix)) {
+			return append(texts, line[lineStart+len(prefix):])
+		}
+		if start < 0 {
+			return texts
+		}
+		line = line[start+len(block[0]):]
+		c.closing = block[1]
+	}
+}
+
+// indexOutsideString returns the index of the first s in line that is not
+// inside a double-quoted string, or -1.
+func indexOutsideString(line, s string) int {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case quoted && line[i] == '\\':
+			i++
+		case line[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(line[i:], s):
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,152 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/snowarch/project-memory/internal/models"
+	"github.com/snowarch/project-memory/internal/stats"
+)
+
+func TestExtractFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []models.Todo
+	}{
+		{
+			name: "Go",
+			file: "main.go",
+			content: `package main
+
+// TODO: handle signals
+func main() {
+	todo := "TODO: not a comment"
+	_ = todo // FIXME leaks the file
+	/* HACK(p1): retry until it works */
+	/*
+	 * XXX the cache is never invalidated
+	 */
+	// A comment mentioning a TODO in passing.
+}
+`,
+			want: []models.Todo{
+				{LineNumber: 3, Kind: "TODO", Content: "handle signals", Priority: PriorityMedium},
+				{LineNumber: 6, Kind: "FIXME", Content: "leaks the file", Priority: PriorityHigh},
+				{LineNumber: 7, Kind: "HACK", Content: "retry until it works", Priority: PriorityHigh},
+				{LineNumber: 9, Kind: "XXX", Content: "the cache is never invalidated", Priority: PriorityHigh},
+			},
+		},
+		{
+			name: "Python",
+			file: "app.py",
+			content: `"""
+TODO(low): document the module
+"""
+import os  # TODO!: check the path first
+## HACK - monkey patch os
+s = "# TODO not a comment"
+`,
+			want: []models.Todo{
+				{LineNumber: 2, Kind: "TODO", Content: "document the module", Priority: PriorityLow},
+				{LineNumber: 4, Kind: "TODO", Content: "check the path first", Priority: PriorityHigh},
+				{LineNumber: 5, Kind: "HACK", Content: "monkey patch os", Priority: PriorityLow},
+			},
+		},
+		{
+			name: "SQL and HTML",
+			file: "index.html",
+			content: `<html>
+<!-- TODO: urgent, fix the layout -->
+<body>TODO: text</body>
+</html>
+`,
+			want: []models.Todo{
+				{LineNumber: 2, Kind: "TODO", Content: "urgent, fix the layout", Priority: PriorityHigh},
+			},
+		},
+		{
+			name:    "Language without comments",
+			file:    "data.json",
+			content: `{"note": "// TODO: not a comment"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineComments, blockComments, _ := stats.CommentSyntax(tt.file)
+			got := extractFile(strings.NewReader(tt.content), lineComments, blockComments)
+			if len(got) != len(tt.want) {
+				t.Fatalf("extractFile() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i].LineNumber != tt.want[i].LineNumber || got[i].Kind != tt.want[i].Kind ||
+					got[i].Content != tt.want[i].Content || got[i].Priority != tt.want[i].Priority {
+					t.Errorf("item %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInferPriority(t *testing.T) {
+	tests := []struct {
+		kind, tag string
+		exclaimed bool
+		content   string
+		want      string
+	}{
+		{"TODO", "", false, "add tests", PriorityMedium},
+		{"FIXME", "", false, "off by one", PriorityHigh},
+		{"XXX", "", false, "", PriorityHigh},
+		{"HACK", "", false, "works for now", PriorityLow},
+		{"FIXME", "p3", false, "cosmetic", PriorityLow},
+		{"TODO", "alice", false, "add tests", PriorityMedium},
+		{"TODO", "", true, "add tests", PriorityHigh},
+		{"HACK", "", false, "Security hole", PriorityHigh},
+	}
+	for _, tt := range tests {
+		if got := inferPriority(tt.kind, tt.tag, tt.exclaimed, tt.content); got != tt.want {
+			t.Errorf("inferPriority(%q, %q, %v, %q) = %q, want %q", tt.kind, tt.tag, tt.exclaimed, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":            "package main\n\n// TODO: wire the flags\n",
+		"internal/db/db.go":  "package db\n// FIXME: close rows\n",
+		"vendor/lib/lib.go":  "// TODO: vendored\n",
+		"notes.txt":          "TODO: plain text\n",
+		"assets/logo.go":     "// TODO\x00binary\n",
+		"web/app.min.js":     "// TODO: minified\n",
+		"scripts/release.sh": "#!/bin/sh\n# XXX: tag first\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	todos, err := Extract(root)
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	var got []string
+	for _, todo := range todos {
+		got = append(got, todo.SourceFile+":"+todo.Kind)
+	}
+	want := []string{"internal/db/db.go:FIXME", "main.go:TODO", "scripts/release.sh:XXX"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Extract() found %v, want %v", got, want)
+	}
+}
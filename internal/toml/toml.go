@@ -0,0 +1,646 @@
+// Package toml parses TOML documents into plain Go values.
+//
+// Tables become map[string]interface{}, arrays []interface{}, strings
+// string, integers int64, floats float64 and booleans bool. Dates and times
+// are returned as the string they were written as. The parser covers the
+// TOML 1.0 syntax found in manifests such as Cargo.toml, pyproject.toml and
+// Gradle version catalogs.
+package toml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parse decodes a TOML document.
+func Parse(data []byte) (map[string]interface{}, error) {
+	p := &parser{src: string(data), line: 1}
+	root := make(map[string]interface{})
+	p.current = root
+	if err := p.parse(root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Table returns the table at the dotted path in doc, or nil.
+func Table(doc map[string]interface{}, path ...string) map[string]interface{} {
+	current := doc
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// String returns the string stored under key in t, or "".
+func String(t map[string]interface{}, key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// Strings returns the strings of the array stored under key in t.
+func Strings(t map[string]interface{}, key string) []string {
+	arr, _ := t[key].([]interface{})
+	var out []string
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Error reports a syntax error with the line it was found on.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("toml: line %d: %s", e.Line, e.Msg)
+}
+
+type parser struct {
+	src     string
+	pos     int
+	line    int
+	current map[string]interface{}
+	// defined records tables created by a header or by inline definition,
+	// which cannot be defined a second time.
+	defined map[string]bool
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &Error{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) advance() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipSpace skips spaces and tabs on the current line.
+func (p *parser) skipSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment up to, but not including, the newline.
+func (p *parser) skipComment() {
+	if p.peek() != '#' {
+		return
+	}
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *parser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.advance()
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine consumes trailing whitespace, an optional comment and the
+// newline that must follow a key/value pair or table header.
+func (p *parser) endOfLine() error {
+	p.skipSpace()
+	p.skipComment()
+	if p.eof() {
+		return nil
+	}
+	if p.peek() == '\r' {
+		p.pos++
+	}
+	if p.eof() || p.peek() != '\n' {
+		return p.errorf("expected newline, found %q", p.peek())
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) parse(root map[string]interface{}) error {
+	p.defined = make(map[string]bool)
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+
+		if p.peek() == '[' {
+			if err := p.parseHeader(root); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := p.parseKeyValue(p.current); err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseHeader(root map[string]interface{}) error {
+	p.pos++
+	array := false
+	if p.peek() == '[' {
+		array = true
+		p.pos++
+	}
+
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+
+	if p.peek() != ']' {
+		return p.errorf("expected ']' after table name")
+	}
+	p.pos++
+	if array {
+		if p.peek() != ']' {
+			return p.errorf("expected ']]' after array table name")
+		}
+		p.pos++
+	}
+
+	parent, err := p.descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	name := strings.Join(keys, "\x00")
+
+	if array {
+		var arr []interface{}
+		switch existing := parent[last].(type) {
+		case nil:
+		case []interface{}:
+			arr = existing
+		default:
+			return p.errorf("key %q is already defined", strings.Join(keys, "."))
+		}
+		table := make(map[string]interface{})
+		parent[last] = append(arr, table)
+		p.current = table
+	} else {
+		switch existing := parent[last].(type) {
+		case nil:
+			table := make(map[string]interface{})
+			parent[last] = table
+			p.current = table
+		case map[string]interface{}:
+			if p.defined[name] {
+				return p.errorf("table %q is already defined", strings.Join(keys, "."))
+			}
+			p.current = existing
+		default:
+			return p.errorf("key %q is already defined", strings.Join(keys, "."))
+		}
+		p.defined[name] = true
+	}
+
+	return p.endOfLine()
+}
+
+// descend walks or creates the tables named by keys below t. Arrays of
+// tables resolve to their last element, as TOML requires.
+func (p *parser) descend(t map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		switch next := t[key].(type) {
+		case nil:
+			table := make(map[string]interface{})
+			t[key] = table
+			t = table
+		case map[string]interface{}:
+			t = next
+		case []interface{}:
+			if len(next) == 0 {
+				return nil, p.errorf("key %q is not a table", key)
+			}
+			table, ok := next[len(next)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("key %q is not a table", key)
+			}
+			t = table
+		default:
+			return nil, p.errorf("key %q is not a table", key)
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) parseKeyValue(t map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpace()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.descend(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return p.errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKey reads a possibly dotted key made of bare and quoted parts.
+func (p *parser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace()
+		var key string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case c == '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("expected key, found %q", p.peek())
+			}
+			key = p.src[start:p.pos]
+		}
+		keys = append(keys, key)
+
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("expected value")
+	}
+
+	switch c := p.peek(); c {
+	case '"':
+		if strings.HasPrefix(p.src[p.pos:], `"""`) {
+			return p.parseMultilineBasicString()
+		}
+		return p.parseBasicString()
+	case '\'':
+		if strings.HasPrefix(p.src[p.pos:], "'''") {
+			return p.parseMultilineLiteralString()
+		}
+		return p.parseLiteralString()
+	case '[':
+		return p.parseArray()
+	case '{':
+		return p.parseInlineTable()
+	case 't':
+		if strings.HasPrefix(p.src[p.pos:], "true") {
+			p.pos += 4
+			return true, nil
+		}
+	case 'f':
+		if strings.HasPrefix(p.src[p.pos:], "false") {
+			p.pos += 5
+			return false, nil
+		}
+	}
+
+	return p.parseScalar()
+}
+
+// parseScalar reads numbers, special floats and dates.
+func (p *parser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == ',' || c == ']' || c == '}' || c == '#' || c == '\n' || c == '\r' {
+			break
+		}
+		// A date and time may be separated by a single space.
+		if c == ' ' || c == '\t' {
+			rest := p.src[p.pos+1:]
+			if c == ' ' && isDate(p.src[start:p.pos]) && len(rest) > 0 && rest[0] >= '0' && rest[0] <= '9' {
+				p.pos++
+				continue
+			}
+			break
+		}
+		p.pos++
+	}
+
+	raw := p.src[start:p.pos]
+	if raw == "" {
+		return nil, p.errorf("expected value")
+	}
+
+	switch raw {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	if isDate(raw) || strings.Count(raw, ":") >= 2 {
+		return raw, nil
+	}
+
+	clean := strings.ReplaceAll(raw, "_", "")
+	if strings.HasPrefix(clean, "0x") || strings.HasPrefix(clean, "0o") || strings.HasPrefix(clean, "0b") {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[clean[1]]
+		n, err := strconv.ParseInt(clean[2:], base, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %q", raw)
+		}
+		return n, nil
+	}
+
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+
+	return nil, p.errorf("invalid value %q", raw)
+}
+
+func isDate(s string) bool {
+	return len(s) >= 10 && s[4] == '-' && s[7] == '-' && s[0] >= '0' && s[0] <= '9'
+}
+
+func (p *parser) parseBasicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.advance()
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *parser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.trimLeadingNewline()
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], `"""`) {
+			// Up to two quotes may directly precede the closing delimiter.
+			extra := 0
+			for extra < 2 && strings.HasPrefix(p.src[p.pos+3+extra:], `"`) {
+				extra++
+			}
+			b.WriteString(strings.Repeat(`"`, extra))
+			p.pos += 3 + extra
+			return b.String(), nil
+		}
+
+		c := p.advance()
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+
+		// A backslash at the end of a line trims the newline and any
+		// whitespace that follows it.
+		rest := p.pos
+		for rest < len(p.src) && (p.src[rest] == ' ' || p.src[rest] == '\t' || p.src[rest] == '\r') {
+			rest++
+		}
+		if rest < len(p.src) && p.src[rest] == '\n' {
+			for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+				p.advance()
+			}
+			continue
+		}
+		if err := p.parseEscape(&b); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+	p.pos++
+	start := p.pos
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.peek() == '\'' {
+			s := p.src[start:p.pos]
+			p.pos++
+			return s, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.trimLeadingNewline()
+
+	start := p.pos
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], "'''") {
+			end := p.pos
+			extra := 0
+			for extra < 2 && strings.HasPrefix(p.src[p.pos+3+extra:], "'") {
+				extra++
+			}
+			p.pos += 3 + extra
+			return p.src[start:end] + strings.Repeat("'", extra), nil
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) trimLeadingNewline() {
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos++
+	}
+	if p.peek() == '\n' {
+		p.advance()
+	}
+}
+
+func (p *parser) parseEscape(b *strings.Builder) error {
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+	c := p.advance()
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape")
+		}
+		p.pos += n
+		b.WriteRune(rune(code))
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+func (p *parser) parseArray() ([]interface{}, error) {
+	p.pos++
+	arr := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *parser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return table, nil
+	}
+
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
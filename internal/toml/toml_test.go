@@ -0,0 +1,115 @@
+package toml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc := `
+# Cargo style manifest
+title = "demo" # trailing comment
+"quoted key" = 'literal \n kept'
+count = 1_000
+hex = 0xff
+ratio = 0.5
+enabled = true
+released = 2024-01-02T03:04:05Z
+day = 1979-05-27
+
+description = """
+First line
+second \
+  line"""
+
+raw = '''
+C:\path\'''
+
+[package]
+name = "app"
+edition = "2021"
+authors = [
+  "a", # first
+  "b",
+]
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+tokio.version = "1"
+tokio.features = ["full"]
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+
+[[bin]]
+name = "one"
+
+[[bin]]
+name = "two"
+
+[bin.extra]
+`
+
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"string", got["title"], "demo"},
+		{"quoted key literal", got["quoted key"], `literal \n kept`},
+		{"integer with underscores", got["count"], int64(1000)},
+		{"hex integer", got["hex"], int64(255)},
+		{"float", got["ratio"], 0.5},
+		{"bool", got["enabled"], true},
+		{"datetime", got["released"], "2024-01-02T03:04:05Z"},
+		{"date", got["day"], "1979-05-27"},
+		{"multi-line basic", got["description"], "First line\nsecond line"},
+		{"multi-line literal", got["raw"], `C:\path\`},
+		{"table", String(Table(got, "package"), "edition"), "2021"},
+		{"multi-line array", Strings(Table(got, "package"), "authors"), []string{"a", "b"}},
+		{"inline table", Strings(Table(got, "dependencies", "serde"), "features"), []string{"derive"}},
+		{"dotted keys", String(Table(got, "dependencies", "tokio"), "version"), "1"},
+		{"quoted table name", String(Table(got, "target", "cfg(unix)", "dependencies"), "libc"), "0.2"},
+		{"array of tables", len(got["bin"].([]interface{})), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.value, tt.expected) {
+				t.Errorf("got %#v, want %#v", tt.value, tt.expected)
+			}
+		})
+	}
+
+	second := got["bin"].([]interface{})[1].(map[string]interface{})
+	if _, ok := second["extra"].(map[string]interface{}); !ok {
+		t.Errorf("[bin.extra] was not added to the last [[bin]] entry: %#v", second)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"duplicate key", "a = 1\na = 2\n"},
+		{"duplicate table", "[a]\n[a]\n"},
+		{"missing value", "a =\n"},
+		{"unterminated string", "a = \"x\n"},
+		{"unterminated array", "a = [1, 2\n"},
+		{"two values on a line", "a = 1 b = 2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.doc)); err == nil {
+				t.Errorf("Parse(%q) succeeded, want error", tt.doc)
+			}
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/snowarch/project-memory/internal/detect"
+	"github.com/snowarch/project-memory/internal/git"
+	"github.com/snowarch/project-memory/internal/ignore"
+	"github.com/snowarch/project-memory/internal/models"
 )
 
 // AgentContext provides comprehensive project context for code agents
@@ -18,6 +22,7 @@ type AgentContext struct {
 	Status           string            `json:"status"`
 	Progress         int               `json:"progress"`
 	Technologies     []TechnologyInfo  `json:"technologies"`
+	Languages        []models.LanguageStat `json:"languages,omitempty"`
 	RecentFiles      []FileInfo        `json:"recent_files"`
 	ActivityLevel    string            `json:"activity_level"`
 	LastModified     time.Time         `json:"last_modified"`
@@ -29,9 +34,20 @@ type AgentContext struct {
 	TestCommands     []string          `json:"test_commands"`
 	DevCommands      []string          `json:"dev_commands"`
 	Notes            string            `json:"notes,omitempty"`
+	Parent           *ProjectRef       `json:"parent,omitempty"`
+	Members          []ProjectRef      `json:"members,omitempty"`
 	GeneratedAt      time.Time         `json:"generated_at"`
 }
 
+// ProjectRef identifies a related project, such as the workspace a project
+// belongs to or the members of a workspace.
+type ProjectRef struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Status string `json:"status,omitempty"`
+}
+
 type TechnologyInfo struct {
 	Name         string `json:"name"`
 	Version      string `json:"version,omitempty"`
@@ -52,10 +68,33 @@ type GitInfo struct {
 	Remote           string   `json:"remote,omitempty"`
 	Branch           string   `json:"branch,omitempty"`
 	HasUncommitted   bool     `json:"has_uncommitted"`
-	RecentCommits    []string `json:"recent_commits,omitempty"`
-	LastCommitTime   time.Time `json:"last_commit_time,omitempty"`
+	RecentCommits    []CommitInfo `json:"recent_commits,omitempty"`
+	LastCommitTime   *time.Time `json:"last_commit_time,omitempty"`
+	Upstream         string   `json:"upstream,omitempty"`
+	Ahead            int      `json:"ahead,omitempty"`
+	Behind           int      `json:"behind,omitempty"`
+	StashCount       int      `json:"stash_count"`
+	Branches         []string `json:"branches,omitempty"`
+}
+
+// CommitInfo summarizes a commit for agents: who made it, when, what it
+// says and which files it touched.
+type CommitInfo struct {
+	Hash         string    `json:"hash"`
+	Author       string    `json:"author"`
+	Date         time.Time `json:"date"`
+	Subject      string    `json:"subject"`
+	Files        []string  `json:"files,omitempty"`
+	FilesChanged int       `json:"files_changed"`
 }
 
+// recentCommitCount is how many of the latest commits a context lists.
+const recentCommitCount = 10
+
+// maxCommitFiles bounds the files listed per commit; FilesChanged still
+// counts them all.
+const maxCommitFiles = 20
+
 type QuickStartInfo struct {
 	SetupCommands    []string `json:"setup_commands"`
 	DevCommand       string   `json:"dev_command,omitempty"`
@@ -107,10 +146,11 @@ func (cg *ContextGenerator) GenerateAgentContext(projectName, status string, pro
 		context.GitInfo = gitInfo
 	}
 
-	// Get technologies
-	techs, err := cg.getTechnologies()
+	// Get technologies and dependencies
+	techs, deps, err := cg.getTechnologies()
 	if err == nil {
 		context.Technologies = techs
+		context.Dependencies = deps
 	}
 
 	// Get quick start info
@@ -119,12 +159,6 @@ func (cg *ContextGenerator) GenerateAgentContext(projectName, status string, pro
 	// Get important files
 	context.ImportantFiles = cg.getImportantFiles()
 
-	// Get dependencies
-	deps, err := cg.getDependencies()
-	if err == nil {
-		context.Dependencies = deps
-	}
-
 	// Get commands
 	context.BuildCommands = cg.getBuildCommands()
 	context.TestCommands = cg.getTestCommands()
@@ -134,24 +168,37 @@ func (cg *ContextGenerator) GenerateAgentContext(projectName, status string, pro
 }
 
 func (cg *ContextGenerator) detectProjectType() string {
-	indicators := map[string]string{
-		"package.json":     "nodejs",
-		"go.mod":           "go",
-		"Cargo.toml":       "rust",
-		"requirements.txt": "python",
-		"pom.xml":          "java-maven",
-		"build.gradle":     "java-gradle",
-		"*.sln":            "dotnet",
-		"CMakeLists.txt":   "cmake",
-		"Makefile":         "makefile",
-		"composer.json":    "php",
-		"Gemfile":          "ruby",
+	// Checked in order, so a polyglot project gets the type of its main
+	// build; patterns are globs.
+	indicators := []struct {
+		pattern  string
+		projType string
+	}{
+		{"package.json", "nodejs"},
+		{"go.mod", "go"},
+		{"Cargo.toml", "rust"},
+		{"pyproject.toml", "python"},
+		{"requirements.txt", "python"},
+		{"pom.xml", "java-maven"},
+		{"build.gradle", "java-gradle"},
+		{"build.gradle.kts", "java-gradle"},
+		{"*.sln", "dotnet"},
+		{"*.csproj", "dotnet"},
+		{"*.fsproj", "dotnet"},
+		{"composer.json", "php"},
+		{"Gemfile", "ruby"},
+		{"mix.exs", "elixir"},
+		{"pubspec.yaml", "dart"},
+		{"Package.swift", "swift"},
+		{"build.zig", "zig"},
+		{"CMakeLists.txt", "cmake"},
+		{"Makefile", "makefile"},
 	}
 
-	for file, projType := range indicators {
-		fullPath := filepath.Join(cg.projectPath, file)
-		if _, err := os.Stat(fullPath); err == nil {
-			return projType
+	for _, ind := range indicators {
+		matches, err := filepath.Glob(filepath.Join(cg.projectPath, ind.pattern))
+		if err == nil && len(matches) > 0 {
+			return ind.projType
 		}
 	}
 
@@ -162,21 +209,11 @@ func (cg *ContextGenerator) getRecentFiles() ([]FileInfo, error) {
 	var files []FileInfo
 	cutoff := time.Now().AddDate(0, 0, -7) // Last 7 days
 
-	err := filepath.Walk(cg.projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(cg.projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
 
-		// Skip hidden files and common directories
-		if strings.HasPrefix(info.Name(), ".") || 
-		   strings.Contains(path, "node_modules") ||
-		   strings.Contains(path, "target") ||
-		   strings.Contains(path, "build") ||
-		   strings.Contains(path, "dist") ||
-		   strings.Contains(path, ".git") {
-			return nil
-		}
-
 		if info.ModTime().After(cutoff) {
 			fileInfo := FileInfo{
 				Path:      path,
@@ -236,20 +273,11 @@ func (cg *ContextGenerator) analyzeActivityLevel() string {
 func (cg *ContextGenerator) getLastModifiedTime() time.Time {
 	var latest time.Time
 	
-	filepath.Walk(cg.projectPath, func(path string, info os.FileInfo, err error) error {
+	ignore.Walk(cg.projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
 
-		if strings.HasPrefix(info.Name(), ".") || 
-		   strings.Contains(path, "node_modules") ||
-		   strings.Contains(path, "target") ||
-		   strings.Contains(path, "build") ||
-		   strings.Contains(path, "dist") ||
-		   strings.Contains(path, ".git") {
-			return nil
-		}
-
 		if info.ModTime().After(latest) {
 			latest = info.ModTime()
 		}
@@ -261,110 +289,99 @@ func (cg *ContextGenerator) getLastModifiedTime() time.Time {
 }
 
 func (cg *ContextGenerator) getGitInfo() *GitInfo {
-	gitDir := filepath.Join(cg.projectPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+	repo, err := git.Open(cg.projectPath)
+	if err != nil {
 		return nil
 	}
+	defer repo.Close()
 
-	gitInfo := &GitInfo{}
-
-	// Get remote
-	configPath := filepath.Join(gitDir, "config")
-	if data, err := os.ReadFile(configPath); err == nil {
-		content := string(data)
-		for _, line := range strings.Split(content, "\n") {
-			if strings.Contains(line, "url =") {
-				parts := strings.Split(line, "=")
-				if len(parts) == 2 {
-					gitInfo.Remote = strings.TrimSpace(parts[1])
-					break
-				}
+	gitInfo := &GitInfo{
+		Remote: repo.Remote(),
+		Branch: repo.Branch(),
+	}
+
+	// Check for uncommitted changes
+	if dirty, err := repo.HasChanges(); err == nil {
+		gitInfo.HasUncommitted = dirty
+	}
+
+	// Recent commits, with the files each one touched
+	if commits, err := repo.Log(git.LogOptions{Limit: recentCommitCount}); err == nil {
+		for _, c := range commits {
+			commit := CommitInfo{
+				Hash:    c.Hash,
+				Author:  c.Author.Name,
+				Date:    c.Author.When,
+				Subject: c.Subject(),
+			}
+			if files, err := repo.ChangedFiles(c); err == nil {
+				commit.FilesChanged = len(files)
+				commit.Files = files[:min(len(files), maxCommitFiles)]
 			}
+			gitInfo.RecentCommits = append(gitInfo.RecentCommits, commit)
+		}
+		if len(commits) > 0 {
+			last := commits[0].Committer.When
+			gitInfo.LastCommitTime = &last
 		}
 	}
 
-	// Get branch
-	headPath := filepath.Join(gitDir, "HEAD")
-	if headData, err := os.ReadFile(headPath); err == nil {
-		headContent := strings.TrimSpace(string(headData))
-		if strings.HasPrefix(headContent, "ref: refs/heads/") {
-			gitInfo.Branch = strings.TrimPrefix(headContent, "ref: refs/heads/")
+	// Divergence from the upstream branch
+	if upstream := repo.Upstream(); upstream != "" {
+		if ahead, behind, err := repo.AheadBehind(upstream); err == nil {
+			gitInfo.Upstream = strings.TrimPrefix(strings.TrimPrefix(upstream, "refs/remotes/"), "refs/heads/")
+			gitInfo.Ahead, gitInfo.Behind = ahead, behind
 		}
 	}
 
-	// Check for uncommitted changes
-	if output, err := exec.Command("git", "-C", cg.projectPath, "status", "--porcelain").Output(); err == nil {
-		gitInfo.HasUncommitted = len(strings.TrimSpace(string(output))) > 0
+	if count, err := repo.StashCount(); err == nil {
+		gitInfo.StashCount = count
+	}
+	if branches, err := repo.Branches(); err == nil {
+		gitInfo.Branches = branches
 	}
 
 	return gitInfo
 }
 
-func (cg *ContextGenerator) getTechnologies() ([]TechnologyInfo, error) {
-	var techs []TechnologyInfo
+// getTechnologies reports the technologies found by the same detectors the
+// scanner uses.
+func (cg *ContextGenerator) getTechnologies() ([]TechnologyInfo, []DependencyInfo, error) {
+	detected, err := detect.Detect(cg.projectPath)
 
-	// Node.js
-	if data, err := os.ReadFile(filepath.Join(cg.projectPath, "package.json")); err == nil {
-		var pkg struct {
-			Dependencies    map[string]string `json:"dependencies"`
-			DevDependencies map[string]string `json:"devDependencies"`
+	var techs []TechnologyInfo
+	var deps []DependencyInfo
+	for _, t := range detected {
+		if t.Transitive {
+			continue
 		}
-		if json.Unmarshal(data, &pkg) == nil {
-			techs = append(techs, TechnologyInfo{
-				Name:         "Node.js",
-				Type:         "runtime",
-				DetectedFrom: "package.json",
-			})
-
-			// Add main dependencies
-			for dep, ver := range pkg.Dependencies {
-				techs = append(techs, TechnologyInfo{
-					Name:         dep,
-					Version:      ver,
-					Type:         "dependency",
-					DetectedFrom: "package.json",
-				})
+		techs = append(techs, TechnologyInfo{
+			Name:         t.Name,
+			Version:      t.Version,
+			Type:         t.Type,
+			DetectedFrom: t.DetectedFrom,
+		})
+		if t.Type == "dependency" || t.Type == "framework" {
+			depType := "runtime"
+			switch t.Scope {
+			case models.ScopeDev:
+				depType = "dev"
+			case models.ScopeBuild:
+				depType = "build"
 			}
-		}
-	}
-
-	// Go
-	if data, err := os.ReadFile(filepath.Join(cg.projectPath, "go.mod")); err == nil {
-		content := string(data)
-		lines := strings.Split(content, "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "go ") {
-				version := strings.TrimSpace(strings.TrimPrefix(line, "go "))
-				techs = append(techs, TechnologyInfo{
-					Name:         "Go",
-					Version:      version,
-					Type:         "runtime",
-					DetectedFrom: "go.mod",
-				})
-				break
+			version := t.Version
+			if version == "" {
+				version = t.DeclaredVersion
 			}
+			deps = append(deps, DependencyInfo{
+				Name:    t.Name,
+				Version: version,
+				Type:    depType,
+			})
 		}
 	}
 
-	// Rust
-	if _, err := os.Stat(filepath.Join(cg.projectPath, "Cargo.toml")); err == nil {
-		techs = append(techs, TechnologyInfo{
-			Name:         "Rust",
-			Type:         "runtime",
-			DetectedFrom: "Cargo.toml",
-		})
-	}
-
-	// Python
-	if _, err := os.Stat(filepath.Join(cg.projectPath, "requirements.txt")); err == nil {
-		techs = append(techs, TechnologyInfo{
-			Name:         "Python",
-			Type:         "runtime",
-			DetectedFrom: "requirements.txt",
-		})
-	}
-
-	return techs, nil
+	return techs, deps, err
 }
 
 func (cg *ContextGenerator) getQuickStartInfo() *QuickStartInfo {
@@ -413,31 +430,6 @@ func (cg *ContextGenerator) getImportantFiles() []string {
 	return files
 }
 
-func (cg *ContextGenerator) getDependencies() ([]DependencyInfo, error) {
-	var deps []DependencyInfo
-
-	// This is a simplified version - in production, you'd parse actual dependency files
-	switch cg.detectProjectType() {
-	case "nodejs":
-		if data, err := os.ReadFile(filepath.Join(cg.projectPath, "package.json")); err == nil {
-			var pkg struct {
-				Dependencies map[string]string `json:"dependencies"`
-			}
-			if json.Unmarshal(data, &pkg) == nil {
-				for name, version := range pkg.Dependencies {
-					deps = append(deps, DependencyInfo{
-						Name:    name,
-						Version: version,
-						Type:    "runtime",
-					})
-				}
-			}
-		}
-	}
-
-	return deps, nil
-}
-
 func (cg *ContextGenerator) getBuildCommands() []string {
 	switch cg.detectProjectType() {
 	case "nodejs":
@@ -514,6 +506,20 @@ func (ctx *AgentContext) ExportToMarkdown() string {
 		}
 	}
 
+	if ctx.Parent != nil || len(ctx.Members) > 0 {
+		md.WriteString("## Workspace\n\n")
+		if ctx.Parent != nil {
+			md.WriteString(fmt.Sprintf("**Member of:** %s (`%s`)  \n", ctx.Parent.Name, ctx.Parent.Path))
+		}
+		if len(ctx.Members) > 0 {
+			md.WriteString("**Members:**\n")
+			for _, m := range ctx.Members {
+				md.WriteString(fmt.Sprintf("- %s (`%s`)\n", m.Name, m.Path))
+			}
+		}
+		md.WriteString("\n")
+	}
+
 	if len(ctx.Technologies) > 0 {
 		md.WriteString("## Technologies\n\n")
 		for _, tech := range ctx.Technologies {
@@ -526,6 +532,14 @@ func (ctx *AgentContext) ExportToMarkdown() string {
 		md.WriteString("\n")
 	}
 
+	if len(ctx.Languages) > 0 {
+		md.WriteString("## Languages\n\n")
+		for _, lang := range ctx.Languages {
+			md.WriteString(fmt.Sprintf("- **%s**: %d lines of code in %d files\n", lang.Language, lang.CodeLines, lang.Files))
+		}
+		md.WriteString("\n")
+	}
+
 	if len(ctx.ImportantFiles) > 0 {
 		md.WriteString("## Important Files\n\n")
 		for _, file := range ctx.ImportantFiles {
@@ -542,7 +556,34 @@ func (ctx *AgentContext) ExportToMarkdown() string {
 		if ctx.GitInfo.Branch != "" {
 			md.WriteString(fmt.Sprintf("**Branch:** %s  \n", ctx.GitInfo.Branch))
 		}
+		if ctx.GitInfo.Upstream != "" {
+			md.WriteString(fmt.Sprintf("**Upstream:** %s (%d ahead, %d behind)  \n", ctx.GitInfo.Upstream, ctx.GitInfo.Ahead, ctx.GitInfo.Behind))
+		}
+		if len(ctx.GitInfo.Branches) > 0 {
+			md.WriteString(fmt.Sprintf("**Local Branches:** %s  \n", strings.Join(ctx.GitInfo.Branches, ", ")))
+		}
+		if ctx.GitInfo.LastCommitTime != nil {
+			md.WriteString(fmt.Sprintf("**Last Commit:** %s  \n", ctx.GitInfo.LastCommitTime.Format("2006-01-02 15:04:05")))
+		}
+		if ctx.GitInfo.StashCount > 0 {
+			md.WriteString(fmt.Sprintf("**Stashes:** %d  \n", ctx.GitInfo.StashCount))
+		}
 		md.WriteString(fmt.Sprintf("**Uncommitted Changes:** %v  \n\n", ctx.GitInfo.HasUncommitted))
+
+		if len(ctx.GitInfo.RecentCommits) > 0 {
+			md.WriteString("### Recent Commits\n\n")
+			for _, c := range ctx.GitInfo.RecentCommits {
+				md.WriteString(fmt.Sprintf("- `%s` %s (%s, %s)\n", c.Hash[:min(len(c.Hash), 7)], c.Subject, c.Author, c.Date.Format("2006-01-02")))
+				if len(c.Files) > 0 {
+					files := "`" + strings.Join(c.Files, "`, `") + "`"
+					if more := c.FilesChanged - len(c.Files); more > 0 {
+						files += fmt.Sprintf(" and %d more", more)
+					}
+					md.WriteString(fmt.Sprintf("  - Files: %s\n", files))
+				}
+			}
+			md.WriteString("\n")
+		}
 	}
 
 	return md.String()
@@ -0,0 +1,707 @@
+// Package yaml parses YAML documents into plain Go values.
+//
+// Mappings become map[string]interface{}, sequences []interface{} and
+// scalars string; null scalars are nil. Scalars are not resolved to other
+// types, so "1.20" stays a version rather than becoming a float. The parser
+// covers the block and flow syntax found in lock files, Compose files,
+// Kubernetes manifests and CI pipelines: block scalars, quoted strings,
+// anchors, aliases and merge keys, and multiple documents. Tags are
+// ignored.
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parse decodes the first document of a YAML stream.
+func Parse(data []byte) (interface{}, error) {
+	docs, err := ParseAll(data)
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+	return docs[0], nil
+}
+
+// ParseAll decodes every document of a YAML stream. Empty documents are
+// skipped.
+func ParseAll(data []byte) ([]interface{}, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	var docs []interface{}
+	start := 0
+	parse := func(end int) error {
+		p := &parser{lines: lines[start:end], offset: start, anchors: make(map[string]interface{})}
+		doc, err := p.parseDocument()
+		if err != nil {
+			return err
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+		return nil
+	}
+
+	for i, line := range lines {
+		if line == "---" || strings.HasPrefix(line, "--- ") || line == "..." {
+			if err := parse(i); err != nil {
+				return nil, err
+			}
+			start = i + 1
+		}
+	}
+	if err := parse(len(lines)); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Map returns the mapping at the key path below v, or nil.
+func Map(v interface{}, path ...string) map[string]interface{} {
+	current, _ := v.(map[string]interface{})
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// String returns the scalar stored under key in m, or "".
+func String(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// Strings returns the scalars of the sequence stored under key in m.
+func Strings(m map[string]interface{}, key string) []string {
+	seq, _ := m[key].([]interface{})
+	var out []string
+	for _, v := range seq {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Error reports a syntax error with the line it was found on.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("yaml: line %d: %s", e.Line, e.Msg)
+}
+
+type parser struct {
+	lines   []string
+	offset  int
+	pos     int
+	anchors map[string]interface{}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &Error{Line: p.offset + p.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseDocument() (interface{}, error) {
+	// Directives such as %YAML only appear before the first document.
+	for p.pos < len(p.lines) && strings.HasPrefix(p.lines[p.pos], "%") {
+		p.pos++
+	}
+	doc, err := p.parseNode(-1)
+	if err != nil {
+		return nil, err
+	}
+	if _, text, ok := p.peek(); ok {
+		return nil, p.errorf("unexpected %q", text)
+	}
+	return doc, nil
+}
+
+// peek returns the indentation and content, without comments, of the next
+// line that is not blank. Blank lines are consumed.
+func (p *parser) peek() (int, string, bool) {
+	for p.pos < len(p.lines) {
+		raw := p.lines[p.pos]
+		text := strings.TrimLeft(raw, " ")
+		content := strings.TrimRight(stripComment(text), " \t")
+		if content == "" {
+			p.pos++
+			continue
+		}
+		return len(raw) - len(text), content, true
+	}
+	return 0, "", false
+}
+
+// parseNode parses the block node on the next lines, which must be indented
+// more than parent. It returns nil when there is no such node.
+func (p *parser) parseNode(parent int) (interface{}, error) {
+	indent, text, ok := p.peek()
+	if !ok || indent <= parent {
+		return nil, nil
+	}
+	if isSequenceItem(text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitKey(text); ok {
+		return p.parseMapping(indent)
+	}
+	p.pos++
+	return p.parseValue(text, parent)
+}
+
+func (p *parser) parseMapping(indent int) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind < indent {
+			return m, nil
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, rest, ok := splitKey(text)
+		if !ok {
+			if isSequenceItem(text) {
+				return m, nil
+			}
+			return nil, p.errorf("expected a mapping key, found %q", text)
+		}
+		p.pos++
+
+		value, err := p.parseValue(rest, indent)
+		if err != nil {
+			return nil, err
+		}
+		if key == "<<" {
+			merge(m, value)
+			continue
+		}
+		m[key] = value
+	}
+}
+
+// merge copies the keys of a merged mapping, or of a sequence of mappings,
+// that m does not define yet.
+func merge(m map[string]interface{}, value interface{}) {
+	sources, ok := value.([]interface{})
+	if !ok {
+		sources = []interface{}{value}
+	}
+	for _, source := range sources {
+		src, _ := source.(map[string]interface{})
+		for k, v := range src {
+			if _, exists := m[k]; !exists {
+				m[k] = v
+			}
+		}
+	}
+}
+
+func (p *parser) parseSequence(indent int) ([]interface{}, error) {
+	seq := []interface{}{}
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind < indent || !isSequenceItem(text) {
+			return seq, nil
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(text[1:], " ")
+		_, _, isKey := splitKey(rest)
+		if rest != "" && (isKey || isSequenceItem(rest)) {
+			// A block node starting on the item's line: rewrite the line so
+			// the node sits at its own column and continues on the next lines.
+			column := ind + len(text) - len(rest)
+			p.lines[p.pos] = strings.Repeat(" ", column) + rest
+			item, err := p.parseNode(indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, item)
+			continue
+		}
+
+		p.pos++
+		var item interface{}
+		var err error
+		if rest == "" {
+			// Unlike a mapping value, an empty item never continues with a
+			// sequence at its own indentation: that is the next item.
+			item, err = p.parseNode(indent)
+		} else {
+			item, err = p.parseValue(rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, item)
+	}
+}
+
+// parseValue parses the value written after a key or sequence indicator on
+// a line already consumed; nested nodes must be indented more than indent.
+func (p *parser) parseValue(rest string, indent int) (interface{}, error) {
+	anchor := ""
+	for rest != "" && (rest[0] == '&' || rest[0] == '!') {
+		token, after, _ := strings.Cut(rest, " ")
+		if token[0] == '&' {
+			anchor = token[1:]
+		}
+		rest = strings.TrimSpace(after)
+	}
+
+	var value interface{}
+	var err error
+	switch {
+	case rest == "":
+		ind, text, ok := p.peek()
+		if ok && ind == indent && isSequenceItem(text) {
+			// Sequences may sit at the indentation of their key.
+			value, err = p.parseSequence(indent)
+		} else {
+			value, err = p.parseNode(indent)
+		}
+	case rest[0] == '*':
+		name := rest[1:]
+		v, ok := p.anchors[name]
+		if !ok {
+			return nil, p.errorf("unknown alias %q", name)
+		}
+		value = v
+	case rest[0] == '|' || rest[0] == '>':
+		value = p.parseBlockScalar(rest, indent)
+	case rest[0] == '[' || rest[0] == '{':
+		value, err = p.parseFlow(rest)
+	default:
+		value, err = p.parseScalar(rest, indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if anchor != "" {
+		p.anchors[anchor] = value
+	}
+	return value, nil
+}
+
+// parseScalar parses a plain or quoted scalar, which may continue on the
+// following lines when they are indented more than indent.
+func (p *parser) parseScalar(text string, indent int) (interface{}, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		for closingQuote(text) < 0 {
+			if p.pos >= len(p.lines) {
+				return nil, p.errorf("unterminated string")
+			}
+			text += " " + strings.TrimSpace(p.lines[p.pos])
+			p.pos++
+		}
+		end := closingQuote(text)
+		if strings.TrimSpace(text[end+1:]) != "" {
+			return nil, p.errorf("unexpected %q after string", text[end+1:])
+		}
+		return unquote(text[:end+1])
+	}
+
+	for {
+		ind, next, ok := p.peek()
+		if !ok || ind <= indent || isSequenceItem(next) {
+			break
+		}
+		if _, _, isKey := splitKey(next); isKey {
+			break
+		}
+		text += " " + next
+		p.pos++
+	}
+	return plain(text), nil
+}
+
+// parseBlockScalar reads a literal (|) or folded (>) block scalar whose
+// lines are indented more than parent.
+func (p *parser) parseBlockScalar(header string, parent int) string {
+	var lines []string
+	indent := -1
+	for p.pos < len(p.lines) {
+		raw := p.lines[p.pos]
+		if strings.TrimSpace(raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		ind := len(raw) - len(strings.TrimLeft(raw, " "))
+		if indent < 0 {
+			indent = ind
+		}
+		if ind <= parent || ind < indent {
+			break
+		}
+		lines = append(lines, raw[indent:])
+		p.pos++
+	}
+
+	n := len(lines)
+	for n > 0 && lines[n-1] == "" {
+		n--
+	}
+	trailing := len(lines) - n
+	lines = lines[:n]
+
+	var s string
+	if header[0] == '>' {
+		s = fold(lines)
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case strings.Contains(header, "-"):
+		return s
+	case strings.Contains(header, "+"):
+		return s + strings.Repeat("\n", trailing+1)
+	case s != "":
+		return s + "\n"
+	}
+	return s
+}
+
+// fold joins the lines of a folded block scalar: line breaks become spaces
+// except around empty and more-indented lines.
+func fold(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case line == "":
+				b.WriteByte('\n')
+			case prev == "":
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(prev, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// parseFlow parses a flow sequence or mapping, joining the following lines
+// until its brackets are balanced.
+func (p *parser) parseFlow(text string) (interface{}, error) {
+	for !flowBalanced(text) {
+		if p.pos >= len(p.lines) {
+			return nil, p.errorf("unterminated flow collection")
+		}
+		text += " " + strings.TrimSpace(stripComment(p.lines[p.pos]))
+		p.pos++
+	}
+
+	f := &flowParser{s: text}
+	value, err := f.value()
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	f.space()
+	if f.i < len(f.s) {
+		return nil, p.errorf("unexpected %q after flow collection", f.s[f.i:])
+	}
+	return value, nil
+}
+
+func flowBalanced(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			end := closingQuote(s[i:])
+			if end < 0 {
+				return false
+			}
+			i += end
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+type flowParser struct {
+	s string
+	i int
+}
+
+func (f *flowParser) space() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+func (f *flowParser) value() (interface{}, error) {
+	f.space()
+	if f.i >= len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch f.s[f.i] {
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	case '"', '\'':
+		return f.quoted()
+	}
+	return plain(f.plain(false)), nil
+}
+
+func (f *flowParser) sequence() (interface{}, error) {
+	f.i++
+	seq := []interface{}{}
+	for {
+		f.space()
+		if f.i < len(f.s) && f.s[f.i] == ']' {
+			f.i++
+			return seq, nil
+		}
+		item, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, item)
+		if err := f.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *flowParser) mapping() (interface{}, error) {
+	f.i++
+	m := make(map[string]interface{})
+	for {
+		f.space()
+		if f.i < len(f.s) && f.s[f.i] == '}' {
+			f.i++
+			return m, nil
+		}
+
+		var key string
+		if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+			k, err := f.quoted()
+			if err != nil {
+				return nil, err
+			}
+			key, _ = k.(string)
+		} else {
+			key = f.plain(true)
+		}
+
+		f.space()
+		var value interface{}
+		if f.i < len(f.s) && f.s[f.i] == ':' {
+			f.i++
+			f.space()
+			if f.i < len(f.s) && f.s[f.i] != ',' && f.s[f.i] != '}' {
+				v, err := f.value()
+				if err != nil {
+					return nil, err
+				}
+				value = v
+			}
+		}
+		m[key] = value
+		if err := f.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes the comma between entries; the closing bracket is left
+// for the caller.
+func (f *flowParser) separator(closing byte) error {
+	f.space()
+	if f.i >= len(f.s) {
+		return fmt.Errorf("unterminated flow collection")
+	}
+	switch f.s[f.i] {
+	case ',':
+		f.i++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("expected ',' or %q, found %q", closing, f.s[f.i])
+}
+
+func (f *flowParser) quoted() (interface{}, error) {
+	end := closingQuote(f.s[f.i:])
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	s, err := unquote(f.s[f.i : f.i+end+1])
+	f.i += end + 1
+	return s, err
+}
+
+// plain reads a plain scalar inside a flow collection. Keys also end at a
+// colon followed by a space or the end of the entry.
+func (f *flowParser) plain(key bool) string {
+	start := f.i
+	for f.i < len(f.s) {
+		c := f.s[f.i]
+		if c == ',' || c == ']' || c == '}' {
+			break
+		}
+		if key && c == ':' && (f.i+1 == len(f.s) || strings.IndexByte(" ,}", f.s[f.i+1]) >= 0) {
+			break
+		}
+		f.i++
+	}
+	return strings.TrimSpace(f.s[start:f.i])
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits a "key: value" line. It reports false when the line is
+// not a mapping entry.
+func splitKey(text string) (string, string, bool) {
+	if text == "" || text[0] == '[' || text[0] == '{' || isSequenceItem(text) {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", false
+		}
+		after := strings.TrimLeft(text[end+1:], " ")
+		if !strings.HasPrefix(after, ":") || (len(after) > 1 && after[1] != ' ') {
+			return "", "", false
+		}
+		key, err := unquote(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(after[1:]), true
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// stripComment removes a comment, which starts with a # at the beginning of
+// the text or after whitespace, outside of quoted scalars.
+func stripComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		case (c == '"' || c == '\'') && startsScalar(s[:i]):
+			end := closingQuote(s[i:])
+			if end < 0 {
+				return s
+			}
+			i += end
+		}
+	}
+	return s
+}
+
+// startsScalar reports whether a quote after before opens a quoted scalar
+// rather than being part of a plain one, as in "it's".
+func startsScalar(before string) bool {
+	before = strings.TrimRight(before, " \t")
+	if before == "" {
+		return true
+	}
+	return strings.IndexByte(":-[{,?", before[len(before)-1]) >= 0
+}
+
+// closingQuote returns the index of the quote closing the string s starts
+// with, or -1.
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func plain(s string) interface{} {
+	switch s = strings.TrimSpace(s); s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	}
+	return s
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+
+	var b strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", fmt.Errorf("invalid escape at end of string")
+		}
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case ' ', '"', '/', '\\':
+			b.WriteByte(body[i])
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+			if i+size >= len(body) {
+				return "", fmt.Errorf("invalid escape \\%c", body[i])
+			}
+			code, err := strconv.ParseUint(body[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape \\%c", body[i])
+			}
+			var buf [utf8.UTFMax]byte
+			b.Write(buf[:utf8.EncodeRune(buf[:], rune(code))])
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", body[i])
+		}
+	}
+	return b.String(), nil
+}
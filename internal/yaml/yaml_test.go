@@ -0,0 +1,116 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc := `
+# Compose style document
+version: "3.8"
+x-defaults: &defaults
+  restart: always
+  environment:
+    - TZ=UTC
+services:
+  web:
+    <<: *defaults
+    image: 'node:18-alpine' # trailing comment
+    ports: ["3000:3000", '8080:80']
+    command: >
+      npm run
+      start
+    healthcheck: {test: [CMD, curl, -f], interval: 30s}
+  db:
+    image: postgres:16
+    restart: "no"
+steps:
+- name: Checkout
+  uses: actions/checkout@v4
+- run: |
+    go build ./...
+    go test ./...
+-
+  - nested
+  - items
+empty:
+url: https://example.com/#fragment
+it: it's plain
+escaped: "tab\there \u00e9"
+"/@babel/core@7.22.0":
+  dev: true
+long: first
+  second
+`
+
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	web := Map(got, "services", "web")
+	steps := got.(map[string]interface{})["steps"].([]interface{})
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"quoted scalar", String(Map(got), "version"), "3.8"},
+		{"single quoted scalar", String(web, "image"), "node:18-alpine"},
+		{"merge key", String(web, "restart"), "always"},
+		{"merged sequence", Strings(web, "environment"), []string{"TZ=UTC"}},
+		{"merge keeps own keys", String(Map(got, "services", "db"), "restart"), "no"},
+		{"flow sequence", Strings(web, "ports"), []string{"3000:3000", "8080:80"}},
+		{"folded scalar", String(web, "command"), "npm run start\n"},
+		{"flow mapping", Strings(Map(web, "healthcheck"), "test"), []string{"CMD", "curl", "-f"}},
+		{"sequence of mappings", String(steps[0].(map[string]interface{}), "uses"), "actions/checkout@v4"},
+		{"literal scalar", String(steps[1].(map[string]interface{}), "run"), "go build ./...\ngo test ./...\n"},
+		{"nested sequence", steps[2], []interface{}{"nested", "items"}},
+		{"null value", Map(got)["empty"], nil},
+		{"hash inside scalar", String(Map(got), "url"), "https://example.com/#fragment"},
+		{"apostrophe in plain scalar", String(Map(got), "it"), "it's plain"},
+		{"escapes", String(Map(got), "escaped"), "tab\there é"},
+		{"quoted key", String(Map(got, "/@babel/core@7.22.0"), "dev"), "true"},
+		{"multi-line plain scalar", String(Map(got), "long"), "first second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.value, tt.expected) {
+				t.Errorf("got %#v, want %#v", tt.value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	docs, err := ParseAll([]byte("apiVersion: v1\nkind: Service\n---\n# empty\n---\nkind: Deployment\n"))
+	if err != nil {
+		t.Fatalf("ParseAll() failed: %v", err)
+	}
+	if len(docs) != 2 || String(Map(docs[1]), "kind") != "Deployment" {
+		t.Errorf("ParseAll() = %#v, want two documents", docs)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"bad indentation", "a:\n    b: 1\n  c: 2\n"},
+		{"unterminated string", "a: \"x\n"},
+		{"unterminated flow", "a: [1, 2\n"},
+		{"unknown alias", "a: *missing\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.doc)); err == nil {
+				t.Errorf("Parse(%q) succeeded, want error", tt.doc)
+			}
+		})
+	}
+}